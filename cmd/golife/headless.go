@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golife/pkg/engine"
+	"golife/pkg/hashlife"
+	"golife/pkg/patterns"
+	"golife/pkg/universe"
+)
+
+// runHeadless steps the simulation without rendering for --generations, or
+// until it settles with --auto-stop, then prints a report, writes the
+// statistics and final state when asked and returns the exit code saying
+// how the run ended
+func runHeadless(u *universe.Universe2D, sim *engine.Simulation, recorder *engine.Recorder) int {
+	// Keep the report out of the statistics when they go to stdout
+	var report io.Writer = os.Stdout
+	if config.StatsOut == "-" {
+		report = os.Stderr
+	}
+
+	// Only --auto-stop watches every generation; otherwise the outcome comes
+	// from the final state alone, which tells extinction but not cycles
+	detector := engine.NewDetector(0)
+	var outcome engine.Detection
+	if config.AutoStop {
+		outcome = detector.Observe(0, sim.Universe())
+		sim.Subscribe(func(ev engine.Event) {
+			if !outcome.Settled() {
+				outcome = detector.Observe(ev.Generation, ev.Universe)
+			}
+		})
+	}
+
+	start := time.Now()
+	for sim.Generation() < config.Generations && !outcome.Settled() {
+		generation := sim.Generation()
+		sim.Step(1)
+		if sim.Generation() == generation {
+			break // The engine can advance no further
+		}
+	}
+	elapsed := time.Since(start)
+	if !config.AutoStop {
+		outcome = detector.Observe(sim.Generation(), sim.Universe())
+	}
+
+	code := exitOK
+	if engineErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: stopped at generation %d: %v\n", sim.Generation(), engineErr)
+		code = exitError
+	}
+	if recorder != nil {
+		if err := writeStats(recorder, config.StatsOut, config.StatsFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			code = exitError
+		}
+	}
+	if config.Output != "" {
+		if err := writeFinalState(u, sim); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			code = exitError
+		}
+	}
+
+	stats := sim.Stats()
+	fmt.Fprintf(report, "Rule:        %s\n", u.Rule().Name())
+	fmt.Fprintf(report, "Grid:        %dx%d, %s boundary, %s engine\n", u.Width(), u.Height(), config.Boundary, config.Engine)
+	if config.Pattern != "" {
+		fmt.Fprintf(report, "Pattern:     %s\n", config.Pattern)
+	} else {
+		fmt.Fprintf(report, "Seed:        %d (density %g)\n", stats.Seed, config.Density)
+	}
+	fmt.Fprintf(report, "Generations: %d\n", stats.Generation)
+	fmt.Fprintf(report, "Population:  %d\n", stats.LivingCells)
+	if stats.LivingCells > 0 {
+		fmt.Fprintf(report, "Bounds:      (%d,%d)-(%d,%d)\n", stats.BoundsMin.X, stats.BoundsMin.Y, stats.BoundsMax.X, stats.BoundsMax.Y)
+	}
+	fmt.Fprintf(report, "Outcome:     %s\n", outcome)
+	fmt.Fprintf(report, "Elapsed:     %s (%.0f generations/s)\n", elapsed.Round(time.Millisecond), float64(stats.Generation)/max(elapsed.Seconds(), 1e-9))
+
+	if code != exitOK {
+		return code
+	}
+	switch {
+	case stats.LivingCells == 0:
+		return exitExtinct
+	case outcome.Settled():
+		return exitSettled
+	default:
+		return exitOK
+	}
+}
+
+// writeFinalState writes the final state to --output: the whole plane of a
+// HashLife run as a macrocell for .mc files, otherwise the living part of
+// the displayed grid as RLE for .rle, Life 1.06 for .lif or plaintext
+func writeFinalState(u *universe.Universe2D, sim *engine.Simulation) error {
+	name := strings.TrimSuffix(filepath.Base(config.Pattern), filepath.Ext(config.Pattern))
+	if name == "" {
+		name = "random"
+	}
+	description := fmt.Sprintf("Run for %d generations under %s", sim.Generation(), u.Rule().Name())
+
+	if isMacrocell(config.Output) {
+		return writeMacrocell(u, sim, []string{name, description})
+	}
+	p := patterns.FromUniverse2D(u, 0, 0, u.Width(), u.Height()).Trim()
+	p.Name = name
+	p.Description = description
+	p.Rule = config.Rule
+	return patterns.WriteFile(config.Output, p)
+}
+
+// writeMacrocell writes the simulated HashLife universe, or a copy of the
+// displayed grid for the other engines, to --output as a macrocell
+func writeMacrocell(u *universe.Universe2D, sim *engine.Simulation, comments []string) error {
+	hl, ok := sim.Universe().(*hashlife.Universe)
+	if !ok {
+		var err error
+		if hl, err = hashlife.New(u.Width(), u.Height(), u.Rule()); err != nil {
+			return fmt.Errorf("failed to write final state: %w", err)
+		}
+		copyCells(hl, u)
+	}
+
+	f, err := os.Create(config.Output)
+	if err != nil {
+		return fmt.Errorf("failed to write final state: %w", err)
+	}
+	if err := hl.WriteMacrocell(f, comments); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write final state: %w", err)
+	}
+	return f.Close()
+}
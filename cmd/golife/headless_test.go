@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+)
+
+func TestRunHeadless_HashLifeStopsAtPlaneEdge(t *testing.T) {
+	saved := config
+	defer func() { config, engineErr = saved, nil }()
+	config = Configuration{Width: 20, Height: 20, Engine: "hashlife", StepExp: 59, Generations: math.MaxInt, Pattern: "glider", Boundary: "dead"}
+
+	u := universe.New2D(config.Width, config.Height, rules.ConwayRule{})
+	for _, c := range [][2]int{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}} {
+		u.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+	}
+	sim, _, err := newSimulation(u, nil)
+	if err != nil {
+		t.Fatalf("newSimulation returned error: %v", err)
+	}
+
+	// The glider travels 2^57 cells a step and soon leaves no room for the root
+	done := make(chan int)
+	go func() { done <- runHeadless(u, sim, nil) }()
+	select {
+	case code := <-done:
+		if code != exitError {
+			t.Errorf("Exit code = %d, want %d", code, exitError)
+		}
+		if engineErr == nil {
+			t.Error("The refused step should be reported")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("runHeadless kept stepping after the engine stopped advancing")
+	}
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"golife/pkg/core"
 	"golife/pkg/engine"
+	"golife/pkg/hashlife"
 	"golife/pkg/patterns"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
@@ -32,6 +38,19 @@ type Configuration struct {
 	ShowStats    bool
 	ColorMode    string
 	Interactive  bool
+	Boundary     string
+	Rule         string
+	Seed         int64
+	Density      float64
+	Engine       string
+	StepExp      int
+	Workers      int
+	HistoryMB    int
+	AutoStop     bool
+	StatsOut     string
+	StatsFormat  string
+	Headless     bool
+	Output       string
 	CurrentSpeed int
 }
 
@@ -42,53 +61,186 @@ func init() {
 	flag.IntVar(&config.Height, "height", defaultHeight, "Grid height")
 	flag.IntVar(&config.Speed, "speed", defaultSpeed, "Animation speed in milliseconds")
 	flag.IntVar(&config.Generations, "generations", defaultGenerations, "Number of generations to simulate")
-	flag.StringVar(&config.Pattern, "pattern", "", "Pattern to load (use 'list' to see available patterns)")
+	flag.StringVar(&config.Pattern, "pattern", "", "Pattern to load: a built-in name (use 'list' to see them) or an .rle, .cells, Life 1.05/1.06 .lif or macrocell .mc file (.mc needs --engine=hashlife)")
 	flag.BoolVar(&config.ShowStats, "stats", false, "Show statistics during simulation")
 	flag.StringVar(&config.ColorMode, "color", "", "Color mode: 'age' for age-based coloring")
 	flag.BoolVar(&config.Interactive, "interactive", false, "Enable interactive mode (keyboard controls)")
+	flag.StringVar(&config.Boundary, "boundary", "dead", "Boundary topology: dead, alive, torus, klein, projective, mirror")
+	flag.Int64Var(&config.Seed, "seed", 0, "Seed for the random initial state (0 picks one; it is shown with --stats and printed on exit)")
+	flag.Float64Var(&config.Density, "density", universe.DefaultDensity, "Fraction of cells alive in the random initial state (0-1)")
+	flag.StringVar(&config.Engine, "engine", "grid", "Simulation engine: grid, bitpacked (64 cells per word, B/S rules) or hashlife (unbounded plane, fast-forwarding, dead boundary only)")
+	flag.IntVar(&config.StepExp, "step-exp", 0, "With --engine=hashlife, advance 2^k generations per frame")
+	flag.BoolVar(&config.AutoStop, "auto-stop", false, "Stop before --generations once the run is extinct, still, periodic or a lone spaceship, and print which")
+	flag.StringVar(&config.StatsOut, "stats-out", "", "Write every generation's statistics to this file when the run ends ('-' for stdout)")
+	flag.StringVar(&config.StatsFormat, "stats-format", "", "Format for --stats-out: csv or ndjson (default from the file extension, else csv)")
+	flag.BoolVar(&config.Headless, "headless", false, "Run without rendering for --generations (or until it settles with --auto-stop), print a report and exit with 2 if the pattern died or, with --auto-stop, 3 if it settled")
+	flag.StringVar(&config.Output, "output", "", "With --headless, write the final state to this pattern file (RLE for .rle, Life 1.06 for .lif, macrocell for .mc, else plaintext)")
+	flag.IntVar(&config.HistoryMB, "history", 64, "Memory budget in MB for stepping back with 'b' in interactive mode (0 disables; not with --engine=hashlife)")
+	flag.IntVar(&config.Workers, "workers", 0, "Goroutines stepping the grid (0 uses one per CPU)")
+	flag.StringVar(&config.Rule, "rule", "B3/S23", "Rulestring, e.g. B36/S23, B2/S/C3 (Generations), a name such as highlife or wireworld, or a Golly .rule/.table file")
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// Exit codes of a run; headless runs report how the pattern ended
+const (
+	exitOK      = 0 // Finished with the pattern still evolving
+	exitError   = 1 // Bad flags or a failure
+	exitExtinct = 2 // Every cell died
+	exitSettled = 3 // Became still, periodic or a lone spaceship
+)
+
+// run runs golife and returns the process exit code
+func run() int {
 	flag.Parse()
 
 	// Handle pattern list request
 	if config.Pattern == "list" {
 		fmt.Print(listPatterns())
-		return
+		return exitOK
 	}
 
 	// Validate parameters
 	if config.Width <= 0 || config.Height <= 0 {
 		fmt.Println("Error: width and height must be positive integers")
 		flag.Usage()
-		return
+		return exitError
 	}
 	if config.Speed <= 0 {
 		fmt.Println("Error: speed must be a positive integer")
 		flag.Usage()
-		return
+		return exitError
 	}
 	if config.Generations <= 0 {
 		fmt.Println("Error: generations must be a positive integer")
 		flag.Usage()
-		return
+		return exitError
+	}
+	if config.Workers < 0 {
+		fmt.Println("Error: workers must not be negative")
+		flag.Usage()
+		return exitError
+	}
+	if config.StatsFormat == "" {
+		config.StatsFormat = engine.FormatForPath(config.StatsOut)
+	}
+	if config.StatsFormat != engine.FormatCSV && config.StatsFormat != engine.FormatNDJSON {
+		fmt.Println("Error: stats-format must be csv or ndjson")
+		flag.Usage()
+		return exitError
+	}
+	if config.HistoryMB < 0 {
+		fmt.Println("Error: history must not be negative")
+		flag.Usage()
+		return exitError
+	}
+	if config.HistoryMB > 0 && config.Engine == "hashlife" && flagPassed("history") {
+		fmt.Println("Error: history needs a bounded grid; drop --history with --engine=hashlife")
+		return exitError
+	}
+	if config.Output != "" && !config.Headless {
+		fmt.Println("Error: output needs --headless")
+		flag.Usage()
+		return exitError
+	}
+	if config.Density <= 0 || config.Density > 1 {
+		fmt.Println("Error: density must be greater than 0 and at most 1")
+		flag.Usage()
+		return exitError
+	}
+
+	boundary, err := universe.ParseBoundary(config.Boundary)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		flag.Usage()
+		return exitError
+	}
+
+	// Patterns made for another rule (Wireworld, Langton's loops, or the rule
+	// in a pattern file's header) bring it along unless --rule is given
+	var pattern patterns.Pattern2D
+	var macrocell *hashlife.Universe
+	if isMacrocell(config.Pattern) {
+		if macrocell, err = readMacrocell(config.Pattern); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return exitError
+		}
+		if flagPassed("rule") {
+			fmt.Println("Error: a macrocell pattern is read with its own rule; drop --rule")
+			return exitError
+		}
+		config.Rule = macrocell.Rule().Name()
+	} else if config.Pattern != "" {
+		pattern, err = findPattern(config.Pattern)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			if !patterns.IsFile(config.Pattern) {
+				fmt.Print(listPatterns())
+			}
+			return exitError
+		}
+		if pattern.Rule != "" && !flagPassed("rule") {
+			config.Rule = pattern.Rule
+		}
+	}
+
+	rule, err := parseRule(config.Rule)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		flag.Usage()
+		return exitError
 	}
 
-	// Create universe with Conway's rule
-	rule := rules.ConwayRule{}
 	u := universe.New2D(config.Width, config.Height, rule)
+	u.SetBoundary(boundary)
 
 	// Initialize universe
+	var seed int64
 	if config.Pattern != "" {
-		if err := loadPattern(u, config.Pattern); err != nil {
-			fmt.Printf("Error: %v\n", err)
-			fmt.Print(listPatterns())
-			return
-		}
+		loadPattern(u, pattern)
 	} else {
-		u.Randomize()
+		seed = randomize(u, config.Seed)
+	}
+
+	sim, reload, err := newSimulation(u, macrocell)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		flag.Usage()
+		return exitError
+	}
+	sim.SetSeed(seed)
+	sim.SetRate(rateForSpeed(config.Speed))
+
+	var recorder *engine.Recorder
+	if config.StatsOut != "" {
+		recorder = engine.NewRecorder()
+		recorder.Add(sim.Stats())
+		sim.Subscribe(func(ev engine.Event) { recorder.Add(ev.Stats) })
 	}
 
+	if config.Headless {
+		return runHeadless(u, sim, recorder)
+	}
+
+	// Report the seed and how the run ended once the terminal is restored,
+	// so the run can be replayed
+	var outcome engine.Detection
+	defer func() {
+		if recorder != nil {
+			if err := writeStats(recorder, config.StatsOut, config.StatsFormat); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+		}
+		if outcome.Settled() {
+			fmt.Printf("Stopped at generation %d: %s\n", outcome.Generation, outcome)
+		}
+		if seed := sim.Stats().Seed; seed != 0 {
+			fmt.Printf("Random seed: %d (replay with --seed=%d)\n", seed, seed)
+		}
+	}()
+
 	// Initialize termbox
 	if err := termbox.Init(); err != nil {
 		panic(err)
@@ -99,17 +251,62 @@ func main() {
 		panic(err)
 	}
 
-	// Initialize statistics and renderer
-	stats := engine.NewStatistics(u.CountLiving())
+	// Initialize renderer
 	renderer := terminal.NewRenderer2D(config.ShowStats, config.ColorMode)
+	if table, ok := rule.(*rules.RuleTable); ok {
+		renderer.SetPalette(table.Colors())
+	}
 	config.CurrentSpeed = config.Speed
 
 	// Run simulation
 	if config.Interactive {
-		runInteractive(u, stats, renderer)
+		runInteractive(u, sim, reload, renderer)
 	} else {
-		runAutomatic(u, stats, renderer)
+		outcome = runAutomatic(u, sim, renderer)
+	}
+	return exitOK
+}
+
+// randomize fills the universe at the configured density from seed, or from
+// a fresh seed when it is 0, and returns the seed used
+func randomize(u *universe.Universe2D, seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	u.RandomizeWith(universe.RandomOptions{Seed: seed, Density: config.Density})
+	return seed
+}
+
+// writeStats writes the recorded statistics to path, or to stdout for "-"
+func writeStats(recorder *engine.Recorder, path, format string) error {
+	if path == "-" {
+		return recorder.Write(os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write statistics: %w", err)
+	}
+	if err := recorder.Write(f, format); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write statistics: %w", err)
 	}
+	return f.Close()
+}
+
+// rateForSpeed converts a frame delay in milliseconds to frames per second
+func rateForSpeed(speedMs int) float64 {
+	return 1000 / float64(speedMs)
+}
+
+// flagPassed reports whether a flag was set on the command line
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
 }
 
 func listPatterns() string {
@@ -121,38 +318,88 @@ func listPatterns() string {
 	return result
 }
 
-func loadPattern(u *universe.Universe2D, patternName string) error {
-	allPatterns := patterns.AllPatterns()
-	p, exists := allPatterns[patternName]
+// parseRule parses a rulestring or name, or loads the Golly rule table file
+// given with --rule. Rules taken from pattern files are never read as paths.
+func parseRule(s string) (core.Rule, error) {
+	if ext := strings.ToLower(filepath.Ext(s)); flagPassed("rule") && (ext == ".rule" || ext == ".table") {
+		return rules.LoadRuleTable(s)
+	}
+	return rules.Parse(s)
+}
+
+// findPattern returns a built-in pattern, or reads a pattern file
+func findPattern(name string) (patterns.Pattern2D, error) {
+	if patterns.IsFile(name) {
+		return patterns.ReadFile(name)
+	}
+	p, exists := patterns.AllPatterns()[name]
 	if !exists {
-		return fmt.Errorf("pattern '%s' not found", patternName)
+		return patterns.Pattern2D{}, fmt.Errorf("pattern '%s' not found", name)
 	}
+	return p, nil
+}
 
-	// Calculate center position
+// isMacrocell reports whether name is a macrocell (.mc) file
+func isMacrocell(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mc")
+}
+
+// readMacrocell reads a macrocell file into a HashLife universe
+func readMacrocell(path string) (*hashlife.Universe, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pattern: %w", err)
+	}
+	defer f.Close()
+
+	u, _, err := hashlife.ReadMacrocell(f, config.Width, config.Height)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return u, nil
+}
+
+// loadPattern places a pattern in the center of the universe
+func loadPattern(u *universe.Universe2D, p patterns.Pattern2D) {
 	startX := (u.Width() - p.Width) / 2
 	startY := (u.Height() - p.Height) / 2
-
-	// Load pattern into universe
 	p.LoadIntoUniverse(u, startX, startY)
-	return nil
 }
 
-func runAutomatic(u *universe.Universe2D, stats *engine.Statistics, renderer *terminal.Renderer2D) {
-	for i := 0; i < config.Generations; i++ {
-		u.Step()
-		stats.Update(u)
+// runAutomatic runs --generations frames, or until the run settles with
+// --auto-stop, and returns how it settled
+func runAutomatic(u *universe.Universe2D, sim *engine.Simulation, renderer *terminal.Renderer2D) engine.Detection {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-		if err := renderer.Render(u, stats, false); err != nil {
+	var outcome engine.Detection
+	detector := engine.NewDetector(0)
+	if config.AutoStop {
+		outcome = detector.Observe(0, sim.Universe())
+	}
+
+	frames := 0
+	sim.Subscribe(func(ev engine.Event) {
+		if err := renderer.Render(u, &ev.Stats, false); err != nil {
 			panic(err)
 		}
-
-		time.Sleep(time.Duration(config.Speed) * time.Millisecond)
+		frames++
+		if config.AutoStop {
+			outcome = detector.Observe(ev.Generation, ev.Universe)
+		}
+		if frames >= config.Generations || outcome.Settled() {
+			cancel()
+		}
+	})
+	if !outcome.Settled() {
+		_ = sim.Run(ctx)
 	}
+	return outcome
 }
 
-func runInteractive(u *universe.Universe2D, stats *engine.Statistics, renderer *terminal.Renderer2D) {
-	paused := false
-	running := true
+func runInteractive(u *universe.Universe2D, sim *engine.Simulation, reload func(), renderer *terminal.Renderer2D) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	eventQueue := make(chan termbox.Event)
 
 	// Start event polling goroutine
@@ -162,55 +409,63 @@ func runInteractive(u *universe.Universe2D, stats *engine.Statistics, renderer *
 		}
 	}()
 
-	// Initial render
-	_ = renderer.Render(u, stats, true)
-
-	for running {
-		select {
-		case ev := <-eventQueue:
-			if ev.Type == termbox.EventKey {
-				switch ev.Key {
-				case termbox.KeyEsc:
-					running = false
-				case termbox.KeySpace:
-					paused = !paused
-				default:
-					switch ev.Ch {
-					case 'q':
-						running = false
-					case ' ':
-						paused = !paused
-					case 'n':
-						if paused {
-							u.Step()
-							stats.Update(u)
-							_ = renderer.Render(u, stats, true)
-						}
-					case '+', '=':
-						if config.CurrentSpeed > 10 {
-							config.CurrentSpeed -= 10
-						}
-					case '-', '_':
-						if config.CurrentSpeed < 1000 {
-							config.CurrentSpeed += 10
-						}
-					case 'r':
-						u.Clear()
-						u.Randomize()
-						stats.Reset(u.CountLiving())
-						_ = renderer.Render(u, stats, true)
-					}
-				}
-			}
+	// HashLife's plane reaches beyond the viewport, so it keeps no history
+	if config.HistoryMB > 0 && config.Engine != "hashlife" {
+		_ = sim.EnableHistory(config.HistoryMB << 20)
+	}
+
+	// The simulation renders every frame; keys that change the grid render
+	// from within Do so drawing never overlaps a frame
+	sim.Subscribe(func(ev engine.Event) {
+		_ = renderer.Render(u, &ev.Stats, true)
+	})
+	sim.Do(func(core.Universe) {
+		stats := sim.Stats()
+		_ = renderer.Render(u, &stats, true)
+	})
+	go func() { _ = sim.Run(ctx) }()
 
+	for ev := range eventQueue {
+		if ev.Type != termbox.EventKey {
+			continue
+		}
+		switch ev.Key {
+		case termbox.KeyEsc:
+			return
+		case termbox.KeySpace:
+			sim.TogglePause()
 		default:
-			if !paused {
-				u.Step()
-				stats.Update(u)
-				_ = renderer.Render(u, stats, true)
-				time.Sleep(time.Duration(config.CurrentSpeed) * time.Millisecond)
-			} else {
-				time.Sleep(50 * time.Millisecond)
+			switch ev.Ch {
+			case 'q':
+				return
+			case ' ':
+				sim.TogglePause()
+			case 'n':
+				if sim.Paused() {
+					sim.Step(1)
+				}
+			case 'b':
+				sim.Pause()
+				_ = sim.StepBack() // Nothing to do at the oldest recorded generation
+			case '+', '=':
+				if config.CurrentSpeed > 10 {
+					config.CurrentSpeed -= 10
+					sim.SetRate(rateForSpeed(config.CurrentSpeed))
+				}
+			case '-', '_':
+				if config.CurrentSpeed < 1000 {
+					config.CurrentSpeed += 10
+					sim.SetRate(rateForSpeed(config.CurrentSpeed))
+				}
+			case 'r':
+				sim.Do(func(core.Universe) {
+					seed := randomize(u, 0)
+					reload()
+					sim.Reset()
+					sim.SetSeed(seed)
+					stats := sim.Stats()
+					_ = renderer.Render(u, &stats, true)
+				})
 			}
 		}
 	}
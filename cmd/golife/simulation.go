@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+
+	"golife/pkg/core"
+	"golife/pkg/engine"
+	"golife/pkg/hashlife"
+	"golife/pkg/universe"
+)
+
+// engineErr is why the engine stopped advancing, once it has; the
+// simulation is paused when it is set
+var engineErr error
+
+// newSimulation returns a simulation running the configured engine on the
+// cells of the displayed universe u. Engines other than grid step their own
+// universe and copy it into u after every frame; the returned reload function
+// copies u back into the engine after cells are written to it, e.g. on 'r'.
+// Call it from within Simulation.Do. A pattern loaded from a macrocell file
+// is passed as macrocell and needs the hashlife engine.
+func newSimulation(u *universe.Universe2D, macrocell *hashlife.Universe) (*engine.Simulation, func(), error) {
+	if macrocell != nil && config.Engine != "hashlife" {
+		return nil, nil, fmt.Errorf("macrocell patterns are too large for a grid and need --engine=hashlife")
+	}
+	switch config.Engine {
+	case "grid":
+		u.SetWorkers(config.Workers)
+		return engine.NewSimulation(u), func() {}, nil
+	case "hashlife":
+		if !u.Boundary().IsDead() {
+			return nil, nil, fmt.Errorf("hashlife runs on an unbounded plane and needs --boundary=dead")
+		}
+		if config.StepExp < 0 || config.StepExp > hashlife.MaxStepExp {
+			return nil, nil, fmt.Errorf("step-exp must be between 0 and %d", hashlife.MaxStepExp)
+		}
+
+		// The viewport shows the plane from origin; a macrocell pattern is
+		// centered in it
+		hl, origin := macrocell, core.Coord{}
+		if hl == nil {
+			var err error
+			if hl, err = hashlife.New(u.Width(), u.Height(), u.Rule()); err != nil {
+				return nil, nil, err
+			}
+		} else if minC, maxC, ok := hl.Bounds(); ok {
+			origin = core.NewCoord2D((minC.X+maxC.X)/2-u.Width()/2, (minC.Y+maxC.Y)/2-u.Height()/2)
+		}
+		reload := func() {
+			hl.Clear()
+			forEachCell(u, func(c core.Coord) {
+				if state := u.Get(c); state != core.Dead {
+					hl.Set(core.NewCoord2D(origin.X+c.X, origin.Y+c.Y), state)
+				}
+			})
+		}
+		toViewport := func() {
+			u.Clear()
+			end := core.NewCoord2D(origin.X+u.Width(), origin.Y+u.Height())
+			hl.ForEachLiving(origin, end, func(c core.Coord) {
+				u.Set(core.NewCoord2D(c.X-origin.X, c.Y-origin.Y), core.Alive)
+			})
+		}
+		if macrocell == nil {
+			reload()
+		} else {
+			toViewport()
+		}
+
+		sim := engine.NewSimulation(hl)
+		exp := config.StepExp
+		sim.SetStepFunc(func(core.Universe) int {
+			if err := hl.StepPow2(exp); err != nil {
+				// The pattern has spread as far as the plane reaches
+				engineErr = err
+				sim.Pause()
+				return 0
+			}
+			return 1 << exp
+		})
+		sim.Subscribe(func(engine.Event) { toViewport() })
+		return sim, reload, nil
+	case "bitpacked":
+		packed, err := universe.NewBit2D(u.Width(), u.Height(), u.Rule())
+		if err != nil {
+			return nil, nil, err
+		}
+		packed.SetBoundary(u.Boundary())
+		packed.SetWorkers(config.Workers)
+		reload := func() { copyCells(packed, u) }
+		reload()
+
+		sim := engine.NewSimulation(packed)
+		sim.Subscribe(func(engine.Event) { copyCells(u, packed) })
+		return sim, reload, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown engine %q (want grid, bitpacked or hashlife)", config.Engine)
+	}
+}
+
+// copyCells copies every cell of a width x height grid from src to dst
+func copyCells(dst, src core.Universe) {
+	forEachCell(dst, func(c core.Coord) {
+		dst.Set(c, src.Get(c))
+	})
+}
+
+// forEachCell calls fn for every cell of a width x height grid
+func forEachCell(u core.Universe, fn func(c core.Coord)) {
+	size := u.Size()
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			fn(core.NewCoord2D(x, y))
+		}
+	}
+}
@@ -16,13 +16,15 @@ func main() {
 	// Register Go functions as JavaScript callbacks
 	wasm.RegisterCallbacks()
 	js.Global().Get("console").Call("log", "✅ Go functions registered:")
-	js.Global().Get("console").Call("log", "  - goInitUniverse(width, height, depth)")
-	js.Global().Get("console").Call("log", "  - goLoadPattern(name, x, y, z)")
+	js.Global().Get("console").Call("log", "  - goInitUniverse(width, height, depth[, rulestring])")
+	js.Global().Get("console").Call("log", "  - goLoadPattern(nameOrText, x, y, z)")
 	js.Global().Get("console").Call("log", "  - goStep()")
 	js.Global().Get("console").Call("log", "  - goGetLivingCells()")
 	js.Global().Get("console").Call("log", "  - goGetUniverseInfo()")
 	js.Global().Get("console").Call("log", "  - goClearUniverse()")
 	js.Global().Get("console").Call("log", "  - goSetCell(x, y, z, alive)")
+	js.Global().Get("console").Call("log", "  - goSetRule(rulestring)")
+	js.Global().Get("console").Call("log", "  - goSavePattern([name])")
 
 	// Keep the program running
 	<-make(chan struct{})
@@ -1,21 +1,28 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"golife/pkg/core"
+	"golife/pkg/engine"
 	"golife/pkg/patterns"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
 	"log"
 	"net/http"
-	"time"
+	"path/filepath"
+	"strings"
 
 	"github.com/gorilla/websocket"
 )
 
 var (
-	addr     = flag.String("addr", ":8080", "http service address")
-	upgrader = websocket.Upgrader{
+	addr        = flag.String("addr", ":8080", "http service address")
+	history     = flag.Int("history", 16, "History kept per client for scrubbing, in MB")
+	ruleFlag    = flag.String("rule", "5766", "Default rulestring (Bays' 5766 is B6/S567); clients may override with ?rule=")
+	patternFlag = flag.String("pattern", "bays-glider", "Initial pattern: a built-in 3D pattern name or a "+patterns.Life3DExt+" file")
+	upgrader    = websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
 			return true // Allow all origins for development
 		},
@@ -24,9 +31,10 @@ var (
 
 // CellData represents a single living cell for JSON serialization
 type CellData struct {
-	X int `json:"x"`
-	Y int `json:"y"`
-	Z int `json:"z"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Z     int `json:"z"`
+	State int `json:"state"` // 255 = alive, 1-254 = decaying Generations state
 }
 
 // UniverseState represents the current state of the universe
@@ -34,15 +42,43 @@ type UniverseState struct {
 	Cells      []CellData `json:"cells"`
 	Generation int        `json:"generation"`
 	Population int        `json:"population"`
+	Active     int        `json:"activeTiles"` // Tiles evaluated by the last step
+	Tiles      int        `json:"tiles"`
 	Width      int        `json:"width"`
 	Height     int        `json:"height"`
 	Depth      int        `json:"depth"`
+	Rule       string     `json:"rule"`
+	Paused     bool       `json:"paused"`
+	// Recorded generations the client can seek to
+	HistoryStart int `json:"historyStart"`
+	HistoryEnd   int `json:"historyEnd"`
 }
 
+// ControlMessage is sent by the client to control its simulation
+type ControlMessage struct {
+	Type       string `json:"type"`                 // pause, resume, stepBack, seek or loadPattern
+	Generation int    `json:"generation,omitempty"` // Target of seek
+	Pattern    string `json:"pattern,omitempty"`    // Built-in name or pattern text for loadPattern
+}
+
+// universeSize is the side of every client's cubic universe
+const universeSize = 32
+
+// initialPattern is loaded into every client's universe; --pattern replaces it
+var initialPattern = patterns.BaysGlider()
+
 func main() {
 	flag.Parse()
 	log.SetFlags(0)
 
+	if _, err := parseRule(*ruleFlag); err != nil {
+		log.Fatalf("Invalid --rule: %v", err)
+	}
+	var err error
+	if initialPattern, err = findPattern(*patternFlag); err != nil {
+		log.Fatalf("Invalid --pattern: %v", err)
+	}
+
 	http.HandleFunc("/", serveHome)
 	http.HandleFunc("/ws", handleWebSocket)
 
@@ -64,6 +100,16 @@ func serveHome(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	rulestring := r.URL.Query().Get("rule")
+	if rulestring == "" {
+		rulestring = *ruleFlag
+	}
+	rule, err := parseRule(rulestring)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println("WebSocket upgrade error:", err)
@@ -75,47 +121,123 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// Create 3D universe with Bays's Glider
-	rule := rules.Life3D_B6S567{}
-	size := 32
-	u := universe.New3D(size, size, size, rule)
-
-	// Load Bays's Glider in the center
-	glider := patterns.BaysGlider()
-	glider.LoadIntoUniverse3D(u, size/2-2, size/2-2, size/2-2)
+	// Create 3D universe with the initial pattern in the center
+	u := universe.New3D(universeSize, universeSize, universeSize, rule)
+	loadCentered(u, initialPattern)
 
-	generation := 0
-	ticker := time.NewTicker(100 * time.Millisecond) // 10 FPS
-	defer ticker.Stop()
+	log.Printf("WebSocket client connected (rule %s)", rule.Name())
 
-	log.Println("WebSocket client connected")
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
 
-	for range ticker.C {
-		// Extract living cells
+	sim := engine.NewSimulation(u)
+	sim.SetRate(10) // 10 FPS
+	if err := sim.EnableHistory(*history << 20); err != nil {
+		log.Println("History disabled:", err)
+	}
+	send := func(generation int) error {
 		state := extractUniverseState(u, generation)
-
-		// Send to client
-		if err := ws.WriteJSON(state); err != nil {
+		state.Rule = rule.Name()
+		state.Paused = sim.Paused()
+		state.HistoryStart, state.HistoryEnd, _ = sim.History().Range()
+		return ws.WriteJSON(state)
+	}
+	if err := send(0); err != nil {
+		log.Println("WebSocket write error:", err)
+		return
+	}
+	sim.Subscribe(func(ev engine.Event) {
+		if err := send(ev.Generation); err != nil {
 			log.Println("WebSocket write error:", err)
-			return
+			cancel()
 		}
+	})
 
-		// Step simulation (use parallel for better performance)
-		u.StepParallel()
-		generation++
-
-		// Check for websocket close
-		if err := ws.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
-			log.Println("SetReadDeadline error:", err)
-			return
+	// Apply control messages until the client goes away
+	go func() {
+		defer cancel()
+		for {
+			var msg ControlMessage
+			if err := ws.ReadJSON(&msg); err != nil {
+				return
+			}
+			if err := handleControl(sim, msg); err != nil {
+				log.Println("Control message error:", err)
+			}
 		}
-		if _, _, err := ws.NextReader(); err != nil {
-			// Client disconnected
-			break
+	}()
+
+	_ = sim.Run(ctx)
+
+	log.Println("WebSocket client disconnected")
+}
+
+// handleControl applies a control message to a client's simulation. Seeking
+// pauses it so the client can scrub through the history; resume continues
+// from the generation shown. Loading a pattern replaces the universe and
+// starts again from generation 0.
+func handleControl(sim *engine.Simulation, msg ControlMessage) error {
+	switch msg.Type {
+	case "pause":
+		sim.Pause()
+	case "resume":
+		sim.Resume()
+	case "stepBack":
+		sim.Pause()
+		return sim.StepBack()
+	case "seek":
+		sim.Pause()
+		return sim.Seek(msg.Generation)
+	case "loadPattern":
+		p, err := patterns.FindPattern3D(msg.Pattern, sim.Universe().Size())
+		if err != nil {
+			return err
 		}
+		sim.Do(func(u core.Universe) {
+			u.Clear()
+			loadCentered(u.(*universe.Universe3D), p)
+			sim.Reset()
+		})
+		return sim.Seek(0) // Shows the pattern even while paused
+	default:
+		return fmt.Errorf("unknown control message type %q", msg.Type)
 	}
+	return nil
+}
 
-	log.Println("WebSocket client disconnected")
+// parseRule parses a rulestring or name into a rule that can drive the 3D
+// universe
+func parseRule(s string) (core.Rule, error) {
+	rule, err := rules.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := universe.Check3DRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// findPattern returns a built-in 3D pattern or reads a pattern file, either
+// of which must fit in a client's universe
+func findPattern(name string) (*patterns.Pattern3D, error) {
+	if !strings.EqualFold(filepath.Ext(name), patterns.Life3DExt) {
+		return patterns.FindPattern3D(name, core.NewCoord3D(universeSize, universeSize, universeSize))
+	}
+	p, err := patterns.ReadPattern3DFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.Width > universeSize || p.Height > universeSize || p.Depth > universeSize {
+		return nil, fmt.Errorf("%s: pattern of %dx%dx%d cells does not fit in the %d³ universe", name, p.Width, p.Height, p.Depth, universeSize)
+	}
+	return p, nil
+}
+
+// loadCentered loads a pattern into the middle of the universe
+func loadCentered(u *universe.Universe3D, p *patterns.Pattern3D) {
+	size := u.Size()
+	p.LoadIntoUniverse3D(u, (size.X-p.Width)/2, (size.Y-p.Height)/2, (size.Z-p.Depth)/2)
 }
 
 func extractUniverseState(u *universe.Universe3D, generation int) UniverseState {
@@ -127,8 +249,8 @@ func extractUniverseState(u *universe.Universe3D, generation int) UniverseState
 		for y := 0; y < size.Y; y++ {
 			for x := 0; x < size.X; x++ {
 				coord := core.NewCoord3D(x, y, z)
-				if u.Get(coord) != core.Dead {
-					cells = append(cells, CellData{X: x, Y: y, Z: z})
+				if state := u.Get(coord); state != core.Dead {
+					cells = append(cells, CellData{X: x, Y: y, Z: z, State: int(state)})
 				}
 			}
 		}
@@ -138,6 +260,8 @@ func extractUniverseState(u *universe.Universe3D, generation int) UniverseState
 		Cells:      cells,
 		Generation: generation,
 		Population: u.CountLiving(),
+		Active:     u.ActiveTiles(),
+		Tiles:      u.TileCount(),
 		Width:      size.X,
 		Height:     size.Y,
 		Depth:      size.Z,
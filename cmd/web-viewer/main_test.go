@@ -2,9 +2,13 @@ package main
 
 import (
 	"golife/pkg/core"
+	"golife/pkg/engine"
 	"golife/pkg/patterns"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -135,9 +139,9 @@ func TestExtractUniverseState_MultipleCells(t *testing.T) {
 
 	// Verify cell positions (cells are collected in z-y-x order)
 	expectedCells := []CellData{
-		{X: 0, Y: 0, Z: 0},
-		{X: 1, Y: 1, Z: 1},
-		{X: 5, Y: 7, Z: 9},
+		{X: 0, Y: 0, Z: 0, State: int(core.Alive)},
+		{X: 1, Y: 1, Z: 1, State: int(core.Alive)},
+		{X: 5, Y: 7, Z: 9, State: int(core.Alive)},
 	}
 
 	for i, expected := range expectedCells {
@@ -209,3 +213,99 @@ func TestExtractUniverseState_AfterStep(t *testing.T) {
 			len(nextState.Cells), nextState.Population)
 	}
 }
+
+func TestHandleWebSocket_InvalidRule(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/ws?rule=B3/X9", nil)
+	rec := httptest.NewRecorder()
+
+	handleWebSocket(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "invalid rulestring") {
+		t.Errorf("Body should describe the rule error, got %q", rec.Body.String())
+	}
+}
+
+func TestHandleWebSocket_2DOnlyRule(t *testing.T) {
+	for _, rule := range []string{"wireworld", "tlife"} {
+		req := httptest.NewRequest(http.MethodGet, "/ws?rule="+rule, nil)
+		rec := httptest.NewRecorder()
+
+		handleWebSocket(rec, req)
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "3D universe does not support") {
+			t.Errorf("%s: got status %d and body %q, want a 3D rule error", rule, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHandleControl_Seek(t *testing.T) {
+	u := universe.New3D(16, 16, 16, rules.Life3D_B6S567{})
+	patterns.BaysGlider().LoadIntoUniverse3D(u, 6, 6, 6)
+	sim := engine.NewSimulation(u)
+	if err := sim.EnableHistory(0); err != nil {
+		t.Fatalf("EnableHistory returned error: %v", err)
+	}
+	sim.Step(4)
+
+	if err := handleControl(sim, ControlMessage{Type: "seek", Generation: 2}); err != nil {
+		t.Fatalf("seek returned error: %v", err)
+	}
+	if sim.Generation() != 2 || !sim.Paused() {
+		t.Errorf("seek should pause at generation 2, got generation %d (paused %v)", sim.Generation(), sim.Paused())
+	}
+
+	if err := handleControl(sim, ControlMessage{Type: "stepBack"}); err != nil || sim.Generation() != 1 {
+		t.Errorf("stepBack should return to generation 1, got %d (err %v)", sim.Generation(), err)
+	}
+	if err := handleControl(sim, ControlMessage{Type: "resume"}); err != nil || sim.Paused() {
+		t.Errorf("resume should unpause the simulation (err %v)", err)
+	}
+	if err := handleControl(sim, ControlMessage{Type: "seek", Generation: 99}); err == nil {
+		t.Error("Seeking past the history should fail")
+	}
+	if err := handleControl(sim, ControlMessage{Type: "rewind"}); err == nil {
+		t.Error("Unknown message types should be rejected")
+	}
+}
+
+func TestHandleControl_LoadPattern(t *testing.T) {
+	u := universe.New3D(16, 16, 16, rules.Life3D_B6S567{})
+	patterns.BaysGlider().LoadIntoUniverse3D(u, 6, 6, 6)
+	sim := engine.NewSimulation(u)
+	if err := sim.EnableHistory(0); err != nil {
+		t.Fatalf("EnableHistory returned error: %v", err)
+	}
+	sim.Step(3)
+
+	if err := handleControl(sim, ControlMessage{Type: "loadPattern", Pattern: "block"}); err != nil {
+		t.Fatalf("loadPattern returned error: %v", err)
+	}
+	if sim.Generation() != 0 || u.CountLiving() != 8 {
+		t.Errorf("Expected a block at generation 0, got %d cells at generation %d", u.CountLiving(), sim.Generation())
+	}
+	if u.Get(core.NewCoord3D(7, 7, 7)) != core.Alive {
+		t.Error("The block should be centered")
+	}
+
+	text := "x = 3, y = 1, z = 1\n3o!"
+	if err := handleControl(sim, ControlMessage{Type: "loadPattern", Pattern: text}); err != nil {
+		t.Fatalf("loadPattern returned error for pattern text: %v", err)
+	}
+	if u.CountLiving() != 3 {
+		t.Errorf("Expected the 3 cells of the pattern text, got %d", u.CountLiving())
+	}
+
+	if err := handleControl(sim, ControlMessage{Type: "loadPattern", Pattern: "no-such-pattern"}); err == nil {
+		t.Error("Unknown patterns should be rejected")
+	}
+	huge := "x = 1, y = 1, z = 1\n" + strings.Repeat("16777216b", 8) + "!"
+	if err := handleControl(sim, ControlMessage{Type: "loadPattern", Pattern: huge}); err == nil {
+		t.Error("Runs beyond the declared size should be rejected")
+	}
+	if err := handleControl(sim, ControlMessage{Type: "loadPattern", Pattern: "x = 17, y = 1, z = 1\no!"}); err == nil {
+		t.Error("Patterns larger than the universe should be rejected")
+	}
+}
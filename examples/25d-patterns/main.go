@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"golife/pkg/engine"
 	"golife/pkg/patterns"
 	"golife/pkg/rules"
 	"golife/pkg/visualizer/terminal"
@@ -72,17 +73,17 @@ func main() {
 			generations = 10 // These evolve slower
 		}
 
-		for gen := 1; gen <= generations; gen++ {
-			u.Step()
-
-			if gen%2 == 0 || gen == generations {
+		sim := engine.NewSimulation(u)
+		sim.Subscribe(func(ev engine.Event) {
+			if gen := ev.Generation; gen%2 == 0 || gen == generations {
 				fmt.Printf("Generation %d:\n", gen)
 				fmt.Println(view.Render(u))
-				fmt.Println(view.RenderStats(gen, u.CountLiving(), 30.0))
+				fmt.Println(view.RenderStats(gen, ev.Stats.LivingCells, 30.0))
 				fmt.Println()
 				time.Sleep(500 * time.Millisecond)
 			}
-		}
+		})
+		sim.Step(generations)
 
 		fmt.Println("---")
 		fmt.Println()
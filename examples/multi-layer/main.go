@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"golife/pkg/core"
+	"golife/pkg/engine"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
 	"golife/pkg/visualizer/terminal"
@@ -69,13 +70,15 @@ func main() {
 
 	// Demo 5: Evolution over time
 	fmt.Println("5. Evolution (5 generations with Grid Layout):")
-	for gen := 1; gen <= 5; gen++ {
-		u.Step()
-		fmt.Printf("\n--- Generation %d ---\n", gen)
+	sim := engine.NewSimulation(u)
+	unsubscribe := sim.Subscribe(func(ev engine.Event) {
+		fmt.Printf("\n--- Generation %d ---\n", ev.Generation)
 		fmt.Println(view.Render(u))
-		fmt.Println(view.RenderStats(gen, u.CountLiving(), 30.0))
+		fmt.Println(view.RenderStats(ev.Generation, ev.Stats.LivingCells, 30.0))
 		time.Sleep(500 * time.Millisecond)
-	}
+	})
+	sim.Step(5)
+	unsubscribe()
 
 	// Demo 6: Layer Navigation
 	view.ToggleAllLayers() // Back to single layer
@@ -0,0 +1,162 @@
+package core
+
+import "math"
+
+// Neighborhood describes which cells around a cell count as its neighbors
+type Neighborhood struct {
+	// Type selects how offsets are generated
+	Type NeighborhoodType
+
+	// Range is the radius for Moore, VonNeumann, Hexagonal and Circular neighborhoods (default 1)
+	Range int
+
+	// Offsets lists the neighbor offsets when Type is Custom
+	Offsets []Coord
+}
+
+// MooreNeighborhood returns the Moore neighborhood with the given range
+// (all cells within Chebyshev distance r)
+func MooreNeighborhood(r int) Neighborhood {
+	return Neighborhood{Type: Moore, Range: r}
+}
+
+// VonNeumannNeighborhood returns the von Neumann neighborhood with the given range
+// (all cells within Manhattan distance r)
+func VonNeumannNeighborhood(r int) Neighborhood {
+	return Neighborhood{Type: VonNeumann, Range: r}
+}
+
+// HexagonalNeighborhood returns the hexagonal neighborhood with the given range.
+// Hex cells are mapped onto the square grid so that (dx, dy) is a neighbor when
+// max(|dx|, |dy|, |dx-dy|) <= r; at range 1 this is Moore minus the NE and SW corners.
+func HexagonalNeighborhood(r int) Neighborhood {
+	return Neighborhood{Type: Hexagonal, Range: r}
+}
+
+// CircularNeighborhood returns the circular (spherical in 3D) neighborhood with the
+// given range (all cells within Euclidean distance r)
+func CircularNeighborhood(r int) Neighborhood {
+	return Neighborhood{Type: Circular, Range: r}
+}
+
+// CustomNeighborhood returns a neighborhood made of user-supplied offsets
+func CustomNeighborhood(offsets []Coord) Neighborhood {
+	copied := make([]Coord, len(offsets))
+	copy(copied, offsets)
+	return Neighborhood{Type: Custom, Offsets: copied}
+}
+
+// rangeOrDefault returns the configured range, defaulting to 1
+func (n Neighborhood) rangeOrDefault() int {
+	if n.Range <= 0 {
+		return 1
+	}
+	return n.Range
+}
+
+// OffsetsFor returns the neighbor offsets for the given dimension, excluding the cell itself.
+// Hexagonal neighborhoods only span the X/Y plane. Custom offsets are returned as given,
+// with axes beyond the dimension zeroed.
+func (n Neighborhood) OffsetsFor(dim Dimension) []Coord {
+	axes := axesFor(dim)
+
+	if n.Type == Custom {
+		offsets := make([]Coord, 0, len(n.Offsets))
+		for _, o := range n.Offsets {
+			offsets = append(offsets, clampAxes(o, axes))
+		}
+		return offsets
+	}
+
+	r := n.rangeOrDefault()
+	if n.Type == Hexagonal {
+		axes = 2
+	}
+
+	var offsets []Coord
+	span := func(axis int) int {
+		if axis < axes {
+			return r
+		}
+		return 0
+	}
+
+	for dw := -span(3); dw <= span(3); dw++ {
+		for dz := -span(2); dz <= span(2); dz++ {
+			for dy := -span(1); dy <= span(1); dy++ {
+				for dx := -span(0); dx <= span(0); dx++ {
+					if dx == 0 && dy == 0 && dz == 0 && dw == 0 {
+						continue
+					}
+					if n.contains(dx, dy, dz, dw, r) {
+						offsets = append(offsets, Coord{X: dx, Y: dy, Z: dz, W: dw})
+					}
+				}
+			}
+		}
+	}
+	return offsets
+}
+
+// contains reports whether an offset inside the bounding cube belongs to the neighborhood
+func (n Neighborhood) contains(dx, dy, dz, dw, r int) bool {
+	switch n.Type {
+	case VonNeumann:
+		return abs(dx)+abs(dy)+abs(dz)+abs(dw) <= r
+	case Hexagonal:
+		return abs(dx-dy) <= r
+	case Circular:
+		return dx*dx+dy*dy+dz*dz+dw*dw <= r*r
+	default:
+		return true // Moore: the whole cube
+	}
+}
+
+// Distance returns the Euclidean length of an offset
+func (c Coord) Distance() float64 {
+	return math.Sqrt(float64(c.X*c.X + c.Y*c.Y + c.Z*c.Z + c.W*c.W))
+}
+
+// Radius returns the largest absolute offset component (Chebyshev radius)
+// of the neighborhood in the given dimension
+func (n Neighborhood) Radius(dim Dimension) int {
+	if n.Type != Custom {
+		return n.rangeOrDefault()
+	}
+	radius := 0
+	for _, o := range n.OffsetsFor(dim) {
+		radius = max(radius, abs(o.X), abs(o.Y), abs(o.Z), abs(o.W))
+	}
+	return radius
+}
+
+// axesFor returns the number of spatial axes used by a dimension
+func axesFor(dim Dimension) int {
+	switch dim {
+	case Dim2D, Dim25D:
+		return 2
+	case Dim3D:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// clampAxes zeroes the components of an offset beyond the given number of axes
+func clampAxes(c Coord, axes int) Coord {
+	if axes < 4 {
+		c.W = 0
+	}
+	if axes < 3 {
+		c.Z = 0
+	}
+	return c
+}
+
+// abs returns the absolute value of an integer
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
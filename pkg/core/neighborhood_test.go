@@ -0,0 +1,104 @@
+package core
+
+import "testing"
+
+func TestNeighborhoodOffsets_Counts(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Neighborhood
+		dim  Dimension
+		want int
+	}{
+		{"Moore 2D", MooreNeighborhood(1), Dim2D, 8},
+		{"Moore 2.5D", MooreNeighborhood(1), Dim25D, 8},
+		{"Moore 3D", MooreNeighborhood(1), Dim3D, 26},
+		{"Moore 4D", MooreNeighborhood(1), Dim4D, 80},
+		{"Moore 2D range 2", MooreNeighborhood(2), Dim2D, 24},
+		{"VonNeumann 2D", VonNeumannNeighborhood(1), Dim2D, 4},
+		{"VonNeumann 3D", VonNeumannNeighborhood(1), Dim3D, 6},
+		{"VonNeumann 4D", VonNeumannNeighborhood(1), Dim4D, 8},
+		{"VonNeumann 2D range 2", VonNeumannNeighborhood(2), Dim2D, 12},
+		{"Hexagonal 2D", HexagonalNeighborhood(1), Dim2D, 6},
+		{"Hexagonal 2D range 2", HexagonalNeighborhood(2), Dim2D, 18},
+		{"Hexagonal 3D stays planar", HexagonalNeighborhood(1), Dim3D, 6},
+		{"Circular 2D range 1", CircularNeighborhood(1), Dim2D, 4},
+		{"Circular 2D range 2", CircularNeighborhood(2), Dim2D, 12},
+		{"Circular 2D range 5", CircularNeighborhood(5), Dim2D, 80},
+		{"Circular 3D range 2", CircularNeighborhood(2), Dim3D, 32},
+		{"Zero range defaults to 1", Neighborhood{Type: Moore}, Dim2D, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.n.OffsetsFor(tt.dim)); got != tt.want {
+				t.Errorf("Expected %d offsets, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNeighborhoodOffsets_Hexagonal(t *testing.T) {
+	offsets := HexagonalNeighborhood(1).OffsetsFor(Dim2D)
+
+	excluded := map[Coord]bool{
+		NewCoord2D(1, -1): true, // NE
+		NewCoord2D(-1, 1): true, // SW
+	}
+	for _, o := range offsets {
+		if excluded[o] {
+			t.Errorf("Hexagonal neighborhood should not contain %v", o)
+		}
+		if o == (Coord{}) {
+			t.Error("Neighborhood should not contain the cell itself")
+		}
+	}
+}
+
+func TestNeighborhoodOffsets_Custom(t *testing.T) {
+	input := []Coord{NewCoord3D(2, 0, 1), NewCoord2D(-1, 0)}
+	n := CustomNeighborhood(input)
+
+	// The neighborhood keeps its own copy of the offsets
+	input[0] = NewCoord2D(9, 9)
+
+	offsets2D := n.OffsetsFor(Dim2D)
+	if len(offsets2D) != 2 || offsets2D[0] != NewCoord2D(2, 0) {
+		t.Errorf("Custom 2D offsets should drop the Z axis, got %v", offsets2D)
+	}
+
+	offsets3D := n.OffsetsFor(Dim3D)
+	if offsets3D[0] != NewCoord3D(2, 0, 1) {
+		t.Errorf("Custom 3D offsets should keep the Z axis, got %v", offsets3D)
+	}
+
+	if r := n.Radius(Dim3D); r != 2 {
+		t.Errorf("Expected radius 2, got %d", r)
+	}
+}
+
+func TestNeighborhoodRadius(t *testing.T) {
+	if r := MooreNeighborhood(3).Radius(Dim3D); r != 3 {
+		t.Errorf("Expected radius 3, got %d", r)
+	}
+	if r := VonNeumannNeighborhood(0).Radius(Dim2D); r != 1 {
+		t.Errorf("Zero range should default to radius 1, got %d", r)
+	}
+}
+
+func TestCoordDistance(t *testing.T) {
+	tests := []struct {
+		c    Coord
+		want float64
+	}{
+		{NewCoord2D(1, 0), 1},
+		{NewCoord2D(3, 4), 5},
+		{NewCoord3D(2, 3, 6), 7},
+		{NewCoord4D(1, 1, 1, 1), 2},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.Distance(); got != tt.want {
+			t.Errorf("%v.Distance() = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
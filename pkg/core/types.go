@@ -85,6 +85,72 @@ type Rule interface {
 	NeighborWeight(distance float64) float64
 }
 
+// MultiStateRule is implemented by Generations rules, where a cell that fails
+// to survive does not die at once but decays through intermediate states.
+//
+// Only Alive cells count as live neighbors. A dying cell steps down one state
+// per generation (States()-2, ..., 1) until it reaches Dead, regardless of its
+// neighbors, and cannot be revived while decaying.
+type MultiStateRule interface {
+	Rule
+
+	// States returns the total number of states, including Dead and Alive
+	States() int
+}
+
+// NeighborhoodRule is implemented by rules that come with their own neighborhood,
+// such as Larger than Life. Universes adopt it as their default neighborhood.
+//
+// Neighbor contributions are weighted by NeighborWeight at the Euclidean distance
+// of each offset, and birth/survival are decided on the weighted sum rounded to
+// the nearest integer.
+type NeighborhoodRule interface {
+	Rule
+
+	// Neighborhood returns the neighborhood the rule is defined on
+	Neighborhood() Neighborhood
+}
+
+// MaskRule is implemented by 2D rules that depend on the arrangement of the
+// eight Moore neighbors, not just on their number (isotropic non-totalistic rules).
+//
+// The mask has one bit per live neighbor, in reading order around the cell:
+//
+//	bit 0 NW | bit 1 N | bit 2 NE
+//	bit 3 W  |  cell   | bit 4 E
+//	bit 5 SW | bit 6 S | bit 7 SE
+//
+// Universes that cannot provide a mask fall back to ShouldBirth/ShouldSurvive.
+type MaskRule interface {
+	Rule
+
+	// ShouldBirthMask determines if a dead cell with the given live neighbors should become alive
+	ShouldBirthMask(mask uint8) bool
+
+	// ShouldSurviveMask determines if a live cell with the given live neighbors should stay alive
+	ShouldSurviveMask(mask uint8) bool
+}
+
+// TransitionRule is implemented by rules given as an explicit transition
+// function over discrete states, such as Golly rule tables (Wireworld,
+// Langton's loops). Cell states are state indices 0..NumStates()-1 rather
+// than Dead/Alive levels; state 0 is the empty state.
+//
+// Universes that cannot provide ordered neighbor states fall back to
+// ShouldBirth/ShouldSurvive.
+type TransitionRule interface {
+	Rule
+
+	// NumStates returns the number of states, including the empty state 0
+	NumStates() int
+
+	// NeighborOffsets returns the neighbor offsets in the order Next expects them
+	NeighborOffsets() []Coord
+
+	// Next returns the next state of a cell from its state and its neighbors' states
+	Next(current CellState, neighbors []CellState) CellState
+}
+
 // NeighborhoodType defines the type of neighborhood calculation
 type NeighborhoodType int
 
@@ -97,6 +163,12 @@ const (
 
 	// Custom allows user-defined neighborhood offsets
 	Custom
+
+	// Hexagonal emulates a hex grid on a square 2D grid by ignoring the NE and SW corners
+	Hexagonal
+
+	// Circular includes all cells within Euclidean distance Range
+	Circular
 )
 
 // String returns the string representation of the neighborhood type
@@ -108,6 +180,10 @@ func (n NeighborhoodType) String() string {
 		return "VonNeumann"
 	case Custom:
 		return "Custom"
+	case Hexagonal:
+		return "Hexagonal"
+	case Circular:
+		return "Circular"
 	default:
 		return "Unknown"
 	}
@@ -76,6 +76,8 @@ func TestNeighborhoodType(t *testing.T) {
 		{"Moore", Moore, "Moore"},
 		{"VonNeumann", VonNeumann, "VonNeumann"},
 		{"Custom", Custom, "Custom"},
+		{"Hexagonal", Hexagonal, "Hexagonal"},
+		{"Circular", Circular, "Circular"},
 	}
 
 	for _, tt := range tests {
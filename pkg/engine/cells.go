@@ -0,0 +1,67 @@
+package engine
+
+import "golife/pkg/core"
+
+// Universes that can list their living cells beyond their Size: HashLife's
+// unbounded plane lists a box, the sparse universes everything
+type (
+	boxLister interface {
+		Bounds() (minC, maxC core.Coord, ok bool)
+		ForEachLiving(minC, maxC core.Coord, fn func(coord core.Coord))
+	}
+	livingLister interface {
+		ForEachLiving(fn func(coord core.Coord, state core.CellState))
+	}
+)
+
+// forEachLiving calls fn for every non-dead cell of the universe: every
+// listed cell of a universe that can list them, or every cell within its Size
+func forEachLiving(u core.Universe, fn func(c core.Coord, state core.CellState)) {
+	switch l := u.(type) {
+	case boxLister:
+		if minC, maxC, ok := l.Bounds(); ok {
+			maxC = core.Coord{X: maxC.X + 1, Y: maxC.Y + 1, Z: maxC.Z + 1}
+			l.ForEachLiving(minC, maxC, func(c core.Coord) {
+				fn(c, core.Alive)
+			})
+		}
+	case livingLister:
+		l.ForEachLiving(fn)
+	default:
+		forEachCoord(u, func(c core.Coord) {
+			if state := u.Get(c); state != core.Dead {
+				fn(c, state)
+			}
+		})
+	}
+}
+
+// unbounded reports whether the universe's cells may lie beyond its Size
+func unbounded(u core.Universe) bool {
+	switch u.(type) {
+	case boxLister, livingLister:
+		return true
+	}
+	return false
+}
+
+// cellCount returns the number of cells within the universe's Size
+func cellCount(u core.Universe) int {
+	size := u.Size()
+	return size.X * size.Y * max(size.Z, 1) * max(size.W, 1)
+}
+
+// forEachCoord calls fn for every coordinate within the universe's Size,
+// in x, then y, then z, then w order
+func forEachCoord(u core.Universe, fn func(c core.Coord)) {
+	size := u.Size()
+	for w := 0; w < max(size.W, 1); w++ {
+		for z := 0; z < max(size.Z, 1); z++ {
+			for y := 0; y < size.Y; y++ {
+				for x := 0; x < size.X; x++ {
+					fn(core.NewCoord4D(x, y, z, w))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"fmt"
+
+	"golife/pkg/core"
+)
+
+// DefaultDetectorWindow is how many generations a detector remembers when
+// none is given; it bounds the longest period that can be detected
+const DefaultDetectorWindow = 256
+
+// Outcome classifies where a run has settled
+type Outcome int
+
+const (
+	Evolving Outcome = iota // No repeat seen yet
+	Extinct                 // Every cell is dead
+	Still                   // The universe no longer changes
+	Periodic                // The universe repeats in place every Period generations
+	Moving                  // The pattern repeats shifted by Displacement every Period generations
+)
+
+// String returns the name of the outcome
+func (o Outcome) String() string {
+	switch o {
+	case Extinct:
+		return "extinct"
+	case Still:
+		return "still"
+	case Periodic:
+		return "periodic"
+	case Moving:
+		return "moving"
+	default:
+		return "evolving"
+	}
+}
+
+// Detection is the result of observing a generation
+type Detection struct {
+	Outcome      Outcome
+	Generation   int        // Generation at which the outcome was detected
+	Period       int        // Generations per cycle; 1 for a still universe
+	Displacement core.Coord // Shift per period of a moving pattern
+	Dimension    core.Dimension
+}
+
+// Settled reports whether the run has stopped evolving
+func (d Detection) Settled() bool {
+	return d.Outcome != Evolving
+}
+
+// String describes the detection, e.g. "periodic with period 2"
+func (d Detection) String() string {
+	switch d.Outcome {
+	case Extinct:
+		return fmt.Sprintf("extinct at generation %d", d.Generation)
+	case Still:
+		return fmt.Sprintf("still since generation %d", d.Generation-1)
+	case Periodic:
+		return fmt.Sprintf("periodic with period %d", d.Period)
+	case Moving:
+		dx, dy, dz := d.Displacement.X, d.Displacement.Y, d.Displacement.Z
+		if d.Dimension == core.Dim2D {
+			return fmt.Sprintf("moving by (%d,%d) every %d generations", dx, dy, d.Period)
+		}
+		return fmt.Sprintf("moving by (%d,%d,%d) every %d generations", dx, dy, dz, d.Period)
+	default:
+		return "evolving"
+	}
+}
+
+// Detector recognizes extinct, still, periodic and moving runs. It hashes
+// the living cells of every observed generation relative to their bounding
+// box, so a pattern that repeats shifted (a spaceship) is recognized as well
+// as one that repeats in place. A repeat is only found within the window
+// of remembered generations.
+//
+// Translated repeats are exact on an unbounded or dead-boundary universe;
+// on a torus a spaceship is only recognized between wraps.
+type Detector struct {
+	window int
+	seen   map[uint64]sighting
+	order  []uint64 // Hashes in observation order, oldest first
+	cells  []livingCell
+}
+
+// sighting is where and when a hashed shape was observed
+type sighting struct {
+	generation int
+	origin     core.Coord // Minimum corner of the bounding box
+}
+
+// livingCell is a non-dead cell collected for hashing
+type livingCell struct {
+	coord core.Coord
+	state core.CellState
+}
+
+// NewDetector creates a detector remembering window generations, or
+// DefaultDetectorWindow when window is 0
+func NewDetector(window int) *Detector {
+	if window <= 0 {
+		window = DefaultDetectorWindow
+	}
+	return &Detector{window: window, seen: make(map[uint64]sighting)}
+}
+
+// Reset forgets every observed generation
+func (d *Detector) Reset() {
+	clear(d.seen)
+	d.order = d.order[:0]
+}
+
+// Observe records the universe at a generation and reports whether it
+// repeats an earlier generation
+func (d *Detector) Observe(generation int, u core.Universe) Detection {
+	det := Detection{Generation: generation, Dimension: u.Dimension()}
+
+	origin, hash, living := d.hash(u)
+	if !living {
+		det.Outcome = Extinct
+		return det
+	}
+
+	if prev, ok := d.seen[hash]; ok && prev.generation < generation {
+		det.Period = generation - prev.generation
+		det.Displacement = core.Coord{
+			X: origin.X - prev.origin.X,
+			Y: origin.Y - prev.origin.Y,
+			Z: origin.Z - prev.origin.Z,
+		}
+		switch {
+		case det.Displacement != core.Coord{}:
+			det.Outcome = Moving
+		case det.Period == 1:
+			det.Outcome = Still
+		default:
+			det.Outcome = Periodic
+		}
+		return det
+	}
+
+	if _, ok := d.seen[hash]; !ok {
+		d.order = append(d.order, hash)
+	}
+	d.seen[hash] = sighting{generation: generation, origin: origin}
+	for len(d.order) > d.window {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	return det
+}
+
+// hash returns the minimum corner of the living cells' bounding box and a
+// hash of the cells relative to it. living is false when no cell is alive.
+func (d *Detector) hash(u core.Universe) (origin core.Coord, hash uint64, living bool) {
+	d.cells = d.cells[:0]
+	forEachLiving(u, func(c core.Coord, state core.CellState) {
+		d.cells = append(d.cells, livingCell{c, state})
+	})
+	if len(d.cells) == 0 {
+		return core.Coord{}, 0, false
+	}
+
+	origin = d.cells[0].coord
+	for _, c := range d.cells[1:] {
+		origin.X = min(origin.X, c.coord.X)
+		origin.Y = min(origin.Y, c.coord.Y)
+		origin.Z = min(origin.Z, c.coord.Z)
+	}
+
+	// Sum a mixed hash of each cell, so the result does not depend on the
+	// order the universe lists its cells in
+	for _, c := range d.cells {
+		v := uint64(uint16(c.coord.X-origin.X)) | uint64(uint16(c.coord.Y-origin.Y))<<16 |
+			uint64(uint16(c.coord.Z-origin.Z))<<32 | uint64(c.state)<<48
+		hash += mix64(v)
+	}
+	hash ^= uint64(len(d.cells))
+	return origin, hash, true
+}
+
+// mix64 is the splitmix64 finalizer, spreading every input bit over the output
+func mix64(v uint64) uint64 {
+	v ^= v >> 30
+	v *= 0xbf58476d1ce4e5b9
+	v ^= v >> 27
+	v *= 0x94d049bb133111eb
+	v ^= v >> 31
+	return v
+}
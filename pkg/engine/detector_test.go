@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/hashlife"
+	"golife/pkg/patterns"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"testing"
+)
+
+// runUntilSettled steps u until the detector reports an outcome or limit
+// generations have passed
+func runUntilSettled(u core.Universe, limit int) Detection {
+	d := NewDetector(0)
+	det := d.Observe(0, u)
+	for gen := 1; gen <= limit && !det.Settled(); gen++ {
+		u.Step()
+		det = d.Observe(gen, u)
+	}
+	return det
+}
+
+// setCells sets the given 2D cells alive
+func setCells(u core.Universe, cells [][2]int) {
+	for _, c := range cells {
+		u.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+	}
+}
+
+func TestDetector_2D(t *testing.T) {
+	glider := [][2]int{{11, 10}, {12, 11}, {10, 12}, {11, 12}, {12, 12}}
+	tests := []struct {
+		name         string
+		cells        [][2]int
+		outcome      Outcome
+		generation   int
+		period       int
+		displacement core.Coord
+	}{
+		{"single cell", [][2]int{{5, 5}}, Extinct, 1, 0, core.Coord{}},
+		{"block", [][2]int{{5, 5}, {6, 5}, {5, 6}, {6, 6}}, Still, 1, 1, core.Coord{}},
+		{"blinker", [][2]int{{4, 5}, {5, 5}, {6, 5}}, Periodic, 2, 2, core.Coord{}},
+		{"glider", glider, Moving, 4, 4, core.NewCoord2D(1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := universe.New2D(40, 40, rules.ConwayRule{})
+			setCells(u, tt.cells)
+
+			det := runUntilSettled(u, 50)
+			if det.Outcome != tt.outcome || det.Generation != tt.generation ||
+				det.Period != tt.period || det.Displacement != tt.displacement {
+				t.Errorf("Got %s (%+v), want %s at generation %d with period %d and displacement %v",
+					det, det, tt.outcome, tt.generation, tt.period, tt.displacement)
+			}
+		})
+	}
+}
+
+func TestDetector_3D(t *testing.T) {
+	block := universe.New3D(16, 16, 16, rules.Life3D_B6S567{})
+	patterns.Block3D().LoadIntoUniverse3D(block, 7, 7, 7)
+	if det := runUntilSettled(block, 10); det.Outcome != Still {
+		t.Errorf("A 3D block should be still, got %s", det)
+	}
+
+	// A shape seen again shifted, as a 3D spaceship would be
+	u := universe.New3D(16, 16, 16, rules.Life3D_B6S567{})
+	shape := func(dx, dy, dz int) {
+		u.Clear()
+		for _, c := range [][3]int{{2, 2, 2}, {3, 2, 2}, {2, 3, 4}} {
+			u.Set(core.NewCoord3D(c[0]+dx, c[1]+dy, c[2]+dz), core.Alive)
+		}
+	}
+	d := NewDetector(0)
+	shape(0, 0, 0)
+	d.Observe(0, u)
+	u.Set(core.NewCoord3D(9, 9, 9), core.Alive) // Another shape in between
+	d.Observe(1, u)
+	shape(1, 0, 2)
+	det := d.Observe(2, u)
+	if det.Outcome != Moving || det.Period != 2 || det.Displacement != core.NewCoord3D(1, 0, 2) {
+		t.Errorf("Expected a move by (1,0,2) every 2 generations, got %s", det)
+	}
+	if got := det.String(); got != "moving by (1,0,2) every 2 generations" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestDetector_UnboundedUniverses(t *testing.T) {
+	glider := [][2]int{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}}
+
+	hl, err := hashlife.New(8, 8, rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("hashlife.New returned error: %v", err)
+	}
+	setCells(hl, glider)
+
+	sparse, err := universe.NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	setCells(sparse, glider)
+
+	for name, u := range map[string]core.Universe{"hashlife": hl, "sparse": sparse} {
+		// Past the 8x8 viewport, the glider is still seen on the whole plane
+		for i := 0; i < 40; i++ {
+			u.Step()
+		}
+		det := runUntilSettled(u, 10)
+		if det.Outcome != Moving || det.Displacement != core.NewCoord2D(1, 1) {
+			t.Errorf("%s: glider should be moving by (1,1), got %s", name, det)
+		}
+	}
+}
+
+func TestDetector_Window(t *testing.T) {
+	u := universe.New2D(20, 20, rules.ConwayRule{})
+	setCells(u, [][2]int{{4, 5}, {5, 5}, {6, 5}})
+
+	// A window of one generation cannot see a period-2 repeat
+	d := NewDetector(1)
+	for gen := 0; gen < 6; gen++ {
+		if det := d.Observe(gen, u); det.Settled() {
+			t.Fatalf("Generation %d: period 2 should be out of a 1-generation window, got %s", gen, det)
+		}
+		u.Step()
+	}
+
+	d.Reset()
+	if det := d.Observe(0, u); det.Settled() {
+		t.Errorf("Reset should forget earlier generations, got %s", det)
+	}
+}
+
+func BenchmarkDetector_Observe_256(b *testing.B) {
+	u := universe.New2D(256, 256, rules.ConwayRule{})
+	universe.RandomFill(u, universe.RandomOptions{Seed: 1, Density: 0.3})
+	d := NewDetector(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Observe(i, u)
+	}
+}
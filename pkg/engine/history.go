@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+
+	"golife/pkg/core"
+)
+
+// DefaultHistoryBudget is the memory budget of a history when none is given
+const DefaultHistoryBudget = 64 << 20
+
+// keyframeInterval bounds how many deltas a seek replays
+const keyframeInterval = 32
+
+// History records the generations of a universe in a ring buffer bounded by
+// a memory budget, so a simulation can step back or seek to any recorded
+// generation. Each generation is stored as the cells that changed since the
+// previous one, with a full keyframe every keyframeInterval generations and
+// at the oldest entry. When the budget is exceeded the oldest generations are
+// dropped.
+//
+// Only cells within the universe's Size are recorded; it works for 2D, 2.5D
+// and 3D grids alike, but not for HashLife or the sparse universes, whose
+// cells lie beyond it. History is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	budget  int
+	used    int
+	cells   int              // Cells per generation
+	entries []historyEntry   // Oldest first
+	last    []core.CellState // Cells of the newest entry
+}
+
+// historyEntry is one recorded generation: a full keyframe, or the cells
+// that changed since the previous entry
+type historyEntry struct {
+	generation int
+	keyframe   []core.CellState
+	indices    []int32
+	states     []core.CellState
+}
+
+// size returns the memory the entry holds, in bytes
+func (e *historyEntry) size() int {
+	return len(e.keyframe) + 5*len(e.indices)
+}
+
+// NewHistory creates a history using at most budget bytes, or
+// DefaultHistoryBudget when budget is 0
+func NewHistory(budget int) *History {
+	if budget <= 0 {
+		budget = DefaultHistoryBudget
+	}
+	return &History{budget: budget}
+}
+
+// Record stores the cells of u as the given generation. Recorded generations
+// at or after it are discarded first, so recording after a seek replaces the
+// old future.
+func (h *History) Record(generation int, u core.Universe) {
+	cells := snapshot(u)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.truncate(generation)
+
+	entry := historyEntry{generation: generation}
+	n := len(h.entries)
+	if n == 0 || len(cells) != h.cells || h.sinceKeyframe() >= keyframeInterval {
+		if len(cells) != h.cells {
+			h.clear() // The universe was resized
+			h.cells = len(cells)
+		}
+		entry.keyframe = cells
+	} else {
+		for i, state := range cells {
+			if state != h.last[i] {
+				entry.indices = append(entry.indices, int32(i))
+				entry.states = append(entry.states, state)
+			}
+		}
+		if entry.size() >= len(cells) {
+			entry = historyEntry{generation: generation, keyframe: cells}
+		}
+	}
+
+	h.entries = append(h.entries, entry)
+	h.used += entry.size()
+	h.last = cells
+	for h.used > h.budget && len(h.entries) > 1 {
+		h.dropOldest()
+	}
+}
+
+// Restore writes the recorded cells of a generation back into u
+func (h *History) Restore(generation int, u core.Universe) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cells, err := h.cellsAt(generation)
+	if err != nil {
+		return err
+	}
+	if len(cells) != cellCount(u) {
+		return fmt.Errorf("generation %d was recorded for a universe of another size", generation)
+	}
+
+	u.Clear()
+	i := 0
+	forEachCoord(u, func(c core.Coord) {
+		if cells[i] != core.Dead {
+			u.Set(c, cells[i])
+		}
+		i++
+	})
+	return nil
+}
+
+// Before returns the newest recorded generation before generation
+func (h *History) Before(generation int) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].generation < generation {
+			return h.entries[i].generation, true
+		}
+	}
+	return 0, false
+}
+
+// Range returns the oldest and newest recorded generations
+func (h *History) Range() (oldest, newest int, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return 0, 0, false
+	}
+	return h.entries[0].generation, h.entries[len(h.entries)-1].generation, true
+}
+
+// Len returns the number of recorded generations
+func (h *History) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
+
+// MemoryUsage returns the bytes held by the recorded generations
+func (h *History) MemoryUsage() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.used
+}
+
+// Clear drops every recorded generation
+func (h *History) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clear()
+}
+
+func (h *History) clear() {
+	h.entries = nil
+	h.last = nil
+	h.used = 0
+}
+
+// truncate drops the entries at or after generation
+func (h *History) truncate(generation int) {
+	n := len(h.entries)
+	for n > 0 && h.entries[n-1].generation >= generation {
+		n--
+		h.used -= h.entries[n].size()
+	}
+	if n == len(h.entries) {
+		return
+	}
+	h.entries = h.entries[:n]
+	h.last = nil
+	if n > 0 {
+		h.last, _ = h.cellsAt(h.entries[n-1].generation)
+	}
+}
+
+// sinceKeyframe returns the number of deltas after the newest keyframe
+func (h *History) sinceKeyframe() int {
+	n := 0
+	for i := len(h.entries) - 1; i >= 0 && h.entries[i].keyframe == nil; i-- {
+		n++
+	}
+	return n
+}
+
+// dropOldest evicts the oldest entry, turning the next one into a keyframe
+func (h *History) dropOldest() {
+	oldest := h.entries[0]
+	h.used -= oldest.size()
+	h.entries = h.entries[1:]
+
+	next := &h.entries[0]
+	if next.keyframe == nil {
+		// The oldest keyframe is not shared with h.last, which is always
+		// newer, so it can be patched in place
+		cells := oldest.keyframe
+		for i, idx := range next.indices {
+			cells[idx] = next.states[i]
+		}
+		h.used += len(cells) - next.size()
+		next.keyframe, next.indices, next.states = cells, nil, nil
+	}
+}
+
+// cellsAt rebuilds the cells of a recorded generation from the nearest
+// keyframe at or before it
+func (h *History) cellsAt(generation int) ([]core.CellState, error) {
+	target := -1
+	for i, e := range h.entries {
+		if e.generation == generation {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return nil, fmt.Errorf("generation %d is not in the history", generation)
+	}
+
+	start := target
+	for h.entries[start].keyframe == nil {
+		start--
+	}
+	cells := make([]core.CellState, len(h.entries[start].keyframe))
+	copy(cells, h.entries[start].keyframe)
+	for _, e := range h.entries[start+1 : target+1] {
+		for i, idx := range e.indices {
+			cells[idx] = e.states[i]
+		}
+	}
+	return cells, nil
+}
+
+// snapshot returns the states of every cell within the universe's Size
+func snapshot(u core.Universe) []core.CellState {
+	cells := make([]core.CellState, 0, cellCount(u))
+	forEachCoord(u, func(c core.Coord) {
+		cells = append(cells, u.Get(c))
+	})
+	return cells
+}
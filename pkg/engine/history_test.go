@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/hashlife"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"testing"
+)
+
+// sameCells reports whether two universes of the same size hold the same cells
+func sameCells(a, b core.Universe) bool {
+	equal := true
+	forEachCoord(a, func(c core.Coord) {
+		if a.Get(c) != b.Get(c) {
+			equal = false
+		}
+	})
+	return equal
+}
+
+func TestHistory_RestoreEveryGeneration(t *testing.T) {
+	u := universe.New2D(40, 30, rules.ConwayRule{})
+	universe.RandomFill(u, universe.RandomOptions{Seed: 11, Density: 0.35})
+	h := NewHistory(0)
+
+	var clones []core.Universe
+	for gen := 0; gen <= 80; gen++ {
+		h.Record(gen, u)
+		clones = append(clones, u.Clone())
+		u.Step()
+	}
+
+	for gen, want := range clones {
+		if err := h.Restore(gen, u); err != nil {
+			t.Fatalf("Restore(%d) returned error: %v", gen, err)
+		}
+		if !sameCells(u, want) {
+			t.Fatalf("Restore(%d) does not match the recorded generation", gen)
+		}
+	}
+	if err := h.Restore(81, u); err == nil {
+		t.Error("Restoring an unrecorded generation should fail")
+	}
+}
+
+func TestHistory_Restore4D(t *testing.T) {
+	u := universe.New4D(5, 5, 5, 5, rules.Life4D_B9S7_10{})
+	for _, c := range []core.Coord{core.NewCoord4D(1, 1, 1, 0), core.NewCoord4D(2, 2, 2, 2), core.NewCoord4D(3, 3, 3, 4)} {
+		u.Set(c, core.Alive)
+	}
+	h := NewHistory(0)
+	h.Record(0, u)
+	want := u.Clone()
+
+	u.Step() // Lone cells die
+	h.Record(1, u)
+	if u.CountLiving() != 0 {
+		t.Fatalf("Expected the lone cells to die, %d are alive", u.CountLiving())
+	}
+
+	if err := h.Restore(0, u); err != nil {
+		t.Fatalf("Restore(0) returned error: %v", err)
+	}
+	if u.CountLiving() != 3 || !sameCells(u, want) {
+		t.Errorf("Restore(0) should bring back the cells in every w slice, got %d living", u.CountLiving())
+	}
+}
+
+func TestHistory_BudgetDropsOldest(t *testing.T) {
+	u := universe.New3D(16, 16, 16, rules.Life3D_B6S567{})
+	universe.RandomFill(u, universe.RandomOptions{Seed: 5, Density: 0.3})
+	budget := 3 * 16 * 16 * 16 // Room for about three keyframes
+	h := NewHistory(budget)
+
+	var clones []core.Universe
+	for gen := 0; gen < 60; gen++ {
+		h.Record(gen, u)
+		clones = append(clones, u.Clone())
+		u.Step()
+		if h.MemoryUsage() > budget {
+			t.Fatalf("Generation %d: history uses %d bytes, over its %d byte budget", gen, h.MemoryUsage(), budget)
+		}
+	}
+
+	oldest, newest, ok := h.Range()
+	if !ok || newest != 59 || oldest == 0 {
+		t.Fatalf("Range() = %d, %d, %v; want the oldest generations dropped", oldest, newest, ok)
+	}
+	for gen := oldest; gen <= newest; gen++ {
+		if err := h.Restore(gen, u); err != nil {
+			t.Fatalf("Restore(%d) returned error: %v", gen, err)
+		}
+		if !sameCells(u, clones[gen]) {
+			t.Fatalf("Restore(%d) does not match after eviction", gen)
+		}
+	}
+}
+
+func TestHistory_RecordAfterSeekReplacesFuture(t *testing.T) {
+	u := universe.New2D(10, 10, rules.ConwayRule{})
+	h := NewHistory(0)
+	for gen := 0; gen < 5; gen++ {
+		h.Record(gen, u)
+	}
+
+	u.Set(core.NewCoord2D(1, 1), core.Alive)
+	h.Record(2, u)
+
+	if _, newest, _ := h.Range(); newest != 2 || h.Len() != 3 {
+		t.Errorf("Recording generation 2 should drop generations 3-4, got newest %d and %d entries", newest, h.Len())
+	}
+	if err := h.Restore(2, u); err != nil || u.CountLiving() != 1 {
+		t.Errorf("Restore(2) should return the new generation 2, got %d cells (err %v)", u.CountLiving(), err)
+	}
+}
+
+func TestSimulation_StepBack(t *testing.T) {
+	tests := []struct {
+		name string
+		u    core.Universe
+	}{
+		{"2D", universe.New2D(32, 32, rules.ConwayRule{})},
+		{"2.5D", universe.New25D(20, 20, 3, rules.ConwayRule{})},
+		{"3D", universe.New3D(12, 12, 12, rules.Life3D_B6S567{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			universe.RandomFill(tt.u, universe.RandomOptions{Seed: 2, Density: 0.3})
+			sim := NewSimulation(tt.u)
+			if err := sim.EnableHistory(0); err != nil {
+				t.Fatalf("EnableHistory returned error: %v", err)
+			}
+
+			initial := tt.u.Clone()
+			sim.Step(3)
+			third := tt.u.Clone()
+			sim.Step(2)
+
+			var published []int
+			sim.Subscribe(func(ev Event) { published = append(published, ev.Generation) })
+
+			if err := sim.StepBack(); err != nil {
+				t.Fatalf("StepBack returned error: %v", err)
+			}
+			if sim.Generation() != 4 || sim.Stats().Generation != 4 {
+				t.Errorf("StepBack should return to generation 4, got %d", sim.Generation())
+			}
+			if err := sim.Seek(3); err != nil || !sameCells(tt.u, third) {
+				t.Errorf("Seek(3) should restore generation 3 (err %v)", err)
+			}
+			if err := sim.Seek(0); err != nil || !sameCells(tt.u, initial) {
+				t.Errorf("Seek(0) should restore the initial state (err %v)", err)
+			}
+			if err := sim.StepBack(); err == nil {
+				t.Error("StepBack at the oldest generation should fail")
+			}
+			if len(published) != 3 || published[2] != 0 {
+				t.Errorf("Each seek should publish an event, got %v", published)
+			}
+
+			// Stepping on from a seek gives the same generations again
+			sim.Step(3)
+			if !sameCells(tt.u, third) {
+				t.Error("Stepping on from generation 0 should reach the same generation 3")
+			}
+		})
+	}
+}
+
+func TestSimulation_StepBackWithoutHistory(t *testing.T) {
+	sim := newBlinkerSimulation()
+	sim.Step(1)
+	if err := sim.StepBack(); err == nil {
+		t.Error("StepBack without EnableHistory should fail")
+	}
+}
+
+func TestSimulation_EnableHistoryUnbounded(t *testing.T) {
+	hl, err := hashlife.New(8, 8, rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("hashlife.New returned error: %v", err)
+	}
+	sparse, err := universe.NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	for name, u := range map[string]core.Universe{"hashlife": hl, "sparse": sparse} {
+		sim := NewSimulation(u)
+		if err := sim.EnableHistory(0); err == nil {
+			t.Errorf("EnableHistory should refuse a %s universe", name)
+		}
+		if sim.History() != nil {
+			t.Errorf("A refused %s history should stay disabled", name)
+		}
+	}
+}
+
+func BenchmarkHistory_Record_256(b *testing.B) {
+	u := universe.New2D(256, 256, rules.ConwayRule{})
+	universe.RandomFill(u, universe.RandomOptions{Seed: 1, Density: 0.3})
+	h := NewHistory(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.Step()
+		h.Record(i, u)
+	}
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Time-series formats a Recorder writes
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Record is the statistics of one generation in a time series
+type Record struct {
+	Generation int     `json:"generation"`
+	Population int     `json:"population"`
+	Births     int     `json:"births"`
+	Deaths     int     `json:"deaths"`
+	MinX       int     `json:"minX"` // Inclusive bounding box of the living cells
+	MinY       int     `json:"minY"`
+	MinZ       int     `json:"minZ"`
+	MaxX       int     `json:"maxX"`
+	MaxY       int     `json:"maxY"`
+	MaxZ       int     `json:"maxZ"`
+	Density    float64 `json:"density"`
+	MeanAge    float64 `json:"meanAge"`
+	MaxAge     int     `json:"maxAge"`
+	Layers     []int   `json:"layers,omitempty"` // Living cells per layer of a 2.5D universe
+	StepNanos  int64   `json:"stepNs"`           // Time the step to this generation took
+}
+
+// NewRecord captures the statistics of the current generation
+func NewRecord(stats Statistics) Record {
+	return Record{
+		Generation: stats.Generation,
+		Population: stats.LivingCells,
+		Births:     stats.Births,
+		Deaths:     stats.Deaths,
+		MinX:       stats.BoundsMin.X,
+		MinY:       stats.BoundsMin.Y,
+		MinZ:       stats.BoundsMin.Z,
+		MaxX:       stats.BoundsMax.X,
+		MaxY:       stats.BoundsMax.Y,
+		MaxZ:       stats.BoundsMax.Z,
+		Density:    stats.Density,
+		MeanAge:    stats.MeanAge,
+		MaxAge:     stats.MaxAge,
+		Layers:     stats.Layers,
+		StepNanos:  stats.StepDuration.Nanoseconds(),
+	}
+}
+
+// Recorder keeps the statistics of every generation of a run so the
+// population curve can be analyzed offline, and writes them as CSV or
+// NDJSON (one JSON object per line).
+type Recorder struct {
+	records []Record
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Add records the statistics of a generation
+func (r *Recorder) Add(stats Statistics) {
+	r.records = append(r.records, NewRecord(stats))
+}
+
+// Records returns the recorded generations in order
+func (r *Recorder) Records() []Record {
+	return r.records
+}
+
+// FormatForPath picks the format from a file extension: NDJSON for .ndjson,
+// .jsonl and .json, CSV otherwise
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl", ".json":
+		return FormatNDJSON
+	default:
+		return FormatCSV
+	}
+}
+
+// Write writes the records in the given format
+func (r *Recorder) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return r.WriteCSV(w)
+	case FormatNDJSON:
+		return r.WriteNDJSON(w)
+	default:
+		return fmt.Errorf("unknown stats format %q (want csv or ndjson)", format)
+	}
+}
+
+// WriteNDJSON writes one JSON object per generation
+func (r *Recorder) WriteNDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, rec := range r.records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes a header and one row per generation. Per-layer counts get
+// one layer_<z> column per layer.
+func (r *Recorder) WriteCSV(w io.Writer) error {
+	layers := 0
+	for _, rec := range r.records {
+		layers = max(layers, len(rec.Layers))
+	}
+
+	header := []string{
+		"generation", "population", "births", "deaths",
+		"min_x", "min_y", "min_z", "max_x", "max_y", "max_z",
+		"density", "mean_age", "max_age", "step_ns",
+	}
+	for z := 0; z < layers; z++ {
+		header = append(header, "layer_"+strconv.Itoa(z))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	row := make([]string, 0, len(header))
+	for _, rec := range r.records {
+		row = row[:0]
+		for _, v := range []int{
+			rec.Generation, rec.Population, rec.Births, rec.Deaths,
+			rec.MinX, rec.MinY, rec.MinZ, rec.MaxX, rec.MaxY, rec.MaxZ,
+		} {
+			row = append(row, strconv.Itoa(v))
+		}
+		row = append(row,
+			strconv.FormatFloat(rec.Density, 'g', -1, 64),
+			strconv.FormatFloat(rec.MeanAge, 'g', -1, 64),
+			strconv.Itoa(rec.MaxAge),
+			strconv.FormatInt(rec.StepNanos, 10),
+		)
+		for z := 0; z < layers; z++ {
+			count := 0
+			if z < len(rec.Layers) {
+				count = rec.Layers[z]
+			}
+			row = append(row, strconv.Itoa(count))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
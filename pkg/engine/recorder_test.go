@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"strings"
+	"testing"
+)
+
+// recordBlinker records 3 generations of a blinker in the top layer of a
+// 2.5D universe
+func recordBlinker() *Recorder {
+	u := universe.New25D(10, 10, 2, rules.ConwayRule{})
+	for x := 3; x < 6; x++ {
+		u.Set(core.NewCoord3D(x, 5, 1), core.Alive)
+	}
+	sim := NewSimulation(u)
+	rec := NewRecorder()
+	rec.Add(sim.Stats())
+	sim.Subscribe(func(ev Event) { rec.Add(ev.Stats) })
+	sim.Step(3)
+	return rec
+}
+
+func TestRecorder_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := recordBlinker().Write(&buf, FormatCSV); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Expected a header and 4 generations, got %d rows", len(rows))
+	}
+	header := strings.Join(rows[0], ",")
+	if header != "generation,population,births,deaths,min_x,min_y,min_z,max_x,max_y,max_z,density,mean_age,max_age,step_ns,layer_0,layer_1" {
+		t.Errorf("Unexpected header %q", header)
+	}
+	// Generation 1: the blinker turns vertical around (4,5) in layer 1
+	want := []string{"1", "3", "2", "2", "4", "4", "1", "4", "6", "1", "0.015"}
+	for i, v := range want {
+		if rows[2][i] != v {
+			t.Errorf("Column %s of generation 1 = %q, want %q", rows[0][i], rows[2][i], v)
+		}
+	}
+	if rows[2][14] != "0" || rows[2][15] != "3" {
+		t.Errorf("Layer columns should be 0 and 3, got %q and %q", rows[2][14], rows[2][15])
+	}
+}
+
+func TestRecorder_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := recordBlinker().Write(&buf, FormatNDJSON); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d", len(lines))
+	}
+	var rec Record
+	if err := json.Unmarshal([]byte(lines[3]), &rec); err != nil {
+		t.Fatalf("Line is not valid JSON: %v", err)
+	}
+	if rec.Generation != 3 || rec.Population != 3 || rec.Births != 2 || len(rec.Layers) != 2 {
+		t.Errorf("Unexpected record for generation 3: %+v", rec)
+	}
+	if rec.StepNanos <= 0 {
+		t.Errorf("Stepped generations should have a step time, got %d", rec.StepNanos)
+	}
+}
+
+func TestRecorder_Format(t *testing.T) {
+	for path, want := range map[string]string{
+		"stats.csv":    FormatCSV,
+		"stats.NDJSON": FormatNDJSON,
+		"run.jsonl":    FormatNDJSON,
+		"-":            FormatCSV,
+	} {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if err := NewRecorder().Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("Unknown formats should be rejected")
+	}
+}
@@ -0,0 +1,383 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golife/pkg/core"
+)
+
+// Event is published to subscribers after every step
+type Event struct {
+	Generation int           // Generations stepped since the start or the last Reset
+	Stats      Statistics    // Snapshot of the statistics after the step
+	Universe   core.Universe // The universe; only read it from within the callback
+}
+
+// StepFunc advances a universe and returns the number of generations it advanced
+type StepFunc func(u core.Universe) int
+
+// Simulation drives any core.Universe: it steps it on demand or at a target
+// rate, can be paused and resumed, keeps Statistics and publishes an Event
+// to its subscribers after every step. Front ends share it instead of
+// hand-rolling their own step loops.
+//
+// All methods are safe for concurrent use. Subscribers run on the stepping
+// goroutine between generations; they may call Pause, Resume, SetRate and
+// Stats, but not Step or Do.
+type Simulation struct {
+	stepMu   sync.Mutex // Held while stepping, publishing and inside Do
+	universe core.Universe
+	stepFn   StepFunc
+	history  *History // nil unless EnableHistory was called; set with both locks held
+
+	mu          sync.Mutex // Guards the fields below
+	stats       *Statistics
+	generation  int
+	rate        float64 // Target generations per second; 0 runs as fast as possible
+	paused      bool
+	subscribers []subscriber
+	nextID      int
+	wake        chan struct{} // Interrupts Run when pause or rate change
+}
+
+// subscriber is a registered event callback
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// NewSimulation creates a simulation of u. It steps with StepParallel when
+// the universe has it, and Step otherwise.
+func NewSimulation(u core.Universe) *Simulation {
+	stats := NewStatistics(u.CountLiving())
+	stats.updateCells(u)
+	return &Simulation{
+		universe: u,
+		stepFn:   defaultStep,
+		stats:    stats,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// defaultStep advances one generation, in parallel when the universe supports it
+func defaultStep(u core.Universe) int {
+	if p, ok := u.(interface{ StepParallel() }); ok {
+		p.StepParallel()
+	} else {
+		u.Step()
+	}
+	return 1
+}
+
+// SetStepFunc replaces how the universe is advanced, e.g. to step a HashLife
+// universe 2^k generations at a time
+func (s *Simulation) SetStepFunc(fn StepFunc) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	s.stepFn = fn
+}
+
+// EnableHistory records every generation in a history using at most budget
+// bytes (DefaultHistoryBudget when 0), so StepBack and Seek can return to it.
+// History records the cells within Size, so universes whose cells reach
+// beyond it, such as HashLife and the sparse universes, are refused.
+func (s *Simulation) EnableHistory(budget int) error {
+	if unbounded(s.universe) {
+		return fmt.Errorf("history needs a universe bounded by its size, not %T", s.universe)
+	}
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	h := NewHistory(budget)
+	h.Record(s.Generation(), s.universe)
+
+	s.mu.Lock()
+	s.history = h
+	s.mu.Unlock()
+	return nil
+}
+
+// History returns the recorded generations, or nil when history is disabled
+func (s *Simulation) History() *History {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.history
+}
+
+// Universe returns the simulated universe. Mutate it only inside Do.
+func (s *Simulation) Universe() core.Universe {
+	return s.universe
+}
+
+// Generation returns the number of generations stepped
+func (s *Simulation) Generation() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// Stats returns a snapshot of the statistics
+func (s *Simulation) Stats() Statistics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.stats
+}
+
+// SetSeed records the seed of the random initial state in the statistics
+func (s *Simulation) SetSeed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Seed = seed
+}
+
+// SetRate sets the target number of generations per second for Run.
+// 0 runs as fast as possible.
+func (s *Simulation) SetRate(generationsPerSecond float64) {
+	s.mu.Lock()
+	s.rate = max(generationsPerSecond, 0)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Rate returns the target number of generations per second
+func (s *Simulation) Rate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rate
+}
+
+// Pause stops Run from stepping until Resume is called
+func (s *Simulation) Pause() {
+	s.mu.Lock()
+	s.paused = true
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Resume lets Run step again
+func (s *Simulation) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	s.mu.Unlock()
+	s.notify()
+}
+
+// TogglePause pauses a running simulation or resumes a paused one
+func (s *Simulation) TogglePause() {
+	s.mu.Lock()
+	s.paused = !s.paused
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Paused reports whether the simulation is paused
+func (s *Simulation) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// notify wakes Run so it picks up a pause or rate change
+func (s *Simulation) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default: // A wake-up is already pending
+	}
+}
+
+// Subscribe registers fn to be called after every step, in subscription
+// order. It returns a function that unregisters it.
+func (s *Simulation) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subscribers = append(s.subscribers, subscriber{id: id, fn: fn})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, sub := range s.subscribers {
+			if sub.id == id {
+				s.subscribers = append(s.subscribers[:i:i], s.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// SubscribeChan returns a channel receiving an Event after every step. Events
+// are dropped rather than stalling the simulation when the buffer is full,
+// and their Universe must not be read, since stepping goes on concurrently.
+// The returned function unregisters the channel and closes it.
+func (s *Simulation) SubscribeChan(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	unsubscribe := s.Subscribe(func(ev Event) {
+		ev.Universe = nil
+		select {
+		case ch <- ev:
+		default:
+		}
+	})
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			unsubscribe()
+			s.stepMu.Lock() // Wait for a publish in progress before closing
+			close(ch)
+			s.stepMu.Unlock()
+		})
+	}
+}
+
+// Step advances n generations, publishing an event after each one
+func (s *Simulation) Step(n int) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	for i := 0; i < n; i++ {
+		s.stepOnce()
+	}
+}
+
+// stepOnce advances the universe, records it and publishes the event;
+// stepMu must be held
+func (s *Simulation) stepOnce() {
+	start := time.Now()
+	advanced := s.stepFn(s.universe)
+	elapsed := time.Since(start)
+
+	generation := s.Generation() + advanced
+	if s.history != nil {
+		s.history.Record(generation, s.universe)
+	}
+	s.publish(generation, true, elapsed)
+}
+
+// publish updates the statistics for the universe at generation and calls
+// the subscribers; stepMu must be held. Births, deaths and the step time are
+// only reported when the universe was stepped to get there.
+func (s *Simulation) publish(generation int, stepped bool, stepTime time.Duration) {
+	s.mu.Lock()
+	s.generation = generation
+	s.stats.Update(s.universe)
+	s.stats.Generation = generation
+	s.stats.StepDuration = stepTime
+	if !stepped {
+		s.stats.Births, s.stats.Deaths = 0, 0
+	}
+	ev := Event{Generation: generation, Stats: *s.stats, Universe: s.universe}
+	subscribers := s.subscribers
+	s.mu.Unlock()
+
+	for _, sub := range subscribers {
+		sub.fn(ev)
+	}
+}
+
+// StepBack returns to the newest recorded generation before the current one
+// and publishes it like a step
+func (s *Simulation) StepBack() error {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	if s.history == nil {
+		return fmt.Errorf("history is not enabled")
+	}
+	generation, ok := s.history.Before(s.Generation())
+	if !ok {
+		return fmt.Errorf("no earlier generation in the history")
+	}
+	return s.seek(generation)
+}
+
+// Seek returns to a recorded generation and publishes it like a step.
+// Stepping on from there records over the generations after it.
+func (s *Simulation) Seek(generation int) error {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	if s.history == nil {
+		return fmt.Errorf("history is not enabled")
+	}
+	return s.seek(generation)
+}
+
+// seek restores a generation and publishes it; stepMu must be held
+func (s *Simulation) seek(generation int) error {
+	if err := s.history.Restore(generation, s.universe); err != nil {
+		return err
+	}
+	s.publish(generation, false, 0)
+	return nil
+}
+
+// Do runs fn between generations with exclusive access to the universe,
+// e.g. to load a pattern or set cells while Run is stepping
+func (s *Simulation) Do(fn func(u core.Universe)) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	fn(s.universe)
+}
+
+// Reset restarts the generation count and statistics, keeping the seed.
+// Call it from within Do after replacing the contents of the universe.
+func (s *Simulation) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation = 0
+	s.stats.Reset(s.universe.CountLiving())
+	s.stats.updateCells(s.universe)
+	if s.history != nil {
+		s.history.Clear()
+		s.history.Record(0, s.universe)
+	}
+}
+
+// Run steps the simulation at the target rate until ctx is done, waiting
+// while it is paused. It returns ctx.Err().
+func (s *Simulation) Run(ctx context.Context) error {
+	var next time.Time // When the next step is due at the target rate
+	for {
+		s.mu.Lock()
+		paused, rate := s.paused, s.rate
+		s.mu.Unlock()
+
+		if paused {
+			next = time.Time{}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-s.wake:
+				continue
+			}
+		}
+
+		if rate > 0 {
+			interval := time.Duration(float64(time.Second) / rate)
+			now := time.Now()
+			if next.Before(now.Add(-interval)) {
+				next = now // Fell behind: do not burst to catch up
+			}
+			if wait := next.Sub(now); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-s.wake:
+					timer.Stop()
+					next = time.Time{}
+					continue
+				case <-timer.C:
+				}
+			}
+			next = next.Add(interval)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.Step(1)
+	}
+}
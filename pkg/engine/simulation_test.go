@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"context"
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBlinkerSimulation returns a simulation of a blinker on a 10x10 grid
+func newBlinkerSimulation() *Simulation {
+	u := universe.New2D(10, 10, rules.ConwayRule{})
+	for x := 3; x < 6; x++ {
+		u.Set(core.NewCoord2D(x, 5), core.Alive)
+	}
+	return NewSimulation(u)
+}
+
+func TestSimulation_StepPublishesEvents(t *testing.T) {
+	sim := newBlinkerSimulation()
+	var generations []int
+	var order []string
+	sim.Subscribe(func(ev Event) {
+		generations = append(generations, ev.Generation)
+		order = append(order, "first")
+		if ev.Stats.LivingCells != 3 || ev.Universe.CountLiving() != 3 {
+			t.Errorf("Blinker should keep 3 cells, stats say %d", ev.Stats.LivingCells)
+		}
+	})
+	sim.Subscribe(func(ev Event) { order = append(order, "second") })
+
+	sim.Step(3)
+
+	if len(generations) != 3 || generations[2] != 3 || sim.Generation() != 3 {
+		t.Errorf("Expected events for generations 1-3, got %v (Generation() = %d)", generations, sim.Generation())
+	}
+	if sim.Stats().Generation != 3 {
+		t.Errorf("Stats().Generation = %d, want 3", sim.Stats().Generation)
+	}
+	if len(order) != 6 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("Subscribers should run in subscription order, got %v", order)
+	}
+}
+
+func TestSimulation_Unsubscribe(t *testing.T) {
+	sim := newBlinkerSimulation()
+	calls := 0
+	unsubscribe := sim.Subscribe(func(Event) { calls++ })
+
+	sim.Step(1)
+	unsubscribe()
+	sim.Step(1)
+
+	if calls != 1 {
+		t.Errorf("Unsubscribed callback should not run again, got %d calls", calls)
+	}
+}
+
+func TestSimulation_StepFunc(t *testing.T) {
+	sim := newBlinkerSimulation()
+	sim.SetStepFunc(func(u core.Universe) int {
+		u.Step()
+		u.Step()
+		return 2
+	})
+
+	sim.Step(2)
+	if sim.Generation() != 4 {
+		t.Errorf("Generation() = %d, want 4 after two double steps", sim.Generation())
+	}
+}
+
+func TestSimulation_Run3D(t *testing.T) {
+	u := universe.New3D(12, 12, 12, rules.Life3D_B6S567{})
+	universe.RandomFill(u, universe.RandomOptions{Seed: 3, Density: 0.3})
+	sim := NewSimulation(u)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sim.Subscribe(func(ev Event) {
+		if ev.Generation == 5 {
+			cancel()
+		}
+	})
+
+	if err := sim.Run(ctx); err != context.Canceled {
+		t.Errorf("Run should return context.Canceled, got %v", err)
+	}
+	if sim.Generation() != 5 {
+		t.Errorf("Run should stop at generation 5, got %d", sim.Generation())
+	}
+}
+
+func TestSimulation_PauseResume(t *testing.T) {
+	sim := newBlinkerSimulation()
+	sim.SetRate(1000)
+	sim.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sim.Run(ctx) }()
+
+	time.Sleep(30 * time.Millisecond)
+	if sim.Generation() != 0 {
+		t.Fatalf("A paused simulation should not step, got generation %d", sim.Generation())
+	}
+
+	var reached atomic.Bool
+	sim.Subscribe(func(ev Event) {
+		if ev.Generation >= 3 {
+			reached.Store(true)
+		}
+	})
+	sim.Resume()
+	deadline := time.Now().Add(2 * time.Second)
+	for !reached.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if !reached.Load() {
+		t.Error("Resumed simulation should keep stepping")
+	}
+	if sim.Paused() {
+		t.Error("Paused() should be false after Resume")
+	}
+}
+
+func TestSimulation_RateLimitsSteps(t *testing.T) {
+	sim := newBlinkerSimulation()
+	sim.SetRate(50) // One generation every 20ms
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_ = sim.Run(ctx)
+
+	if g := sim.Generation(); g < 3 || g > 15 {
+		t.Errorf("About 10 generations should run in 200ms at 50/s, got %d", g)
+	}
+}
+
+func TestSimulation_SubscribeChanDropsWhenFull(t *testing.T) {
+	sim := newBlinkerSimulation()
+	events, unsubscribe := sim.SubscribeChan(2)
+
+	sim.Step(5)
+	unsubscribe()
+
+	var received []int
+	for ev := range events {
+		received = append(received, ev.Generation)
+		if ev.Universe != nil {
+			t.Error("Channel events should not carry the universe")
+		}
+	}
+	if len(received) != 2 || received[0] != 1 || received[1] != 2 {
+		t.Errorf("Expected the first 2 events to be buffered, got %v", received)
+	}
+}
+
+func TestSimulation_DoAndReset(t *testing.T) {
+	sim := newBlinkerSimulation()
+	sim.SetSeed(99)
+	sim.Step(4)
+
+	sim.Do(func(u core.Universe) {
+		u.Clear()
+		u.Set(core.NewCoord2D(0, 0), core.Alive)
+		sim.Reset()
+	})
+
+	stats := sim.Stats()
+	if sim.Generation() != 0 || stats.Generation != 0 {
+		t.Errorf("Reset should restart the generation count, got %d", sim.Generation())
+	}
+	if stats.LivingCells != 1 || stats.Seed != 99 {
+		t.Errorf("Reset should count the new cells and keep the seed, got %d cells and seed %d", stats.LivingCells, stats.Seed)
+	}
+}
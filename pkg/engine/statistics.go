@@ -3,7 +3,7 @@ package engine
 import (
 	"time"
 
-	"golife/pkg/universe"
+	"golife/pkg/core"
 )
 
 // Statistics holds simulation statistics
@@ -15,6 +15,18 @@ type Statistics struct {
 	StartTime     time.Time
 	LastFrameTime time.Time
 	FPS           float64
+	Seed          int64 // Seed of the random initial state; 0 when the run did not start from one
+	ActiveTiles   int   // Tiles evaluated by the last step; quiescent tiles are skipped
+	TotalTiles    int   // Tiles covering the grid; 0 when the universe does not use tiles
+
+	Layers    []int      // Living cells per layer of a 2.5D universe; nil otherwise
+	BoundsMin core.Coord // Inclusive bounding box of the living cells; zero when there are none
+	BoundsMax core.Coord
+	Density   float64 // Fraction of the cells within the universe's Size that are alive
+	MeanAge   float64 // Mean age of the living cells; 0 when the universe does not track ages
+	MaxAge    int
+
+	StepDuration time.Duration // Time the last step took, as measured by a Simulation
 }
 
 // NewStatistics creates a new Statistics instance
@@ -29,25 +41,70 @@ func NewStatistics(initialPopulation int) *Statistics {
 	}
 }
 
-// Update updates the statistics for the current generation
-func (s *Statistics) Update(u *universe.Universe2D) {
+// Optional universe capabilities the statistics report on
+type (
+	// tiled is implemented by universes that skip quiescent tiles
+	tiled interface {
+		ActiveTiles() int
+		TileCount() int
+	}
+	// stepCounter is implemented by universes that count the cells born and
+	// the cells that died while stepping
+	stepCounter interface {
+		Births() int
+		Deaths() int
+	}
+	// aged2D and aged3D are implemented by universes that track cell ages
+	aged2D interface {
+		GetAge(x, y int) int
+	}
+	aged3D interface {
+		GetAge(x, y, z int) int
+	}
+	// bounded is implemented by universes that find the bounding box of
+	// their living cells without listing them
+	bounded interface {
+		Bounds() (minC, maxC core.Coord, ok bool)
+	}
+	// ageSummarizer is implemented by aged universes that sum up the ages
+	// of their cells directly
+	ageSummarizer interface {
+		AgeStats() (mean float64, maxAge int)
+	}
+	// layered is implemented by 2.5D universes
+	layered interface {
+		CountLivingInLayer(z int) int
+	}
+)
+
+// Update updates the statistics for the current generation. Births and
+// deaths are the ones counted by the universe during its last step; for
+// universes that do not count them they are derived from the change in
+// population, which understates both when cells are born and die at once.
+func (s *Statistics) Update(u core.Universe) {
 	prevLivingCells := s.LivingCells
 	s.Generation++
 	s.LivingCells = u.CountLiving()
+	s.ActiveTiles, s.TotalTiles = 0, 0
+	if t, ok := u.(tiled); ok {
+		s.ActiveTiles = t.ActiveTiles()
+		s.TotalTiles = t.TileCount()
+	}
 
 	// Calculate births and deaths
-	diff := s.LivingCells - prevLivingCells
-	if diff > 0 {
+	if c, ok := u.(stepCounter); ok {
+		s.Births = c.Births()
+		s.Deaths = c.Deaths()
+	} else if diff := s.LivingCells - prevLivingCells; diff > 0 {
 		s.Births = diff
 		s.Deaths = 0
-	} else if diff < 0 {
-		s.Births = 0
-		s.Deaths = -diff
 	} else {
 		s.Births = 0
-		s.Deaths = 0
+		s.Deaths = -diff
 	}
 
+	s.updateCells(u)
+
 	// Calculate FPS
 	now := time.Now()
 	if !s.LastFrameTime.IsZero() {
@@ -59,13 +116,81 @@ func (s *Statistics) Update(u *universe.Universe2D) {
 	s.LastFrameTime = now
 }
 
-// Reset resets statistics to initial state
+// updateCells updates the per-layer counts, bounding box, density and ages
+// from the living cells of the universe
+func (s *Statistics) updateCells(u core.Universe) {
+	// A fresh slice, since snapshots of the statistics share it
+	s.Layers = nil
+	if l, ok := u.(layered); ok && u.Dimension() == core.Dim25D {
+		s.Layers = make([]int, u.Size().Z)
+		for z := range s.Layers {
+			s.Layers[z] = l.CountLivingInLayer(z)
+		}
+	}
+
+	s.Density = 0
+	if cells := cellCount(u); cells > 0 {
+		s.Density = float64(s.LivingCells) / float64(cells)
+	}
+
+	s.BoundsMin, s.BoundsMax, s.MeanAge, s.MaxAge = core.Coord{}, core.Coord{}, 0, 0
+	age2D, has2D := u.(aged2D)
+	age3D, has3D := u.(aged3D)
+	summary, hasSummary := u.(ageSummarizer)
+	if b, ok := u.(bounded); ok && (hasSummary || !has2D && !has3D) {
+		// Universes that find their bounding box and ages from their own
+		// storage (bit-packed words, HashLife's quadtree) are not scanned
+		// cell by cell
+		s.BoundsMin, s.BoundsMax, _ = b.Bounds()
+		if hasSummary {
+			s.MeanAge, s.MaxAge = summary.AgeStats()
+		}
+		return
+	}
+
+	first := true
+	totalAge, aged := 0, 0
+	forEachLiving(u, func(c core.Coord, state core.CellState) {
+		if first {
+			s.BoundsMin, s.BoundsMax, first = c, c, false
+		} else {
+			s.BoundsMin.X, s.BoundsMax.X = min(s.BoundsMin.X, c.X), max(s.BoundsMax.X, c.X)
+			s.BoundsMin.Y, s.BoundsMax.Y = min(s.BoundsMin.Y, c.Y), max(s.BoundsMax.Y, c.Y)
+			s.BoundsMin.Z, s.BoundsMax.Z = min(s.BoundsMin.Z, c.Z), max(s.BoundsMax.Z, c.Z)
+			s.BoundsMin.W, s.BoundsMax.W = min(s.BoundsMin.W, c.W), max(s.BoundsMax.W, c.W)
+		}
+
+		age := 0
+		switch {
+		case has3D:
+			age = age3D.GetAge(c.X, c.Y, c.Z)
+		case has2D:
+			age = age2D.GetAge(c.X, c.Y)
+		}
+		if age > 0 {
+			totalAge += age
+			aged++
+			s.MaxAge = max(s.MaxAge, age)
+		}
+	})
+
+	if aged > 0 {
+		s.MeanAge = float64(totalAge) / float64(aged)
+	}
+}
+
+// Reset resets statistics to initial state. The seed is kept; set it again
+// when the universe is re-randomized.
 func (s *Statistics) Reset(initialPopulation int) {
 	now := time.Now()
 	s.Generation = 0
 	s.LivingCells = initialPopulation
 	s.Births = 0
 	s.Deaths = 0
+	s.Layers = nil
+	s.BoundsMin, s.BoundsMax = core.Coord{}, core.Coord{}
+	s.Density, s.MeanAge, s.MaxAge = 0, 0, 0
+	s.StepDuration = 0
 	s.StartTime = now
 	s.LastFrameTime = now
 	s.FPS = 0
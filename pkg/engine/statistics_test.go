@@ -2,6 +2,7 @@ package engine
 
 import (
 	"golife/pkg/core"
+	"golife/pkg/hashlife"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
 	"testing"
@@ -39,7 +40,7 @@ func TestStatisticsUpdate_PopulationIncrease(t *testing.T) {
 	rule := rules.ConwayRule{}
 	u := universe.New2D(10, 10, rule)
 
-	// Create a blinker pattern that will have births
+	// Create a blinker pattern that will have births and deaths
 	u.Set(core.NewCoord2D(5, 5), 1)
 	u.Set(core.NewCoord2D(6, 5), 1)
 	u.Set(core.NewCoord2D(7, 5), 1)
@@ -56,31 +57,13 @@ func TestStatisticsUpdate_PopulationIncrease(t *testing.T) {
 		t.Errorf("Generation should be 1, got %d", stats.Generation)
 	}
 
-	// Check that births/deaths are tracked correctly
-	newPop := stats.LivingCells
-	diff := newPop - initialPop
-
-	if diff > 0 {
-		if stats.Births != diff {
-			t.Errorf("Births should be %d, got %d", diff, stats.Births)
-		}
-		if stats.Deaths != 0 {
-			t.Errorf("Deaths should be 0 when population increases, got %d", stats.Deaths)
-		}
-	} else if diff < 0 {
-		if stats.Deaths != -diff {
-			t.Errorf("Deaths should be %d, got %d", -diff, stats.Deaths)
-		}
-		if stats.Births != 0 {
-			t.Errorf("Births should be 0 when population decreases, got %d", stats.Births)
-		}
-	} else {
-		if stats.Births != 0 {
-			t.Errorf("Births should be 0 when population unchanged, got %d", stats.Births)
-		}
-		if stats.Deaths != 0 {
-			t.Errorf("Deaths should be 0 when population unchanged, got %d", stats.Deaths)
-		}
+	// A blinker turns: two cells are born and two die, though the
+	// population stays the same
+	if stats.Births != 2 || stats.Deaths != 2 {
+		t.Errorf("Expected 2 births and 2 deaths, got %d and %d", stats.Births, stats.Deaths)
+	}
+	if stats.LivingCells != initialPop+stats.Births-stats.Deaths {
+		t.Errorf("Population %d should be %d + births - deaths", stats.LivingCells, initialPop)
 	}
 
 	// FPS should be calculated
@@ -293,3 +276,210 @@ func TestStatisticsReset_PreservesIndependence(t *testing.T) {
 		t.Errorf("stats2 should still have 20 living cells, got %d", stats2.LivingCells)
 	}
 }
+
+func TestStatisticsReset_KeepsSeed(t *testing.T) {
+	u := universe.New2D(20, 20, rules.ConwayRule{})
+	u.RandomizeWith(universe.RandomOptions{Seed: 1234})
+
+	stats := NewStatistics(u.CountLiving())
+	stats.Seed = 1234
+	u.Step()
+	stats.Update(u)
+	stats.Reset(u.CountLiving())
+
+	if stats.Seed != 1234 {
+		t.Errorf("Reset should keep the seed, got %d", stats.Seed)
+	}
+
+	// Replaying the seed reproduces the initial population
+	replay := universe.New2D(20, 20, rules.ConwayRule{})
+	replay.RandomizeWith(universe.RandomOptions{Seed: stats.Seed})
+	u.RandomizeWith(universe.RandomOptions{Seed: 1234})
+	if replay.CountLiving() != u.CountLiving() {
+		t.Errorf("Replaying seed %d gave %d cells, want %d", stats.Seed, replay.CountLiving(), u.CountLiving())
+	}
+}
+
+func TestStatisticsUpdate_ActiveTiles(t *testing.T) {
+	u := universe.New2D(64, 64, rules.ConwayRule{})
+	for x := 10; x < 13; x++ {
+		u.Set(core.NewCoord2D(x, 10), core.Alive) // Blinker
+	}
+
+	stats := NewStatistics(u.CountLiving())
+	u.Step()
+	u.Step()
+	stats.Update(u)
+
+	if stats.TotalTiles != 16 {
+		t.Errorf("TotalTiles should be 16 for a 64x64 grid, got %d", stats.TotalTiles)
+	}
+	if stats.ActiveTiles == 0 || stats.ActiveTiles >= stats.TotalTiles {
+		t.Errorf("A blinker should keep a few tiles active, got %d of %d", stats.ActiveTiles, stats.TotalTiles)
+	}
+}
+
+func TestStatisticsUpdate_BoundsDensityAndAges(t *testing.T) {
+	u := universe.New2D(10, 10, rules.ConwayRule{})
+	for _, c := range [][2]int{{2, 3}, {3, 3}, {2, 4}, {3, 4}} {
+		u.Set(core.NewCoord2D(c[0], c[1]), core.Alive) // Block
+	}
+	u.Set(core.NewCoord2D(7, 7), core.Alive) // Dies in the first step
+
+	stats := NewStatistics(u.CountLiving())
+	u.Step()
+	u.Step()
+	stats.Update(u)
+
+	if stats.BoundsMin != core.NewCoord2D(2, 3) || stats.BoundsMax != core.NewCoord2D(3, 4) {
+		t.Errorf("Bounding box should be (2,3)-(3,4), got %v-%v", stats.BoundsMin, stats.BoundsMax)
+	}
+	if stats.Density != 0.04 {
+		t.Errorf("Density should be 4/100, got %v", stats.Density)
+	}
+	if stats.MeanAge != 2 || stats.MaxAge != 2 {
+		t.Errorf("Block cells should have lived 2 generations, got mean %v and max %d", stats.MeanAge, stats.MaxAge)
+	}
+	if stats.Layers != nil {
+		t.Errorf("A 2D universe has no layers, got %v", stats.Layers)
+	}
+}
+
+func TestStatisticsUpdate_4D(t *testing.T) {
+	u := universe.New4D(5, 5, 4, 2, rules.Life4D_B9S7_10{})
+	u.Set(core.NewCoord4D(1, 2, 0, 0), core.Alive)
+	u.Set(core.NewCoord4D(3, 1, 2, 1), core.Alive)
+
+	stats := NewStatistics(u.CountLiving())
+	stats.Update(u)
+
+	if stats.Density != 2.0/200 {
+		t.Errorf("Density should be 2/200, got %v", stats.Density)
+	}
+	if stats.BoundsMin != core.NewCoord4D(1, 1, 0, 0) || stats.BoundsMax != core.NewCoord4D(3, 2, 2, 1) {
+		t.Errorf("Bounding box should be (1,1,0,0)-(3,2,2,1), got %v-%v", stats.BoundsMin, stats.BoundsMax)
+	}
+}
+
+func TestStatisticsUpdate_Layers(t *testing.T) {
+	u := universe.New25D(10, 10, 3, rules.ConwayRule{})
+	for x := 3; x < 6; x++ {
+		u.Set(core.NewCoord3D(x, 5, 2), core.Alive) // Blinker in the top layer
+	}
+
+	stats := NewStatistics(u.CountLiving())
+	u.Step()
+	stats.Update(u)
+
+	if len(stats.Layers) != 3 || stats.Layers[0] != 0 || stats.Layers[2] != 3 {
+		t.Errorf("Expected layer counts [0 0 3], got %v", stats.Layers)
+	}
+	if stats.Births != 2 || stats.Deaths != 2 {
+		t.Errorf("Expected 2 births and 2 deaths, got %d and %d", stats.Births, stats.Deaths)
+	}
+	if stats.BoundsMin != core.NewCoord3D(4, 4, 2) || stats.BoundsMax != core.NewCoord3D(4, 6, 2) {
+		t.Errorf("Bounding box should be (4,4,2)-(4,6,2), got %v-%v", stats.BoundsMin, stats.BoundsMax)
+	}
+	if stats.MeanAge != 1 || stats.MaxAge != 1 {
+		t.Errorf("The turned blinker should have lived 1 generation, got mean %v and max %d", stats.MeanAge, stats.MaxAge)
+	}
+}
+
+func TestStatisticsUpdate_UniverseWithoutCounts(t *testing.T) {
+	// The sparse universe does not count births and deaths, so they come
+	// from the change in population
+	u, err := universe.NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	for _, c := range [][2]int{{-5, 0}, {-4, 0}, {-3, 0}, {100, 100}} {
+		u.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+	}
+
+	stats := NewStatistics(u.CountLiving())
+	u.Step()
+	stats.Update(u)
+
+	if stats.Births != 0 || stats.Deaths != 1 {
+		t.Errorf("Expected the net change (0 births, 1 death), got %d and %d", stats.Births, stats.Deaths)
+	}
+	if stats.BoundsMin != core.NewCoord2D(-4, -1) || stats.BoundsMax != core.NewCoord2D(-4, 1) {
+		t.Errorf("Bounding box should cover the blinker at (-4,-1)-(-4,1), got %v-%v", stats.BoundsMin, stats.BoundsMax)
+	}
+}
+
+func TestStatisticsUpdate_HashLifeBounds(t *testing.T) {
+	u, err := hashlife.New(16, 16, rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("hashlife.New returned error: %v", err)
+	}
+	for _, c := range [][2]int{{-4, -1}, {-4, 0}, {-4, 1}, {1000, 2000}, {1001, 2000}, {1000, 2001}, {1001, 2001}} {
+		u.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+	}
+
+	stats := NewStatistics(u.CountLiving())
+	u.Step()
+	stats.Update(u)
+
+	if stats.BoundsMin != core.NewCoord2D(-5, 0) || stats.BoundsMax != core.NewCoord2D(1001, 2001) {
+		t.Errorf("Bounding box should cover the blinker and the block, got %v-%v", stats.BoundsMin, stats.BoundsMax)
+	}
+	if stats.MeanAge != 0 || stats.MaxAge != 0 {
+		t.Errorf("HashLife tracks no ages, got mean %v and max %d", stats.MeanAge, stats.MaxAge)
+	}
+}
+
+func BenchmarkStatisticsUpdate_Bit2D_2048(b *testing.B) {
+	u, err := universe.NewBit2D(2048, 2048, rules.ConwayRule{})
+	if err != nil {
+		b.Fatalf("NewBit2D returned error: %v", err)
+	}
+	u.RandomizeWith(universe.RandomOptions{Seed: 1, Density: 0.3})
+	stats := NewStatistics(u.CountLiving())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stats.Update(u)
+	}
+}
+
+func BenchmarkStatisticsUpdate_Universe2D_2048(b *testing.B) {
+	u := universe.New2D(2048, 2048, rules.ConwayRule{})
+	u.RandomizeWith(universe.RandomOptions{Seed: 1, Density: 0.3})
+	stats := NewStatistics(u.CountLiving())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stats.Update(u)
+	}
+}
+
+func TestStatisticsUpdate_StoredBoundsAndAges(t *testing.T) {
+	// Grid and bit-packed universes report bounds and ages from their own
+	// storage rather than through the per-cell scan
+	bit, err := universe.NewBit2D(100, 20, rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewBit2D returned error: %v", err)
+	}
+	for name, u := range map[string]core.Universe{"grid": universe.New2D(100, 20, rules.ConwayRule{}), "bit": bit} {
+		for _, c := range [][2]int{{2, 3}, {3, 3}, {2, 4}, {3, 4}, {70, 10}, {71, 10}, {72, 10}} {
+			u.Set(core.NewCoord2D(c[0], c[1]), core.Alive) // Block and blinker
+		}
+		for i := 0; i < 3; i++ {
+			u.Step()
+		}
+		u.Set(core.NewCoord2D(98, 18), core.Alive) // No age until it survives a step
+
+		stats := NewStatistics(u.CountLiving())
+		stats.Update(u)
+
+		if stats.BoundsMin != core.NewCoord2D(2, 3) || stats.BoundsMax != core.NewCoord2D(98, 18) {
+			t.Errorf("%s: bounding box should be (2,3)-(98,18), got %v-%v", name, stats.BoundsMin, stats.BoundsMax)
+		}
+		// The block and the blinker's centre have lived 3 generations, the
+		// blinker's ends 1
+		if stats.MeanAge != 17.0/7 || stats.MaxAge != 3 {
+			t.Errorf("%s: ages should be mean 17/7 and max 3, got mean %v and max %d", name, stats.MeanAge, stats.MaxAge)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package hashlife implements Gosper's HashLife algorithm for 2D two-state rules.
+//
+// The plane is stored as a quadtree whose nodes are hash-consed, and the
+// future of every node is memoized, so repetitive patterns such as guns and
+// breeders can be advanced by 2^k generations in time roughly proportional
+// to k rather than to 2^k.
+package hashlife
+
+import (
+	"fmt"
+	"math"
+
+	"golife/pkg/core"
+)
+
+// DefaultMaxNodes is the node count above which memoized results are discarded
+const DefaultMaxNodes = 1 << 20
+
+// Universe is an unbounded 2D universe stepped with HashLife. It implements
+// core.Universe; Size reports the viewport given to New, which front ends
+// display and RandomFill fills, but cells can be set and read anywhere.
+//
+// A Universe and its clones share a node store and are not safe for
+// concurrent use.
+type Universe struct {
+	store         *store
+	root          *node // Covers [-2^(level-1), 2^(level-1)) on both axes
+	rule          core.Rule
+	width, height int
+	generation    uint64
+	maxNodes      int
+}
+
+var _ core.Universe = (*Universe)(nil)
+
+// New creates an empty HashLife universe with a width x height viewport.
+// It returns an error if the rule is not a two-state rule on the range-1
+// Moore neighborhood.
+func New(width, height int, rule core.Rule) (*Universe, error) {
+	lut, err := newBaseLUT(rule)
+	if err != nil {
+		return nil, err
+	}
+	s := newStore(lut)
+	return &Universe{
+		store:    s,
+		root:     s.emptyNode(3),
+		rule:     rule,
+		width:    width,
+		height:   height,
+		maxNodes: DefaultMaxNodes,
+	}, nil
+}
+
+// Dimension returns the dimensionality (2D)
+func (u *Universe) Dimension() core.Dimension {
+	return core.Dim2D
+}
+
+// Rule returns the rule driving this universe
+func (u *Universe) Rule() core.Rule {
+	return u.rule
+}
+
+// Generation returns the number of generations stepped since creation
+func (u *Universe) Generation() uint64 {
+	return u.generation
+}
+
+// SetMaxNodes sets the node count above which the store is garbage collected
+func (u *Universe) SetMaxNodes(n int) {
+	u.maxNodes = n
+}
+
+// NodeCount returns the number of distinct nodes in the store
+func (u *Universe) NodeCount() int {
+	return len(u.store.table)
+}
+
+// maxLevel is the highest root level, so that coordinates within the root
+// fit an int
+const maxLevel = 62
+
+// MaxStepExp is the largest k StepPow2 accepts: a step of 2^k generations
+// needs a root of level k+3
+const MaxStepExp = maxLevel - 3
+
+// half returns the distance from the origin to the root's edges
+func (u *Universe) half() int {
+	return 1 << (u.root.level - 1)
+}
+
+// Get returns the state of a cell at the given coordinate
+func (u *Universe) Get(coord core.Coord) core.CellState {
+	h := u.half()
+	if coord.X < -h || coord.X >= h || coord.Y < -h || coord.Y >= h {
+		return core.Dead
+	}
+	if cellAt(u.root, coord.X+h, coord.Y+h) {
+		return core.Alive
+	}
+	return core.Dead
+}
+
+// Set sets the state of a cell at the given coordinate. Any state other
+// than core.Dead is stored as core.Alive.
+func (u *Universe) Set(coord core.Coord, state core.CellState) {
+	for {
+		h := u.half()
+		if coord.X >= -h && coord.X < h && coord.Y >= -h && coord.Y < h {
+			break
+		}
+		if state == core.Dead {
+			return // Already dead beyond the root
+		}
+		u.root = u.store.expand(u.root)
+	}
+	h := u.half()
+	u.root = u.store.setCell(u.root, coord.X+h, coord.Y+h, state != core.Dead)
+}
+
+// Step advances one generation. A pattern that has spread to the edge of
+// the plane is left as it is; StepPow2 reports that as an error.
+func (u *Universe) Step() {
+	_ = u.StepPow2(0)
+}
+
+// StepPow2 advances 2^k generations in a single HashLife step
+// (0 <= k <= MaxStepExp). It returns an error, leaving the cells as they
+// were, when the step would grow the root beyond maxLevel.
+func (u *Universe) StepPow2(k int) error {
+	if k < 0 || k > MaxStepExp {
+		return fmt.Errorf("step of 2^%d generations is outside 2^0-2^%d", k, MaxStepExp)
+	}
+	// Grow the root until the pattern sits in its inner quarter and the step
+	// fits: nothing can then travel past the center half the step returns
+	for u.root.level < k+3 || !u.centered() {
+		if u.root.level >= maxLevel {
+			return fmt.Errorf("pattern has spread too far to step 2^%d generations", k)
+		}
+		u.root = u.store.expand(u.root)
+	}
+	u.root = u.store.nextGen(u.root, k)
+	u.generation += 1 << k
+
+	if len(u.store.table) > u.maxNodes {
+		u.store.collect(u.root)
+	}
+	return nil
+}
+
+// StepN advances n generations, one power-of-two step per set bit of n,
+// stopping at the first step StepPow2 refuses
+func (u *Universe) StepN(n uint64) error {
+	for k := 0; n != 0; k++ {
+		if n&1 != 0 {
+			if err := u.StepPow2(k); err != nil {
+				return err
+			}
+		}
+		n >>= 1
+	}
+	return nil
+}
+
+// centered reports whether every live cell lies in the root's inner quarter
+func (u *Universe) centered() bool {
+	if u.root.level < 3 {
+		return false
+	}
+	s := u.store
+	return s.center(s.center(u.root)).population == u.root.population
+}
+
+// Size returns the viewport size
+func (u *Universe) Size() core.Coord {
+	return core.NewCoord2D(u.width, u.height)
+}
+
+// Clone creates a copy of the universe. Nodes are immutable, so the copy
+// shares them with the original.
+func (u *Universe) Clone() core.Universe {
+	clone := *u
+	return &clone
+}
+
+// Clear resets all cells to dead state
+func (u *Universe) Clear() {
+	u.root = u.store.emptyNode(3)
+}
+
+// CountLiving returns the number of living cells on the whole plane
+func (u *Universe) CountLiving() int {
+	return u.root.population
+}
+
+// ForEachLiving calls fn for every living cell in the box [minC, maxC)
+func (u *Universe) ForEachLiving(minC, maxC core.Coord, fn func(coord core.Coord)) {
+	h := u.half()
+	forEachLiving(u.root, -h, -h, minC.X, minC.Y, maxC.X, maxC.Y, func(x, y int) {
+		fn(core.NewCoord2D(x, y))
+	})
+}
+
+// Bounds returns the inclusive bounding box of living cells.
+// ok is false if the universe is empty.
+func (u *Universe) Bounds() (minC, maxC core.Coord, ok bool) {
+	if u.root.population == 0 {
+		return core.Coord{}, core.Coord{}, false
+	}
+	h := u.half()
+	minX := extent(u.root, -h, false, false)
+	maxX := -extent(u.root, h-1, false, true)
+	minY := extent(u.root, -h, true, false)
+	maxY := -extent(u.root, h-1, true, true)
+	return core.NewCoord2D(minX, minY), core.NewCoord2D(maxX, maxY), true
+}
+
+// extent returns the smallest coordinate of a live cell along one axis, with
+// pos the coordinate of the node's first row or column. When far is set it
+// searches from the opposite side and returns the negated largest coordinate.
+func extent(n *node, pos int, vertical, far bool) int {
+	if n.population == 0 {
+		return math.MaxInt
+	}
+	if n.level == 0 {
+		if far {
+			return -pos
+		}
+		return pos
+	}
+
+	half := 1 << (n.level - 1)
+	nearA, nearB, farA, farB := n.nw, n.sw, n.ne, n.se // Searching along X
+	if vertical {
+		nearA, nearB, farA, farB = n.nw, n.ne, n.sw, n.se
+	}
+	step := half
+	if far {
+		nearA, nearB, farA, farB = farA, farB, nearA, nearB
+		step = -half
+	}
+
+	best := min(extent(nearA, pos, vertical, far), extent(nearB, pos, vertical, far))
+	if best != math.MaxInt {
+		return best
+	}
+	return min(extent(farA, pos+step, vertical, far), extent(farB, pos+step, vertical, far))
+}
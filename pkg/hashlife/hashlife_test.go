@@ -0,0 +1,292 @@
+package hashlife
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// setGlider places a south-east moving glider with its bounding box at (x, y)
+func setGlider(u core.Universe, x, y int) {
+	for _, c := range [][2]int{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}} {
+		u.Set(core.NewCoord2D(x+c[0], y+c[1]), core.Alive)
+	}
+}
+
+// compareWithGrid steps a random soup on both engines and compares every cell
+func compareWithGrid(t *testing.T, rule core.Rule, gens int) {
+	t.Helper()
+	const size = 200
+	grid := universe.New2D(size, size, rule)
+	hl, err := New(size, size, rule)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for y := 92; y < 108; y++ {
+		for x := 92; x < 108; x++ {
+			if rng.Intn(2) == 0 {
+				grid.Set(core.NewCoord2D(x, y), core.Alive)
+				hl.Set(core.NewCoord2D(x, y), core.Alive)
+			}
+		}
+	}
+
+	for gen := 1; gen <= gens; gen++ {
+		grid.Step()
+		hl.Step()
+		if grid.CountLiving() != hl.CountLiving() {
+			t.Fatalf("Generation %d: grid has %d cells, hashlife %d", gen, grid.CountLiving(), hl.CountLiving())
+		}
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := core.NewCoord2D(x, y)
+			if grid.Get(c) != hl.Get(c) {
+				t.Fatalf("Mismatch at (%d,%d) after %d generations", x, y, gens)
+			}
+		}
+	}
+}
+
+func TestHashLife_MatchesUniverse2D(t *testing.T) {
+	compareWithGrid(t, rules.ConwayRule{}, 60)
+}
+
+func TestHashLife_OtherRules(t *testing.T) {
+	for _, rs := range []string{"B36/S23", "B3678/S34678", "B3/S2-i34q"} {
+		t.Run(rs, func(t *testing.T) {
+			rule, err := rules.Parse(rs)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", rs, err)
+			}
+			compareWithGrid(t, rule, 40)
+		})
+	}
+}
+
+func TestHashLife_StepNMatchesSingleSteps(t *testing.T) {
+	single, _ := New(64, 64, rules.ConwayRule{})
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 120; i++ {
+		single.Set(core.NewCoord2D(rng.Intn(20), rng.Intn(20)), core.Alive)
+	}
+	fast := single.Clone().(*Universe)
+
+	for i := 0; i < 100; i++ {
+		single.Step()
+	}
+	fast.StepN(100)
+
+	if fast.Generation() != 100 || single.Generation() != 100 {
+		t.Fatalf("Generations = %d and %d, want 100", fast.Generation(), single.Generation())
+	}
+	if fast.CountLiving() != single.CountLiving() {
+		t.Fatalf("StepN(100) gave %d cells, single steps %d", fast.CountLiving(), single.CountLiving())
+	}
+	minC, maxC, _ := single.Bounds()
+	for y := minC.Y; y <= maxC.Y; y++ {
+		for x := minC.X; x <= maxC.X; x++ {
+			c := core.NewCoord2D(x, y)
+			if fast.Get(c) != single.Get(c) {
+				t.Fatalf("Mismatch at (%d,%d)", x, y)
+			}
+		}
+	}
+}
+
+func TestHashLife_GliderFastForward(t *testing.T) {
+	u, _ := New(10, 10, rules.ConwayRule{})
+	setGlider(u, 0, 0)
+
+	// A glider moves one cell diagonally every 4 generations
+	if err := u.StepPow2(20); err != nil {
+		t.Fatalf("StepPow2 returned error: %v", err)
+	}
+
+	if u.Generation() != 1<<20 {
+		t.Errorf("Generation() = %d, want %d", u.Generation(), 1<<20)
+	}
+	if u.CountLiving() != 5 {
+		t.Fatalf("Glider should keep 5 cells, got %d", u.CountLiving())
+	}
+	minC, maxC, ok := u.Bounds()
+	shift := 1 << 18
+	if !ok || minC != core.NewCoord2D(shift, shift) || maxC != core.NewCoord2D(shift+2, shift+2) {
+		t.Errorf("Bounds = %v..%v, want the glider at (%d,%d)", minC, maxC, shift, shift)
+	}
+}
+
+func TestHashLife_GliderGunPopulation(t *testing.T) {
+	u, _ := New(40, 20, rules.ConwayRule{})
+	gun := []string{
+		"........................O...........",
+		"......................O.O...........",
+		"............OO......OO............OO",
+		"...........O...O....OO............OO",
+		"OO........O.....O...OO..............",
+		"OO........O...O.OO....O.O...........",
+		"..........O.....O.......O...........",
+		"...........O...O....................",
+		"............OO......................",
+	}
+	for y, row := range gun {
+		for x, c := range row {
+			if c == 'O' {
+				u.Set(core.NewCoord2D(x, y), core.Alive)
+			}
+		}
+	}
+
+	// The gun emits one glider every 30 generations; after 30*2^k generations
+	// the gun (36 cells in phase 0) has released 2^k gliders of 5 cells each
+	u.StepN(30 * 1024)
+	if got, want := u.CountLiving(), 36+5*1024; got != want {
+		t.Errorf("Population after %d generations = %d, want %d", 30*1024, got, want)
+	}
+	if u.NodeCount() > 200000 {
+		t.Errorf("Hash-consing should keep the gun's history small, got %d nodes", u.NodeCount())
+	}
+}
+
+func TestHashLife_GarbageCollection(t *testing.T) {
+	u, _ := New(64, 64, rules.ConwayRule{})
+	u.SetMaxNodes(500)
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		u.Set(core.NewCoord2D(rng.Intn(30), rng.Intn(30)), core.Alive)
+	}
+	ref := u.Clone().(*Universe)
+	ref.SetMaxNodes(DefaultMaxNodes)
+
+	for i := 0; i < 50; i++ {
+		u.Step()
+	}
+	ref.StepN(50)
+
+	if u.CountLiving() != ref.CountLiving() {
+		t.Errorf("Collecting nodes changed the result: %d vs %d cells", u.CountLiving(), ref.CountLiving())
+	}
+}
+
+func TestHashLife_SetGetAnywhere(t *testing.T) {
+	u, _ := New(10, 10, rules.ConwayRule{})
+	far := core.NewCoord2D(-100000, 250000)
+	u.Set(far, core.Alive)
+	u.Set(core.NewCoord2D(3, 3), 7) // Any live state is stored as Alive
+
+	if u.Get(far) != core.Alive || u.Get(core.NewCoord2D(3, 3)) != core.Alive {
+		t.Error("Cells should be readable wherever they were set")
+	}
+	if u.CountLiving() != 2 {
+		t.Errorf("CountLiving() = %d, want 2", u.CountLiving())
+	}
+
+	var seen []core.Coord
+	u.ForEachLiving(core.NewCoord2D(0, 0), u.Size(), func(c core.Coord) {
+		seen = append(seen, c)
+	})
+	if len(seen) != 1 || seen[0] != core.NewCoord2D(3, 3) {
+		t.Errorf("ForEachLiving in the viewport = %v, want only (3,3)", seen)
+	}
+
+	u.Set(far, core.Dead)
+	u.Clear()
+	if u.CountLiving() != 0 {
+		t.Errorf("Clear should remove every cell, got %d", u.CountLiving())
+	}
+	if _, _, ok := u.Bounds(); ok {
+		t.Error("Bounds of an empty universe should not be ok")
+	}
+}
+
+func TestHashLife_CloneIsIndependent(t *testing.T) {
+	u, _ := New(10, 10, rules.ConwayRule{})
+	setGlider(u, 2, 2)
+	clone := u.Clone()
+
+	u.StepN(8)
+	clone.Set(core.NewCoord2D(-5, -5), core.Alive)
+
+	if clone.Get(core.NewCoord2D(3, 2)) != core.Alive {
+		t.Error("Stepping the original should not move the clone's glider")
+	}
+	if u.Get(core.NewCoord2D(-5, -5)) != core.Dead {
+		t.Error("Setting a cell in the clone should not affect the original")
+	}
+}
+
+func TestHashLife_RandomFill(t *testing.T) {
+	u, _ := New(32, 16, rules.ConwayRule{})
+	placed := universe.RandomFill(u, universe.RandomOptions{Seed: 3})
+	if placed == 0 || placed != u.CountLiving() {
+		t.Errorf("RandomFill placed %d cells, universe has %d", placed, u.CountLiving())
+	}
+	minC, maxC, _ := u.Bounds()
+	if minC.X < 0 || minC.Y < 0 || maxC.X >= 32 || maxC.Y >= 16 {
+		t.Errorf("Random cells should stay in the viewport, bounds %v..%v", minC, maxC)
+	}
+}
+
+func TestNew_UnsupportedRules(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr string
+	}{
+		{"brians-brain", "multi-state"},
+		{"bosco", "range-1 Moore"},
+		{"wireworld", "rule tables"},
+		{"B03/S23", "B0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := rules.Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.rule, err)
+			}
+			if _, err := New(10, 10, rule); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New with %s: error = %v, want it to contain %q", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+
+	weighted := rules.NewDistanceDecayRule(rules.ConwayRule{}, core.MooreNeighborhood(1), 1)
+	if _, err := New(10, 10, weighted); err == nil || !strings.Contains(err.Error(), "weighted") {
+		t.Errorf("New with a weighted rule: error = %v", err)
+	}
+}
+
+func TestStepPow2_Limits(t *testing.T) {
+	u, _ := New(10, 10, rules.ConwayRule{})
+	setGlider(u, 0, 0)
+	for _, k := range []int{-1, MaxStepExp + 1, 62} {
+		if err := u.StepPow2(k); err == nil {
+			t.Errorf("StepPow2(%d) should return an error", k)
+		}
+	}
+	if u.Generation() != 0 || u.CountLiving() != 5 {
+		t.Errorf("Refused steps should leave the universe alone, got %d cells at generation %d", u.CountLiving(), u.Generation())
+	}
+
+	// A cell near the edge of the plane leaves no room for the root to grow
+	u.Set(core.NewCoord2D(1<<60, 0), core.Alive)
+	if err := u.StepPow2(0); err == nil {
+		t.Error("StepPow2 should refuse a pattern spread to the edge of the plane")
+	}
+	if err := u.StepPow2(MaxStepExp); err == nil {
+		t.Error("StepPow2 should refuse a step the root cannot fit")
+	}
+}
+
+func BenchmarkHashLife_GliderGun(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		u, _ := New(40, 20, rules.ConwayRule{})
+		setGlider(u, 0, 0)
+		u.StepPow2(30)
+	}
+}
@@ -0,0 +1,242 @@
+package hashlife
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+	"golife/pkg/rules"
+)
+
+// macrocellLeafLevel is the level of the 8x8 bitmaps macrocell files start from
+const macrocellLeafLevel = 3
+
+// ReadMacrocell reads a pattern in Golly's macrocell (.mc) format straight
+// into the quadtree of a new universe with a width x height viewport, so
+// patterns far too large for a dense grid can be loaded. The file lists
+// the distinct nodes of the tree once each: 8x8 leaves as rows of '.' and
+// '*' separated by '$', and inner nodes as "level nw ne sw se" with the
+// 1-based line numbers of their children (0 for an empty child). The last
+// node is the root, centered on the origin.
+//
+// The rule comes from the "#R" line (Conway's when there is none) and the
+// generation count from "#G". The "#C" comment lines are returned.
+func ReadMacrocell(r io.Reader, width, height int) (*Universe, []string, error) {
+	var comments []string
+	ruleString := "B3/S23"
+	var generation uint64
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "[M2]") {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read macrocell: %w", err)
+		}
+		return nil, nil, fmt.Errorf("not a macrocell file: missing [M2] header")
+	}
+
+	// Header lines come first, but the nodes need the rule's store, so they
+	// are kept until the rule is known
+	var lines []string
+	lineNo := 1
+	firstNodeLine := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			tag, text := line[1:min(2, len(line))], strings.TrimSpace(line[min(2, len(line)):])
+			switch tag {
+			case "R":
+				ruleString = text
+			case "G":
+				g, err := strconv.ParseUint(text, 10, 64)
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid macrocell: line %d: bad generation %q", lineNo, text)
+				}
+				generation = g
+			case "C", "N", "D":
+				comments = append(comments, text)
+			}
+		default:
+			if firstNodeLine == 0 {
+				firstNodeLine = lineNo
+			}
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read macrocell: %w", err)
+	}
+
+	rule, err := rules.Parse(ruleString)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid macrocell rule: %w", err)
+	}
+	u, err := New(width, height, rule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := u.store
+	nodes := []*node{nil} // nodes[i] is the node on the i-th node line
+	for i, line := range lines {
+		n, err := s.parseMacrocellNode(line, nodes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid macrocell: line %d: %w", firstNodeLine+i, err)
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) > 1 {
+		u.root = nodes[len(nodes)-1]
+	}
+	u.generation = generation
+	return u, comments, nil
+}
+
+// parseMacrocellNode builds the node of a leaf or inner node line
+func (s *store) parseMacrocellNode(line string, nodes []*node) (*node, error) {
+	if c := line[0]; c == '.' || c == '*' || c == '$' {
+		n := s.emptyNode(macrocellLeafLevel)
+		x, y := 0, 0
+		for i := 0; i < len(line); i++ {
+			switch line[i] {
+			case '.':
+				x++
+			case '*':
+				if x >= 8 || y >= 8 {
+					return nil, fmt.Errorf("leaf cell (%d,%d) is outside the 8x8 square", x, y)
+				}
+				n = s.setCell(n, x, y, true)
+				x++
+			case '$':
+				x, y = 0, y+1
+			default:
+				return nil, fmt.Errorf("unexpected character %q in a leaf", line[i])
+			}
+		}
+		return n, nil
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("want \"level nw ne sw se\", got %q", line)
+	}
+	var v [5]int
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("bad number %q", f)
+		}
+		v[i] = n
+	}
+	level := v[0]
+	if level <= macrocellLeafLevel {
+		return nil, fmt.Errorf("level %d node: multi-state and small-leaf macrocells are not supported", level)
+	}
+	if level > maxLevel {
+		return nil, fmt.Errorf("level %d node is too large", level)
+	}
+
+	var children [4]*node
+	for i, index := range v[1:] {
+		switch {
+		case index == 0:
+			children[i] = s.emptyNode(level - 1)
+		case index >= len(nodes):
+			return nil, fmt.Errorf("child %d is not defined yet", index)
+		case nodes[index].level != level-1:
+			return nil, fmt.Errorf("child %d has level %d, want %d", index, nodes[index].level, level-1)
+		default:
+			children[i] = nodes[index]
+		}
+	}
+	return s.join(children[0], children[1], children[2], children[3]), nil
+}
+
+// WriteMacrocell writes the universe in the macrocell format, with its rule,
+// generation count and the given comment lines. Each distinct node is
+// written once, so the output stays as small as the quadtree rather than
+// growing with the area the pattern covers.
+func (u *Universe) WriteMacrocell(w io.Writer, comments []string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[M2] (golife)\n")
+	fmt.Fprintf(bw, "#R %s\n", ruleString(u.rule))
+	if u.generation != 0 {
+		fmt.Fprintf(bw, "#G %d\n", u.generation)
+	}
+	for _, c := range comments {
+		fmt.Fprintf(bw, "#C %s\n", c)
+	}
+
+	root := u.root
+	for root.level < macrocellLeafLevel {
+		root = u.store.expand(root)
+	}
+	if root.population > 0 {
+		mw := macrocellWriter{w: bw, index: make(map[*node]int)}
+		mw.write(root)
+	}
+	return bw.Flush()
+}
+
+// ruleString returns a rulestring for the rule that rules.Parse accepts
+func ruleString(rule core.Rule) string {
+	if _, ok := rule.(rules.ConwayRule); ok {
+		return "B3/S23"
+	}
+	return rule.Name()
+}
+
+// macrocellWriter numbers nodes in the order they are written
+type macrocellWriter struct {
+	w     *bufio.Writer
+	index map[*node]int
+}
+
+// write writes the non-empty node n after its children and returns its line
+// number, or 0 for an empty node
+func (mw *macrocellWriter) write(n *node) int {
+	if n.population == 0 {
+		return 0
+	}
+	if i, ok := mw.index[n]; ok {
+		return i
+	}
+
+	if n.level == macrocellLeafLevel {
+		var line []byte
+		rows := 0 // Row ends not yet written, so trailing empty rows are dropped
+		for y := 0; y < 8; y++ {
+			end := 8
+			for end > 0 && !cellAt(n, end-1, y) {
+				end--
+			}
+			if end > 0 {
+				for ; rows > 0; rows-- {
+					line = append(line, '$')
+				}
+			}
+			for x := 0; x < end; x++ {
+				if cellAt(n, x, y) {
+					line = append(line, '*')
+				} else {
+					line = append(line, '.')
+				}
+			}
+			rows++
+		}
+		mw.w.Write(append(line, '$', '\n'))
+	} else {
+		nw, ne, sw, se := mw.write(n.nw), mw.write(n.ne), mw.write(n.sw), mw.write(n.se)
+		fmt.Fprintf(mw.w, "%d %d %d %d %d\n", n.level, nw, ne, sw, se)
+	}
+
+	i := len(mw.index) + 1
+	mw.index[n] = i
+	return i
+}
@@ -0,0 +1,146 @@
+package hashlife
+
+import (
+	"bytes"
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"strings"
+	"testing"
+)
+
+// sameLiving reports whether two universes have the same living cells
+func sameLiving(a, b *Universe) bool {
+	if a.CountLiving() != b.CountLiving() {
+		return false
+	}
+	minC, maxC, ok := a.Bounds()
+	if !ok {
+		return true
+	}
+	same := true
+	a.ForEachLiving(minC, core.NewCoord2D(maxC.X+1, maxC.Y+1), func(c core.Coord) {
+		same = same && b.Get(c) == core.Alive
+	})
+	return same
+}
+
+func TestReadMacrocell(t *testing.T) {
+	input := `[M2] (golly 2.0)
+#R B36/S23
+#G 12
+#C A glider in the south-east quadrant
+.*$..*$***$
+4 0 0 0 1
+`
+	u, comments, err := ReadMacrocell(strings.NewReader(input), 10, 10)
+	if err != nil {
+		t.Fatalf("ReadMacrocell returned error: %v", err)
+	}
+	if u.Rule().Name() != "B36/S23" || u.Generation() != 12 {
+		t.Errorf("Rule/Generation = %s/%d, want B36/S23/12", u.Rule().Name(), u.Generation())
+	}
+	if len(comments) != 1 || comments[0] != "A glider in the south-east quadrant" {
+		t.Errorf("Comments = %q", comments)
+	}
+
+	want := newGliderUniverse()
+	if !sameLiving(u, want) {
+		minC, maxC, _ := u.Bounds()
+		t.Errorf("Expected a glider at the origin, got %d cells in %v..%v", u.CountLiving(), minC, maxC)
+	}
+}
+
+// newGliderUniverse returns a Conway universe with a glider at the origin
+func newGliderUniverse() *Universe {
+	u, _ := New(10, 10, rules.ConwayRule{})
+	setGlider(u, 0, 0)
+	return u
+}
+
+func TestMacrocell_RoundTrip(t *testing.T) {
+	u, _ := New(40, 20, rules.ConwayRule{})
+	setGlider(u, -30, 7)
+	setGlider(u, 100, -2000)
+	for x := 0; x < 20; x++ {
+		u.Set(core.NewCoord2D(x, 0), core.Alive)
+	}
+	u.StepN(100)
+
+	var buf bytes.Buffer
+	if err := u.WriteMacrocell(&buf, []string{"Gliders and a line"}); err != nil {
+		t.Fatalf("WriteMacrocell returned error: %v", err)
+	}
+	got, comments, err := ReadMacrocell(&buf, 40, 20)
+	if err != nil {
+		t.Fatalf("ReadMacrocell returned error: %v", err)
+	}
+	if !sameLiving(got, u) || got.Generation() != 100 || len(comments) != 1 {
+		t.Fatalf("Round trip changed the universe: %d cells at generation %d, want %d at 100",
+			got.CountLiving(), got.Generation(), u.CountLiving())
+	}
+
+	// The loaded pattern steps like the original
+	u.StepN(64)
+	got.StepN(64)
+	if !sameLiving(got, u) {
+		t.Error("The loaded universe evolves differently from the original")
+	}
+}
+
+func TestMacrocell_StaysCompact(t *testing.T) {
+	u, _ := New(40, 20, rules.ConwayRule{})
+	// A row of 4096 identical blocks 8 cells apart
+	for i := 0; i < 4096; i++ {
+		for _, c := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+			u.Set(core.NewCoord2D(i*8+c[0]+2, c[1]+2), core.Alive)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := u.WriteMacrocell(&buf, nil); err != nil {
+		t.Fatalf("WriteMacrocell returned error: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines > 60 {
+		t.Errorf("Repeated blocks should be written once per distinct node, got %d lines", lines)
+	}
+	got, _, err := ReadMacrocell(&buf, 40, 20)
+	if err != nil {
+		t.Fatalf("ReadMacrocell returned error: %v", err)
+	}
+	if !sameLiving(got, u) {
+		t.Errorf("Round trip changed the blocks: %d cells, want %d", got.CountLiving(), u.CountLiving())
+	}
+}
+
+func TestMacrocell_Empty(t *testing.T) {
+	u, _ := New(10, 10, rules.ConwayRule{})
+	var buf bytes.Buffer
+	if err := u.WriteMacrocell(&buf, nil); err != nil {
+		t.Fatalf("WriteMacrocell returned error: %v", err)
+	}
+	got, _, err := ReadMacrocell(&buf, 10, 10)
+	if err != nil {
+		t.Fatalf("ReadMacrocell returned error: %v", err)
+	}
+	if got.CountLiving() != 0 {
+		t.Errorf("An empty universe should read back empty, got %d cells", got.CountLiving())
+	}
+}
+
+func TestReadMacrocell_Errors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		".*$\n",
+		"[M2]\n#R wireworld\n",
+		"[M2]\n#G soon\n",
+		"[M2]\n.*$\n4 0 0 0 2\n",
+		"[M2]\n.*$\n5 0 0 0 1\n",
+		"[M2]\n1 0 1 0 1\n",
+		"[M2]\n.x$\n",
+		"[M2]\n.........*$\n",
+	} {
+		if _, _, err := ReadMacrocell(strings.NewReader(input), 10, 10); err == nil {
+			t.Errorf("ReadMacrocell(%q) should return an error", input)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package hashlife
+
+// node is a square of 2^level x 2^level cells. Nodes are immutable and
+// hash-consed: two nodes with the same children are the same pointer, so
+// identical regions anywhere in space or time are stored and evolved once.
+type node struct {
+	nw, ne, sw, se *node // Quadrants; nil for leaves (level 0)
+	level          int
+	population     int
+
+	// next memoizes the center of the node advanced 2^nextStep generations
+	next     *node
+	nextStep int
+}
+
+// quad is the hash-consing key of an inner node
+type quad struct {
+	nw, ne, sw, se *node
+}
+
+// store owns the canonical nodes and the rule's base-case table
+type store struct {
+	table map[quad]*node
+	dead  *node
+	alive *node
+	empty []*node // empty[level] is the empty node of that level
+	lut   *baseLUT
+}
+
+// newStore creates a node store stepping with the given base-case table
+func newStore(lut *baseLUT) *store {
+	s := &store{
+		table: make(map[quad]*node),
+		dead:  &node{},
+		alive: &node{population: 1},
+		lut:   lut,
+	}
+	s.empty = []*node{s.dead}
+	return s
+}
+
+// leaf returns the level-0 node for a cell
+func (s *store) leaf(alive bool) *node {
+	if alive {
+		return s.alive
+	}
+	return s.dead
+}
+
+// join returns the canonical node with the given quadrants
+func (s *store) join(nw, ne, sw, se *node) *node {
+	key := quad{nw, ne, sw, se}
+	if n, ok := s.table[key]; ok {
+		return n
+	}
+	n := &node{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level:      nw.level + 1,
+		population: nw.population + ne.population + sw.population + se.population,
+	}
+	s.table[key] = n
+	return n
+}
+
+// emptyNode returns the empty node of a level
+func (s *store) emptyNode(level int) *node {
+	for len(s.empty) <= level {
+		e := s.empty[len(s.empty)-1]
+		s.empty = append(s.empty, s.join(e, e, e, e))
+	}
+	return s.empty[level]
+}
+
+// center returns the middle half of a node, one level down
+func (s *store) center(n *node) *node {
+	return s.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// horizontal returns the node straddling the border between w and e
+func (s *store) horizontal(w, e *node) *node {
+	return s.join(w.ne, e.nw, w.se, e.sw)
+}
+
+// vertical returns the node straddling the border between n and so
+func (s *store) vertical(n, so *node) *node {
+	return s.join(n.sw, n.se, so.nw, so.ne)
+}
+
+// expand returns a node one level up with n in its center and empty space around it
+func (s *store) expand(n *node) *node {
+	e := s.emptyNode(n.level - 1)
+	return s.join(
+		s.join(e, e, e, n.nw),
+		s.join(e, e, n.ne, e),
+		s.join(e, n.sw, e, e),
+		s.join(n.se, e, e, e),
+	)
+}
+
+// nextGen returns the center of n (one level down) advanced 2^step
+// generations, or 2^(level-2) generations if step is larger than that.
+// n must be at least level 2.
+func (s *store) nextGen(n *node, step int) *node {
+	effective := step
+	if effective > n.level-2 {
+		effective = n.level - 2
+	}
+	if n.next != nil && n.nextStep == effective {
+		return n.next
+	}
+	if n.population == 0 {
+		return s.emptyNode(n.level - 1)
+	}
+
+	var result *node
+	if n.level == 2 {
+		result = s.base(n)
+	} else {
+		// Nine overlapping squares one level down tile the middle of n
+		n00, n01, n02 := n.nw, s.horizontal(n.nw, n.ne), n.ne
+		n10, n11, n12 := s.vertical(n.nw, n.sw), s.center(n), s.vertical(n.ne, n.se)
+		n20, n21, n22 := n.sw, s.horizontal(n.sw, n.se), n.se
+
+		if effective == n.level-2 {
+			// Full speed: advance each square 2^(level-3), then the four
+			// combinations another 2^(level-3)
+			n00, n01, n02 = s.nextGen(n00, step), s.nextGen(n01, step), s.nextGen(n02, step)
+			n10, n11, n12 = s.nextGen(n10, step), s.nextGen(n11, step), s.nextGen(n12, step)
+			n20, n21, n22 = s.nextGen(n20, step), s.nextGen(n21, step), s.nextGen(n22, step)
+		} else {
+			// Smaller step: take the squares' centers unchanged and advance
+			// only the four combinations
+			n00, n01, n02 = s.center(n00), s.center(n01), s.center(n02)
+			n10, n11, n12 = s.center(n10), s.center(n11), s.center(n12)
+			n20, n21, n22 = s.center(n20), s.center(n21), s.center(n22)
+		}
+		result = s.join(
+			s.nextGen(s.join(n00, n01, n10, n11), step),
+			s.nextGen(s.join(n01, n02, n11, n12), step),
+			s.nextGen(s.join(n10, n11, n20, n21), step),
+			s.nextGen(s.join(n11, n12, n21, n22), step),
+		)
+	}
+
+	n.next, n.nextStep = result, effective
+	return result
+}
+
+// base advances the center 2x2 of a 4x4 node one generation using the lookup table
+func (s *store) base(n *node) *node {
+	var bits uint16
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if cellAt(n, x, y) {
+				bits |= 1 << (y*4 + x)
+			}
+		}
+	}
+	r := s.lut[bits]
+	return s.join(s.leaf(r&1 != 0), s.leaf(r&2 != 0), s.leaf(r&4 != 0), s.leaf(r&8 != 0))
+}
+
+// cellAt reports whether the cell at (x, y) within n is alive, with (0, 0)
+// the top-left corner of the node
+func cellAt(n *node, x, y int) bool {
+	for n.level > 0 {
+		half := 1 << (n.level - 1)
+		switch {
+		case x < half && y < half:
+			n = n.nw
+		case y < half:
+			n, x = n.ne, x-half
+		case x < half:
+			n, y = n.sw, y-half
+		default:
+			n, x, y = n.se, x-half, y-half
+		}
+	}
+	return n.population == 1
+}
+
+// setCell returns n with the cell at (x, y) set, sharing every untouched subtree
+func (s *store) setCell(n *node, x, y int, alive bool) *node {
+	if n.level == 0 {
+		return s.leaf(alive)
+	}
+	half := 1 << (n.level - 1)
+	switch {
+	case x < half && y < half:
+		return s.join(s.setCell(n.nw, x, y, alive), n.ne, n.sw, n.se)
+	case y < half:
+		return s.join(n.nw, s.setCell(n.ne, x-half, y, alive), n.sw, n.se)
+	case x < half:
+		return s.join(n.nw, n.ne, s.setCell(n.sw, x, y-half, alive), n.se)
+	default:
+		return s.join(n.nw, n.ne, n.sw, s.setCell(n.se, x-half, y-half, alive))
+	}
+}
+
+// forEachLiving calls fn for every live cell of n inside the window
+// [minX, maxX) x [minY, maxY), with n's top-left corner at (x, y)
+func forEachLiving(n *node, x, y, minX, minY, maxX, maxY int, fn func(x, y int)) {
+	size := 1 << n.level
+	if n.population == 0 || x >= maxX || y >= maxY || x+size <= minX || y+size <= minY {
+		return
+	}
+	if n.level == 0 {
+		fn(x, y)
+		return
+	}
+	half := size / 2
+	forEachLiving(n.nw, x, y, minX, minY, maxX, maxY, fn)
+	forEachLiving(n.ne, x+half, y, minX, minY, maxX, maxY, fn)
+	forEachLiving(n.sw, x, y+half, minX, minY, maxX, maxY, fn)
+	forEachLiving(n.se, x+half, y+half, minX, minY, maxX, maxY, fn)
+}
+
+// collect rebuilds the table with only the nodes reachable from root and
+// drops all memoized results, releasing everything else to the garbage collector
+func (s *store) collect(root *node) {
+	s.table = make(map[quad]*node, len(s.table)/4)
+	s.empty = []*node{s.dead}
+	var visit func(n *node)
+	visit = func(n *node) {
+		if n.level == 0 {
+			return
+		}
+		key := quad{n.nw, n.ne, n.sw, n.se}
+		if _, ok := s.table[key]; ok {
+			return
+		}
+		n.next = nil
+		s.table[key] = n
+		visit(n.nw)
+		visit(n.ne)
+		visit(n.sw)
+		visit(n.se)
+	}
+	visit(root)
+}
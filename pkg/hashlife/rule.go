@@ -0,0 +1,83 @@
+package hashlife
+
+import (
+	"fmt"
+
+	"golife/pkg/core"
+)
+
+// baseLUT maps a 4x4 block (bit y*4+x set for a live cell) to the next
+// state of its center 2x2: bit 0 NW, bit 1 NE, bit 2 SW, bit 3 SE
+type baseLUT [1 << 16]uint8
+
+// mooreOffsets lists the Moore neighbors in core.MaskRule bit order
+var mooreOffsets = [8][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+// newBaseLUT builds the base-case table for a rule. HashLife handles
+// two-state rules on the range-1 Moore neighborhood: outer-totalistic rules
+// (core.Rule) and isotropic non-totalistic ones (core.MaskRule).
+func newBaseLUT(rule core.Rule) (*baseLUT, error) {
+	if err := checkRule(rule); err != nil {
+		return nil, err
+	}
+
+	// Decide every neighborhood once: index = mask<<1 | alive
+	var decide [512]bool
+	mr, isMask := rule.(core.MaskRule)
+	for mask := 0; mask < 256; mask++ {
+		count := 0
+		for bit := 0; bit < 8; bit++ {
+			count += mask >> bit & 1
+		}
+		if isMask {
+			decide[mask<<1] = mr.ShouldBirthMask(uint8(mask))
+			decide[mask<<1|1] = mr.ShouldSurviveMask(uint8(mask))
+		} else {
+			decide[mask<<1] = rule.ShouldBirth(count)
+			decide[mask<<1|1] = rule.ShouldSurvive(count, core.Alive)
+		}
+	}
+
+	lut := &baseLUT{}
+	for block := 0; block < len(lut); block++ {
+		var result uint8
+		for i, c := range [4][2]int{{1, 1}, {2, 1}, {1, 2}, {2, 2}} {
+			mask := 0
+			for bit, o := range mooreOffsets {
+				x, y := c[0]+o[0], c[1]+o[1]
+				mask |= (block >> (y*4 + x) & 1) << bit
+			}
+			alive := block >> (c[1]*4 + c[0]) & 1
+			if decide[mask<<1|alive] {
+				result |= 1 << i
+			}
+		}
+		lut[block] = result
+	}
+	return lut, nil
+}
+
+// checkRule reports why a rule cannot run on HashLife, if it cannot
+func checkRule(rule core.Rule) error {
+	if _, ok := rule.(core.TransitionRule); ok {
+		return fmt.Errorf("hashlife does not support rule tables (%s)", rule.Name())
+	}
+	if ms, ok := rule.(core.MultiStateRule); ok && ms.States() > 2 {
+		return fmt.Errorf("hashlife does not support multi-state rules (%s has %d states)", rule.Name(), ms.States())
+	}
+	if rule.ShouldBirth(0) {
+		return fmt.Errorf("hashlife does not support B0 rules (%s), which fill the empty plane", rule.Name())
+	}
+	if nr, ok := rule.(core.NeighborhoodRule); ok {
+		n := nr.Neighborhood()
+		if n.Type != core.Moore || n.Range != 1 {
+			return fmt.Errorf("hashlife only supports the range-1 Moore neighborhood (%s uses %s range %d)", rule.Name(), n.Type, n.Range)
+		}
+	}
+	for _, o := range core.MooreNeighborhood(1).OffsetsFor(core.Dim2D) {
+		if rule.NeighborWeight(o.Distance()) != 1.0 {
+			return fmt.Errorf("hashlife does not support weighted neighbors (%s)", rule.Name())
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package pkg_test
 
 import (
+	"golife/pkg/core"
 	"golife/pkg/patterns"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
@@ -113,11 +114,18 @@ func TestIntegration_Pulsar(t *testing.T) {
 
 // TestIntegration_AllPatterns tests that all patterns can be loaded
 func TestIntegration_AllPatterns(t *testing.T) {
-	rule := rules.ConwayRule{}
 	allPatterns := patterns.AllPatterns()
 
 	for name, pattern := range allPatterns {
 		t.Run(name, func(t *testing.T) {
+			var rule core.Rule = rules.ConwayRule{}
+			if pattern.Rule != "" {
+				var err error
+				if rule, err = rules.Parse(pattern.Rule); err != nil {
+					t.Fatalf("Pattern %s names an invalid rule: %v", name, err)
+				}
+			}
+
 			// Create universe large enough for the pattern
 			u := universe.New2D(100, 100, rule)
 
@@ -139,6 +147,37 @@ func TestIntegration_AllPatterns(t *testing.T) {
 	}
 }
 
+// TestIntegration_WireworldClock tests that the clock sends a pulse down its wire every 10 generations
+func TestIntegration_WireworldClock(t *testing.T) {
+	p := patterns.WireworldClock()
+	rule, err := rules.Parse(p.Rule)
+	if err != nil {
+		t.Fatalf("Failed to parse rule %q: %v", p.Rule, err)
+	}
+	u := universe.New2D(p.Width, p.Height+2, rule)
+	p.LoadIntoUniverse(u, 0, 1)
+
+	// Record when an electron head arrives at the end of the wire
+	end := core.NewCoord2D(p.Width-1, 2)
+	var arrivals []int
+	for gen := 1; gen <= 60; gen++ {
+		u.Step()
+		if u.Get(end) == 1 {
+			arrivals = append(arrivals, gen)
+		}
+	}
+
+	if len(arrivals) < 4 {
+		t.Fatalf("Expected at least 4 pulses at the end of the wire, got %v", arrivals)
+	}
+	for i := 1; i < len(arrivals); i++ {
+		if arrivals[i]-arrivals[i-1] != 10 {
+			t.Errorf("Pulses should arrive every 10 generations, got %v", arrivals)
+			break
+		}
+	}
+}
+
 // BenchmarkUniverse2D_Step benchmarks the step function
 func BenchmarkUniverse2D_Step(b *testing.B) {
 	rule := rules.ConwayRule{}
@@ -0,0 +1,150 @@
+package patterns
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Pattern file formats
+const (
+	FormatRLE       = "rle"
+	FormatPlaintext = "plaintext"
+	FormatLife105   = "life105"
+	FormatLife106   = "life106"
+)
+
+// IsFile reports whether name refers to a pattern file rather than a
+// built-in pattern
+func IsFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".rle", ".cells", ".lif", ".life", ".txt":
+		return true
+	default:
+		return false
+	}
+}
+
+// FormatForPath picks the format to write from a file extension: RLE for
+// .rle, Life 1.06 for .lif and .life, plaintext otherwise
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".rle":
+		return FormatRLE
+	case ".lif", ".life":
+		return FormatLife106
+	default:
+		return FormatPlaintext
+	}
+}
+
+// DetectFormat recognizes the format of pattern file contents from their
+// first lines: the "#Life 1.05"/"#Life 1.06" headers, RLE's "x = ..."
+// header line (after any '#' comments), plaintext's '!' comments or rows of
+// '.' and 'O', and bare "x y" coordinate lines as Life 1.06.
+func DetectFormat(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, life105Header), strings.HasPrefix(line, "#P"):
+			return FormatLife105, nil
+		case strings.HasPrefix(line, life106Header):
+			return FormatLife106, nil
+		case strings.HasPrefix(line, "#"):
+			// RLE comments come before the header line
+		case strings.HasPrefix(line, "!"):
+			return FormatPlaintext, nil
+		case strings.HasPrefix(line, "x") && strings.Contains(line, "="):
+			return FormatRLE, nil
+		case strings.Trim(line, ".Oo*") == "":
+			return FormatPlaintext, nil
+		case strings.Trim(line, "-0123456789 \t") == "" && len(strings.Fields(line)) == 2:
+			return FormatLife106, nil
+		default:
+			return "", fmt.Errorf("unrecognized pattern format")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("unrecognized pattern format")
+}
+
+// Read reads a pattern in any supported format, detected from its contents
+func Read(r io.Reader) (Pattern2D, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Pattern2D{}, fmt.Errorf("failed to read pattern: %w", err)
+	}
+	format, err := DetectFormat(data)
+	if err != nil {
+		return Pattern2D{}, err
+	}
+
+	src := bytes.NewReader(data)
+	switch format {
+	case FormatRLE:
+		return ReadRLE(src)
+	case FormatLife105:
+		return ReadLife105(src)
+	case FormatLife106:
+		return ReadLife106(src)
+	default:
+		return ReadPlaintext(src)
+	}
+}
+
+// Write writes a pattern in the given format
+func Write(w io.Writer, p Pattern2D, format string) error {
+	switch format {
+	case FormatRLE:
+		return WriteRLE(w, p)
+	case FormatPlaintext:
+		return WritePlaintext(w, p)
+	case FormatLife105:
+		return WriteLife105(w, p)
+	case FormatLife106:
+		return WriteLife106(w, p)
+	default:
+		return fmt.Errorf("unknown pattern format %q (want rle, plaintext, life105 or life106)", format)
+	}
+}
+
+// ReadFile reads a pattern file, detecting its format from the contents.
+// A pattern without a name is named after the file.
+func ReadFile(path string) (Pattern2D, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Pattern2D{}, fmt.Errorf("failed to open pattern: %w", err)
+	}
+	defer f.Close()
+
+	p, err := Read(f)
+	if err != nil {
+		return Pattern2D{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// WriteFile writes a pattern file in the format FormatForPath picks
+func WriteFile(path string, p Pattern2D) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write pattern: %w", err)
+	}
+	if err := Write(f, p, FormatForPath(path)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write pattern: %w", err)
+	}
+	return f.Close()
+}
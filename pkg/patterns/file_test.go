@@ -0,0 +1,128 @@
+package patterns
+
+import (
+	"bytes"
+	"golife/pkg/core"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// twoState returns the pattern with every non-dead cell alive, as the
+// two-state formats store it
+func twoState(p Pattern2D) Pattern2D {
+	cells := make([][]core.CellState, len(p.Cells))
+	for y, row := range p.Cells {
+		cells[y] = make([]core.CellState, len(row))
+		for x, state := range row {
+			if state != core.Dead {
+				cells[y][x] = core.Alive
+			}
+		}
+	}
+	p.Cells = cells
+	return p
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"#N Glider\n#C comment\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n", FormatRLE},
+		{"x=3,y=3\nbo$2bo$3o!\n", FormatRLE},
+		{"!Name: Glider\n.O\n..O\nOOO\n", FormatPlaintext},
+		{"\n.O\n..O\nOOO\n", FormatPlaintext},
+		{"#Life 1.05\n#D Glider\n#N\n#P 0 0\n.*\n..*\n***\n", FormatLife105},
+		{"#D Glider\n#P 0 0\n.*\n", FormatLife105},
+		{"#Life 1.06\n1 0\n2 1\n", FormatLife106},
+		{"1 0\n-2 1\n", FormatLife106},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat([]byte(tt.input))
+		if err != nil {
+			t.Errorf("DetectFormat(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "hello world\n", "#C only comments\n"} {
+		if _, err := DetectFormat([]byte(input)); err == nil {
+			t.Errorf("DetectFormat(%q) should return an error", input)
+		}
+	}
+}
+
+func TestRoundTrip_AllFormats(t *testing.T) {
+	formats := []string{FormatRLE, FormatPlaintext, FormatLife105, FormatLife106}
+	for name, p := range AllPatterns() {
+		for _, format := range formats {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := Write(&buf, p, format); err != nil {
+					t.Fatalf("Write returned error: %v", err)
+				}
+				got, err := Read(&buf)
+				if err != nil {
+					t.Fatalf("Read returned error: %v", err)
+				}
+
+				// RLE keeps every state; the other formats store only live
+				// cells, so multi-state patterns come back two-state
+				want := p
+				if format != FormatRLE {
+					want = twoState(p)
+				}
+				if !sameCells(got.Trim(), want.Trim()) {
+					t.Errorf("Round trip changed the cells:\n%v\nwant\n%v", got.Cells, want.Cells)
+				}
+				if format != FormatLife106 && got.Name != p.Name {
+					t.Errorf("Round trip changed the name to %q, want %q", got.Name, p.Name)
+				}
+			})
+		}
+	}
+}
+
+func TestReadFile_WriteFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"gun.rle", "gun.cells", "gun.lif"} {
+		path := filepath.Join(dir, file)
+		if err := WriteFile(path, GliderGun()); err != nil {
+			t.Fatalf("WriteFile(%q) returned error: %v", file, err)
+		}
+		p, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) returned error: %v", file, err)
+		}
+		if !sameCells(p.Trim(), GliderGun()) {
+			t.Errorf("ReadFile(%q) did not return the glider gun", file)
+		}
+	}
+
+	// Life 1.06 has no name, so the file name is used
+	p, err := ReadFile(filepath.Join(dir, "gun.lif"))
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	if p.Name != "gun" {
+		t.Errorf("Name = %q, want the file name", p.Name)
+	}
+
+	// The format comes from the contents, not the extension
+	rle := filepath.Join(dir, "gun.rle")
+	misnamed := filepath.Join(dir, "gun.txt")
+	data, err := os.ReadFile(rle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(misnamed, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ReadFile(misnamed); err != nil || !sameCells(p, GliderGun()) {
+		t.Errorf("ReadFile should detect RLE in a .txt file, got error %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package patterns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// Headers of the Life 1.05 and 1.06 formats
+const (
+	life105Header = "#Life 1.05"
+	life106Header = "#Life 1.06"
+)
+
+// ReadLife106 reads a pattern in the Life 1.06 format: a "#Life 1.06" header
+// followed by one "x y" line per live cell. The coordinates may be negative;
+// the pattern is the bounding box of the cells.
+func ReadLife106(r io.Reader) (Pattern2D, error) {
+	var cells []core.Coord
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return Pattern2D{}, fmt.Errorf("invalid Life 1.06: line %d: want \"x y\", got %q", line, text)
+		}
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		if errX != nil || errY != nil {
+			return Pattern2D{}, fmt.Errorf("invalid Life 1.06: line %d: bad coordinates %q", line, text)
+		}
+		cells = append(cells, core.NewCoord2D(x, y))
+	}
+	if err := scanner.Err(); err != nil {
+		return Pattern2D{}, fmt.Errorf("failed to read Life 1.06: %w", err)
+	}
+	return patternFromCoords(cells)
+}
+
+// patternFromCoords builds a pattern from the bounding box of live cells
+func patternFromCoords(cells []core.Coord) (Pattern2D, error) {
+	if len(cells) == 0 {
+		return Pattern2D{}, nil
+	}
+	minC, maxC := cells[0], cells[0]
+	for _, c := range cells[1:] {
+		minC.X, minC.Y = min(minC.X, c.X), min(minC.Y, c.Y)
+		maxC.X, maxC.Y = max(maxC.X, c.X), max(maxC.Y, c.Y)
+	}
+	// The spans are unsigned, since extreme coordinates are further apart
+	// than an int holds
+	spanX, spanY := uint(maxC.X)-uint(minC.X), uint(maxC.Y)-uint(minC.Y)
+	if spanX >= maxPatternSide || spanY >= maxPatternSide {
+		return Pattern2D{}, fmt.Errorf("pattern spans more than %d cells", maxPatternSide)
+	}
+	p := Pattern2D{Width: int(spanX) + 1, Height: int(spanY) + 1}
+	if err := checkPatternSize(p.Width, p.Height); err != nil {
+		return Pattern2D{}, err
+	}
+	p.Cells = make([][]core.CellState, p.Height)
+	for y := range p.Cells {
+		p.Cells[y] = make([]core.CellState, p.Width)
+	}
+	for _, c := range cells {
+		p.Cells[c.Y-minC.Y][c.X-minC.X] = core.Alive
+	}
+	return p, nil
+}
+
+// WriteLife106 writes the live cells of a pattern in the Life 1.06 format,
+// relative to its top-left corner. The format has no name, description or
+// rule, and every non-dead state is written as alive.
+func WriteLife106(w io.Writer, p Pattern2D) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(life106Header + "\n")
+	for y := 0; y < len(p.Cells) && y < p.Height; y++ {
+		for x := 0; x < len(p.Cells[y]) && x < p.Width; x++ {
+			if p.Cells[y][x] != core.Dead {
+				fmt.Fprintf(bw, "%d %d\n", x, y)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadLife105 reads a pattern in the Life 1.05 format: a "#Life 1.05"
+// header, "#D" description lines ("#D Name: ..." gives the name), "#N" for
+// Conway's rule or "#R s/b" for another, then blocks of '*' and '.' rows each
+// placed by a "#P x y" line.
+func ReadLife105(r io.Reader) (Pattern2D, error) {
+	var description []string
+	var cells []core.Coord
+	name, rule := "", ""
+	x0, y0, y := 0, 0, 0
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#Life"):
+		case strings.HasPrefix(text, "#D") || strings.HasPrefix(text, "#C"):
+			comment := strings.TrimSpace(text[2:])
+			if n, ok := strings.CutPrefix(comment, "Name:"); ok && name == "" {
+				name = strings.TrimSpace(n)
+			} else {
+				description = append(description, comment)
+			}
+		case strings.HasPrefix(text, "#N"):
+			rule = ""
+		case strings.HasPrefix(text, "#R"):
+			var err error
+			if rule, err = life105Rule(strings.TrimSpace(text[2:])); err != nil {
+				return Pattern2D{}, fmt.Errorf("invalid Life 1.05: line %d: %w", line, err)
+			}
+		case strings.HasPrefix(text, "#P"):
+			fields := strings.Fields(text[2:])
+			var errX, errY error
+			if len(fields) == 2 {
+				x0, errX = strconv.Atoi(fields[0])
+				y0, errY = strconv.Atoi(fields[1])
+			}
+			if len(fields) != 2 || errX != nil || errY != nil {
+				return Pattern2D{}, fmt.Errorf("invalid Life 1.05: line %d: bad block position %q", line, text)
+			}
+			y = 0
+		case strings.HasPrefix(text, "#"):
+		default:
+			for x := 0; x < len(text); x++ {
+				switch text[x] {
+				case '*', 'O', 'o':
+					cells = append(cells, core.NewCoord2D(x0+x, y0+y))
+				case '.':
+				default:
+					return Pattern2D{}, fmt.Errorf("invalid Life 1.05: line %d: unexpected character %q", line, text[x])
+				}
+			}
+			y++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Pattern2D{}, fmt.Errorf("failed to read Life 1.05: %w", err)
+	}
+
+	p, err := patternFromCoords(cells)
+	if err != nil {
+		return Pattern2D{}, err
+	}
+	p.Name = name
+	p.Description = strings.Join(description, "\n")
+	p.Rule = rule
+	return p, nil
+}
+
+// life105RulePattern matches the survival/birth counts of a Life 1.05 rule
+var life105RulePattern = regexp.MustCompile(`^([0-8]*)/([0-8]*)$`)
+
+// bsRulePattern matches a plain B/S rulestring
+var bsRulePattern = regexp.MustCompile(`^[Bb]([0-8]*)/[Ss]([0-8]*)$`)
+
+// life105Rule converts the S/B counts of a "#R" line, e.g. "23/3", to a
+// B/S rulestring
+func life105Rule(s string) (string, error) {
+	m := life105RulePattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("bad rule %q (want survival/birth counts such as 23/3)", s)
+	}
+	return "B" + m[2] + "/S" + m[1], nil
+}
+
+// WriteLife105 writes a pattern in the Life 1.05 format as a single block.
+// The name and description become "#D" lines. A B/S rule other than
+// Conway's is written as "#R s/b"; other rules cannot be expressed and are
+// left out. Every non-dead state is written as alive.
+func WriteLife105(w io.Writer, p Pattern2D) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(life105Header + "\n")
+	if p.Name != "" {
+		bw.WriteString("#D Name: " + p.Name + "\n")
+	}
+	for _, line := range strings.Split(p.Description, "\n") {
+		if line != "" {
+			bw.WriteString("#D " + line + "\n")
+		}
+	}
+	switch m := bsRulePattern.FindStringSubmatch(p.Rule); {
+	case m != nil && !(m[1] == "3" && m[2] == "23"):
+		fmt.Fprintf(bw, "#R %s/%s\n", m[2], m[1])
+	case m != nil || p.Rule == "":
+		bw.WriteString("#N\n")
+	}
+
+	bw.WriteString("#P 0 0\n")
+	row := make([]byte, 0, p.Width)
+	for y := 0; y < p.Height; y++ {
+		row = row[:0]
+		for x := 0; x < p.Width; x++ {
+			if y < len(p.Cells) && x < len(p.Cells[y]) && p.Cells[y][x] != core.Dead {
+				row = append(row, '*')
+			} else {
+				row = append(row, '.')
+			}
+		}
+		bw.Write(append(trimDead(row), '\n'))
+	}
+	return bw.Flush()
+}
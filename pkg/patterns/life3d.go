@@ -0,0 +1,265 @@
+package patterns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golife/pkg/core"
+	"golife/pkg/universe"
+)
+
+// life3DHeader starts a 3D pattern file
+const life3DHeader = "#Life3D 1.0"
+
+// Life3DExt is the file extension of 3D pattern files
+const Life3DExt = ".life3d"
+
+// ReadPattern3D reads a 3D pattern in layered RLE: an optional "#Life3D 1.0"
+// line, "#N" (name) and "#C" (description) comments, a header line such as
+// "x = 5, y = 5, z = 4, period = 2, rule = B6/S567", then runs of cells in
+// which '$' ends a row and '/' ends a z layer, up to '!'. The period and rule
+// are optional, and z defaults to a single layer. The cells must fit in the
+// size the header declares. Cell letters and states are those of ReadRLE,
+// except that without a rule the states are kept as they are (1 meaning
+// alive), since 2.5D patterns may hold energy levels no rulestring describes.
+func ReadPattern3D(r io.Reader) (*Pattern3D, error) {
+	return ReadPattern3DWithin(r, core.NewCoord3D(maxPatternSide, maxPatternSide, maxPatternSide))
+}
+
+// ReadPattern3DWithin reads a 3D pattern as ReadPattern3D does, but rejects
+// a pattern larger than size from its header, before reading any cells
+func ReadPattern3DWithin(r io.Reader, size core.Coord) (*Pattern3D, error) {
+	p := &Pattern3D{Cells: make(map[core.Coord]core.CellState)}
+	var description []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	headerFound := false
+	for !headerFound && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			tag, text := rleComment(line)
+			switch tag {
+			case 'N':
+				p.Name = text
+			case 'C':
+				description = append(description, text)
+			}
+		default:
+			fields, rule, err := parseRLEHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			p.Width, p.Height, p.Depth = fields["x"], fields["y"], fields["z"]
+			if _, ok := fields["z"]; !ok {
+				p.Depth = 1
+			}
+			p.Period, p.Rule = fields["period"], rule
+			headerFound = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read 3D pattern: %w", err)
+	}
+	if !headerFound {
+		return nil, fmt.Errorf("3D pattern has no header line")
+	}
+	p.Description = strings.Join(description, "\n")
+	if err := p.checkSize(size); err != nil {
+		return nil, err
+	}
+
+	layers, err := readRLECells(scanner, true, rleLimits{p.Width, p.Height, p.Depth, p.Width * p.Height * p.Depth})
+	if err != nil {
+		return nil, err
+	}
+	toState := stateMapping(p.Rule)
+	if p.Rule == "" {
+		toState = rawState
+	}
+	for z, rows := range layers {
+		for y, row := range rows {
+			for x, s := range row {
+				if state := toState(s); state != core.Dead {
+					p.Cells[core.NewCoord3D(x, y, z)] = state
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// checkSize returns an error if the pattern is larger than size or than
+// the readers' limits
+func (p *Pattern3D) checkSize(size core.Coord) error {
+	if p.Width > size.X || p.Height > size.Y || p.Depth > size.Z {
+		return fmt.Errorf("3D pattern of %dx%dx%d cells does not fit in %dx%dx%d", p.Width, p.Height, p.Depth, size.X, size.Y, size.Z)
+	}
+	if err := checkPatternSize(p.Width, p.Height); err != nil {
+		return err
+	}
+	if p.Depth > maxPatternSide || p.Width*p.Height*p.Depth > maxPatternCells {
+		return fmt.Errorf("3D pattern of %dx%dx%d cells is too large", p.Width, p.Height, p.Depth)
+	}
+	return nil
+}
+
+// WritePattern3D writes a 3D pattern in the layered RLE format ReadPattern3D
+// reads, with its name, description, period and rule. Cells must not have
+// negative coordinates; the size grows to cover any beyond Width, Height
+// and Depth.
+func WritePattern3D(w io.Writer, p *Pattern3D) error {
+	width, height, depth := p.Width, p.Height, p.Depth
+	for c := range p.Cells {
+		if c.X < 0 || c.Y < 0 || c.Z < 0 {
+			return fmt.Errorf("cell (%d,%d,%d) is outside the pattern", c.X, c.Y, c.Z)
+		}
+		width, height, depth = max(width, c.X+1), max(height, c.Y+1), max(depth, c.Z+1)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(life3DHeader + "\n")
+	if p.Name != "" {
+		fmt.Fprintf(bw, "#N %s\n", p.Name)
+	}
+	for _, line := range strings.Split(p.Description, "\n") {
+		if line != "" {
+			fmt.Fprintf(bw, "#C %s\n", line)
+		}
+	}
+	fmt.Fprintf(bw, "x = %d, y = %d, z = %d", width, height, depth)
+	if p.Period > 0 {
+		fmt.Fprintf(bw, ", period = %d", p.Period)
+	}
+	if p.Rule != "" {
+		fmt.Fprintf(bw, ", rule = %s", p.Rule)
+	}
+	bw.WriteString("\n")
+
+	// Only layers with cells are allocated; the others are written as a run of '/'
+	toFile := fileStateMapping(p.Rule)
+	if p.Rule == "" {
+		toFile = rawFileState
+	}
+	layers := make([][][]int, depth)
+	multiState := false
+	for c, s := range p.Cells {
+		state := toFile(s)
+		if state == 0 {
+			continue
+		}
+		if layers[c.Z] == nil {
+			layers[c.Z] = make([][]int, height)
+			for y := range layers[c.Z] {
+				layers[c.Z][y] = make([]int, width)
+			}
+		}
+		layers[c.Z][c.Y][c.X] = state
+		multiState = multiState || state > 1
+	}
+
+	enc := rleEncoder{w: bw}
+	pendingLayers := 0 // Layer ends not yet written, so trailing empty layers are dropped
+	for _, rows := range layers {
+		if rows != nil {
+			enc.run(pendingLayers, "/")
+			pendingLayers = 0
+			enc.rows(rows, multiState)
+		}
+		pendingLayers++
+	}
+	enc.run(1, "!")
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// ReadPattern25D reads a 2.5D pattern in the format of ReadPattern3D, with
+// z as the layer
+func ReadPattern25D(r io.Reader) (*Pattern25D, error) {
+	p, err := ReadPattern3D(r)
+	if err != nil {
+		return nil, err
+	}
+	return (*Pattern25D)(p), nil
+}
+
+// WritePattern25D writes a 2.5D pattern in the format of WritePattern3D
+func WritePattern25D(w io.Writer, p *Pattern25D) error {
+	return WritePattern3D(w, (*Pattern3D)(p))
+}
+
+// ReadPattern3DFile reads a 3D pattern file. A pattern without a name is
+// named after the file.
+func ReadPattern3DFile(path string) (*Pattern3D, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pattern: %w", err)
+	}
+	defer f.Close()
+
+	p, err := ReadPattern3D(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// FindPattern3D returns the built-in 3D pattern with the given name, or
+// parses s as pattern text when it is not a name. Patterns larger than size
+// are rejected.
+func FindPattern3D(s string, size core.Coord) (*Pattern3D, error) {
+	if p := LoadPattern3D(strings.TrimSpace(s)); p != nil {
+		if err := p.checkSize(size); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	if !strings.ContainsAny(s, "\n=!") {
+		return nil, fmt.Errorf("unknown 3D pattern %q (available: %s)", s, strings.Join(ListPatterns3D(), ", "))
+	}
+	return ReadPattern3DWithin(strings.NewReader(s), size)
+}
+
+// FromUniverse3D copies the cells of a universe into a pattern the size of
+// the universe, made for its rule
+func FromUniverse3D(u *universe.Universe3D) *Pattern3D {
+	return fromVolume(u, u.Rule())
+}
+
+// FromUniverse25D copies the cells of a 2.5D universe into a pattern the
+// size of the universe, made for its rule
+func FromUniverse25D(u *universe.Universe25D) *Pattern25D {
+	return (*Pattern25D)(fromVolume(u, u.Rule()))
+}
+
+// fromVolume copies the non-dead cells of a 3D or 2.5D universe
+func fromVolume(u core.Universe, rule core.Rule) *Pattern3D {
+	size := u.Size()
+	p := &Pattern3D{
+		Rule:   ruleName(rule),
+		Width:  size.X,
+		Height: size.Y,
+		Depth:  size.Z,
+		Cells:  make(map[core.Coord]core.CellState),
+	}
+	for z := 0; z < size.Z; z++ {
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				c := core.NewCoord3D(x, y, z)
+				if state := u.Get(c); state != core.Dead {
+					p.Cells[c] = state
+				}
+			}
+		}
+	}
+	return p
+}
@@ -0,0 +1,207 @@
+package patterns
+
+import (
+	"bytes"
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"maps"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestReadPattern3D(t *testing.T) {
+	input := `#Life3D 1.0
+#N Blinker
+#C A bar in two planes
+x = 4, y = 3, z = 2, period = 2, rule = B6/S567
+$b3o/
+$b3o!
+`
+	p, err := ReadPattern3D(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPattern3D returned error: %v", err)
+	}
+	if p.Name != "Blinker" || p.Description != "A bar in two planes" {
+		t.Errorf("Name/Description = %q/%q", p.Name, p.Description)
+	}
+	if p.Rule != "B6/S567" || p.Period != 2 {
+		t.Errorf("Rule/Period = %q/%d, want B6/S567/2", p.Rule, p.Period)
+	}
+	if p.Width != 4 || p.Height != 3 || p.Depth != 2 {
+		t.Errorf("Size = %dx%dx%d, want 4x3x2", p.Width, p.Height, p.Depth)
+	}
+	want := make(map[core.Coord]core.CellState)
+	for z := 0; z < 2; z++ {
+		for x := 1; x < 4; x++ {
+			want[core.NewCoord3D(x, 1, z)] = core.Alive
+		}
+	}
+	if !maps.Equal(p.Cells, want) {
+		t.Errorf("Cells = %v, want %v", p.Cells, want)
+	}
+}
+
+func TestReadPattern3D_EmptyLayersAndStates(t *testing.T) {
+	p, err := ReadPattern3D(strings.NewReader("x = 2, y = 1, z = 3, rule = B2/S/C3\n2/AB!"))
+	if err != nil {
+		t.Fatalf("ReadPattern3D returned error: %v", err)
+	}
+	if p.Depth != 3 || p.Period != 0 {
+		t.Errorf("Depth/Period = %d/%d, want 3/0", p.Depth, p.Period)
+	}
+	want := map[core.Coord]core.CellState{
+		core.NewCoord3D(0, 0, 2): core.Alive,
+		core.NewCoord3D(1, 0, 2): 1, // Dying
+	}
+	if !maps.Equal(p.Cells, want) {
+		t.Errorf("Cells = %v, want %v", p.Cells, want)
+	}
+}
+
+func TestReadPattern3D_Errors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"#N No header\n",
+		"x = 3, y = 3, z = two\no!",
+		"x = 3, y = 3, z = 1\no?o!",
+		"x = 3, y = 3, z = 1\n4o!",                     // Row beyond the declared width
+		"x = 3, y = 1, z = 1\no$o!",                    // Rows beyond the declared height
+		"x = 3, y = 3, z = 1\no/o!",                    // Layers beyond the declared depth
+		"x = 1, y = 1, z = 1\n16777216b16777216b!",     // Runs far beyond the declared size
+		"x = 99999999, y = 99999999, z = 99999999\no!", // Header beyond the limits
+	} {
+		if _, err := ReadPattern3D(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadPattern3D(%q) should return an error", input)
+		}
+	}
+}
+
+func TestWritePattern3D(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePattern3D(&buf, Blinker3D()); err != nil {
+		t.Fatalf("WritePattern3D returned error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), life3DHeader+"\n#N ") {
+		t.Errorf("Expected the header and name first, got\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), ", z = 3, period = 2, rule = B6/S567\n") {
+		t.Errorf("Expected the depth, period and rule in the header, got\n%s", buf.String())
+	}
+
+	p := &Pattern3D{Cells: map[core.Coord]core.CellState{core.NewCoord3D(-1, 0, 0): core.Alive}}
+	if err := WritePattern3D(&buf, p); err == nil {
+		t.Error("WritePattern3D should reject negative coordinates")
+	}
+}
+
+func TestPattern3D_RoundTrip(t *testing.T) {
+	for name, p := range GetPatterns3D() {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WritePattern3D(&buf, p); err != nil {
+				t.Fatalf("WritePattern3D returned error: %v", err)
+			}
+			got, err := ReadPattern3D(&buf)
+			if err != nil {
+				t.Fatalf("ReadPattern3D returned error: %v", err)
+			}
+			if !maps.Equal(got.Cells, p.Cells) {
+				t.Errorf("Round trip changed the cells:\n%v\nwant\n%v", got.Cells, p.Cells)
+			}
+			if got.Name != p.Name || got.Description != p.Description || got.Rule != p.Rule || got.Period != p.Period {
+				t.Errorf("Round trip changed name/description/rule/period to %q/%q/%q/%d",
+					got.Name, got.Description, got.Rule, got.Period)
+			}
+			if got.Width != p.Width || got.Height != p.Height || got.Depth != p.Depth {
+				t.Errorf("Round trip changed the size to %dx%dx%d", got.Width, got.Height, got.Depth)
+			}
+		})
+	}
+}
+
+func TestPattern25D_RoundTrip(t *testing.T) {
+	for name, p := range GetPatterns25D() {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WritePattern25D(&buf, p); err != nil {
+				t.Fatalf("WritePattern25D returned error: %v", err)
+			}
+			got, err := ReadPattern25D(&buf)
+			if err != nil {
+				t.Fatalf("ReadPattern25D returned error: %v", err)
+			}
+			if !maps.Equal(got.Cells, p.Cells) || got.Name != p.Name {
+				t.Errorf("Round trip changed %q:\n%v\nwant\n%v", got.Name, got.Cells, p.Cells)
+			}
+		})
+	}
+}
+
+func TestPattern3D_UniverseRoundTrip(t *testing.T) {
+	rule, err := rules.Parse("4555")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	generations, err := rules.Parse("B6/S567/C4")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	for _, r := range []core.Rule{rule, generations, rules.Life3D_B6S567{}} {
+		u := universe.New3D(12, 10, 8, r)
+		rng := rand.New(rand.NewSource(3))
+		for z := 0; z < 8; z++ {
+			for y := 0; y < 10; y++ {
+				for x := 0; x < 12; x++ {
+					if rng.Float64() < 0.3 {
+						u.Set(core.NewCoord3D(x, y, z), core.Alive)
+					}
+				}
+			}
+		}
+		u.Step() // Leave decaying cells under the Generations rule
+
+		var buf bytes.Buffer
+		if err := WritePattern3D(&buf, FromUniverse3D(u)); err != nil {
+			t.Fatalf("WritePattern3D returned error: %v", err)
+		}
+		p, err := ReadPattern3D(&buf)
+		if err != nil {
+			t.Fatalf("ReadPattern3D returned error: %v", err)
+		}
+		if _, err := rules.Parse(p.Rule); err != nil {
+			t.Errorf("Rule %q of %s does not parse: %v", p.Rule, r.Name(), err)
+		}
+		copied := p.CreateUniverse(r)
+		if !maps.Equal(FromUniverse3D(copied).Cells, FromUniverse3D(u).Cells) {
+			t.Errorf("Round trip under %s changed the universe", r.Name())
+		}
+	}
+}
+
+func TestFindPattern3D(t *testing.T) {
+	size := core.NewCoord3D(16, 16, 16)
+	p, err := FindPattern3D("wheel", size)
+	if err != nil || p.Name != Wheel3D().Name {
+		t.Errorf("FindPattern3D(wheel) = %v, %v", p, err)
+	}
+
+	p, err = FindPattern3D("x = 2, y = 2, z = 2\n2o$2o/2o$2o!", size)
+	if err != nil {
+		t.Fatalf("FindPattern3D returned error for pattern text: %v", err)
+	}
+	if !maps.Equal(p.Cells, Block3D().Cells) {
+		t.Errorf("Cells = %v, want a block", p.Cells)
+	}
+
+	if _, err := FindPattern3D("no-such-pattern", size); err == nil {
+		t.Error("FindPattern3D should reject an unknown name")
+	}
+	if _, err := FindPattern3D("x = 17, y = 1, z = 1\no!", size); err == nil {
+		t.Error("FindPattern3D should reject pattern text larger than the universe")
+	}
+	if _, err := FindPattern3D("wheel", core.NewCoord3D(2, 2, 2)); err == nil {
+		t.Error("FindPattern3D should reject a built-in larger than the universe")
+	}
+}
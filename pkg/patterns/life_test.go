@@ -0,0 +1,85 @@
+package patterns
+
+import (
+	"bytes"
+	"golife/pkg/core"
+	"strings"
+	"testing"
+)
+
+func TestReadLife106(t *testing.T) {
+	input := "#Life 1.06\n0 -1\n1 0\n-1 1\n0 1\n1 1\n"
+	p, err := ReadLife106(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadLife106 returned error: %v", err)
+	}
+	if !sameCells(p, Glider()) {
+		t.Errorf("ReadLife106 cells = %v, want a glider", p.Cells)
+	}
+
+	if _, err := ReadLife106(strings.NewReader("#Life 1.06\n1 2 3\n")); err == nil {
+		t.Error("ReadLife106 should reject lines that are not \"x y\"")
+	}
+	for _, input := range []string{
+		"#Life 1.06\n-9223372036854775808 0\n9223372036854775807 0\n",
+		"#Life 1.06\n0 -9223372036854775808\n0 9223372036854775807\n",
+		"#Life 1.06\n0 0\n70000 0\n",
+		"#Life 1.06\n0 0\n60000 60000\n",
+	} {
+		if _, err := ReadLife106(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadLife106(%q) should reject a pattern that large", input)
+		}
+	}
+}
+
+func TestReadLife105(t *testing.T) {
+	input := `#Life 1.05
+#D Name: Two blinkers
+#D Placed in separate blocks
+#R 23/36
+#P -1 -1
+***
+#P 3 1
+*
+*
+*
+`
+	p, err := ReadLife105(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadLife105 returned error: %v", err)
+	}
+	if p.Name != "Two blinkers" || p.Description != "Placed in separate blocks" || p.Rule != "B36/S23" {
+		t.Errorf("Name/Description/Rule = %q/%q/%q", p.Name, p.Description, p.Rule)
+	}
+	if p.Width != 5 || p.Height != 5 {
+		t.Fatalf("Expected the blocks to span 5x5, got %dx%d", p.Width, p.Height)
+	}
+	for _, c := range [][2]int{{0, 0}, {1, 0}, {2, 0}, {4, 2}, {4, 3}, {4, 4}} {
+		if p.Cells[c[1]][c[0]] != core.Alive {
+			t.Errorf("Cell (%d,%d) should be alive", c[0], c[1])
+		}
+	}
+
+	if _, err := ReadLife105(strings.NewReader("#Life 1.05\n#R B3/S23\n*\n")); err == nil {
+		t.Error("ReadLife105 should reject a rule that is not s/b counts")
+	}
+}
+
+func TestWriteLife105_Rule(t *testing.T) {
+	p := Blinker()
+	// Rules that are not plain B/S counts get no rule line at all
+	for rule, want := range map[string]string{"": "#N", "B3/S23": "#N", "B36/S23": "#R 23/36", "B2/S/C3": ""} {
+		p.Rule = rule
+		var buf bytes.Buffer
+		if err := WriteLife105(&buf, p); err != nil {
+			t.Fatalf("WriteLife105 returned error: %v", err)
+		}
+		if want == "" {
+			if strings.Contains(buf.String(), "\n#N\n") || strings.Contains(buf.String(), "\n#R") {
+				t.Errorf("Rule %q: expected no rule line, got\n%s", rule, buf.String())
+			}
+		} else if !strings.Contains(buf.String(), "\n"+want+"\n") {
+			t.Errorf("Rule %q: expected a %q line, got\n%s", rule, want, buf.String())
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 type Pattern25D struct {
 	Name        string
 	Description string
+	Rule        string // Rulestring the pattern is made for; empty if unspecified
+	Period      int    // Period of an oscillator or spaceship, 1 for a still life; 0 if unknown
 	Width       int
 	Height      int
 	Depth       int
@@ -2,6 +2,7 @@ package patterns
 
 import (
 	"golife/pkg/core"
+	"golife/pkg/rules"
 	"golife/pkg/universe"
 )
 
@@ -12,6 +13,7 @@ type Pattern2D struct {
 	Width       int
 	Height      int
 	Cells       [][]core.CellState
+	Rule        string // Rule the pattern is made for (see rules.Parse); empty for Life-like rules
 }
 
 // LoadIntoUniverse loads this pattern into a universe at the given offset
@@ -26,6 +28,60 @@ func (p *Pattern2D) LoadIntoUniverse(u *universe.Universe2D, offsetX, offsetY in
 	}
 }
 
+// FromUniverse2D copies the width×height region of a universe starting at
+// (x, y) into a pattern made for the universe's rule. Cells beyond the grid
+// are dead.
+func FromUniverse2D(u *universe.Universe2D, x, y, width, height int) Pattern2D {
+	cells := make([][]core.CellState, height)
+	for dy := range cells {
+		cells[dy] = make([]core.CellState, width)
+		for dx := range cells[dy] {
+			cells[dy][dx] = u.Get(core.NewCoord2D(x+dx, y+dy))
+		}
+	}
+	return Pattern2D{Width: width, Height: height, Cells: cells, Rule: ruleName(u.Rule())}
+}
+
+// ruleName returns the rulestring a pattern records for rule: empty for
+// Conway's Life, which patterns assume, and a parseable name for the fixed
+// 3D rule
+func ruleName(rule core.Rule) string {
+	switch rule.(type) {
+	case rules.ConwayRule:
+		return ""
+	case rules.Life3D_B6S567:
+		return "B6/S567"
+	}
+	return rule.Name()
+}
+
+// Trim returns the pattern cropped to the bounding box of its non-dead cells.
+// An empty pattern trims to 0×0.
+func (p Pattern2D) Trim() Pattern2D {
+	minX, minY, maxX, maxY := p.Width, p.Height, -1, -1
+	for y := 0; y < len(p.Cells) && y < p.Height; y++ {
+		for x := 0; x < len(p.Cells[y]) && x < p.Width; x++ {
+			if p.Cells[y][x] != core.Dead {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+
+	trimmed := p
+	trimmed.Width, trimmed.Height, trimmed.Cells = 0, 0, nil
+	if maxX < 0 {
+		return trimmed
+	}
+	trimmed.Width, trimmed.Height = maxX-minX+1, maxY-minY+1
+	trimmed.Cells = make([][]core.CellState, trimmed.Height)
+	for y := range trimmed.Cells {
+		trimmed.Cells[y] = make([]core.CellState, trimmed.Width)
+		copy(trimmed.Cells[y], p.Cells[minY+y][minX:min(maxX+1, len(p.Cells[minY+y]))])
+	}
+	return trimmed
+}
+
 // Glider returns the classic glider pattern
 func Glider() Pattern2D {
 	const (
@@ -166,6 +222,64 @@ func Block() Pattern2D {
 	}
 }
 
+// WireworldClock returns a Wireworld loop with one electron circling it,
+// which sends a pulse down the attached wire every 10 generations
+func WireworldClock() Pattern2D {
+	const (
+		O = core.CellState(0) // Empty
+		H = core.CellState(1) // Electron head
+		T = core.CellState(2) // Electron tail
+		C = core.CellState(3) // Conductor
+	)
+	return Pattern2D{
+		Name:        "Wireworld Clock",
+		Description: "A period-10 Wireworld clock feeding a wire (rule: wireworld)",
+		Width:       20,
+		Height:      3,
+		Cells: [][]core.CellState{
+			{O, T, H, C, C, O, O, O, O, O, O, O, O, O, O, O, O, O, O, O},
+			{C, O, O, O, O, C, C, C, C, C, C, C, C, C, C, C, C, C, C, C},
+			{O, C, C, C, C, O, O, O, O, O, O, O, O, O, O, O, O, O, O, O},
+		},
+		Rule: "wireworld",
+	}
+}
+
+// LangtonsLoop returns Langton's self-reproducing loop, which builds a copy of
+// itself every 151 generations (rule: langtons-loops)
+func LangtonsLoop() Pattern2D {
+	rows := []string{
+		" 22222222",
+		"2170140142",
+		"2022222202",
+		"272    212",
+		"212    212",
+		"202    212",
+		"272    212",
+		"21222222122222",
+		"207107107111112",
+		" 2222222222222",
+	}
+
+	cells := make([][]core.CellState, len(rows))
+	for y, row := range rows {
+		cells[y] = make([]core.CellState, 15)
+		for x, c := range row {
+			if c != ' ' {
+				cells[y][x] = core.CellState(c - '0')
+			}
+		}
+	}
+	return Pattern2D{
+		Name:        "Langton's Loop",
+		Description: "A self-reproducing loop (rule: langtons-loops)",
+		Width:       15,
+		Height:      10,
+		Cells:       cells,
+		Rule:        "langtons-loops",
+	}
+}
+
 // AllPatterns returns a map of all available 2D patterns
 func AllPatterns() map[string]Pattern2D {
 	return map[string]Pattern2D{
@@ -176,5 +290,8 @@ func AllPatterns() map[string]Pattern2D {
 		"pulsar":     Pulsar(),
 		"glider-gun": GliderGun(),
 		"block":      Block(),
+
+		"wireworld-clock": WireworldClock(),
+		"langtons-loop":   LangtonsLoop(),
 	}
 }
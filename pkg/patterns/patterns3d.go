@@ -10,6 +10,8 @@ import (
 type Pattern3D struct {
 	Name        string
 	Description string
+	Rule        string // Rulestring the pattern is made for; empty if unspecified
+	Period      int    // Period of an oscillator or spaceship, 1 for a still life; 0 if unknown
 	Width       int
 	Height      int
 	Depth       int
@@ -54,6 +56,7 @@ func BaysGlider() *Pattern3D {
 	return &Pattern3D{
 		Name:        "Bays's Glider",
 		Description: "Period-4 glider, moves √2 cells/4 gens (Carter Bays 1987)",
+		Rule:        "B6/S567",
 		Width:       5,
 		Height:      5,
 		Depth:       4,
@@ -84,6 +87,8 @@ func Block3D() *Pattern3D {
 	return &Pattern3D{
 		Name:        "3D Block",
 		Description: "Stable 2×2×2 cube, 8 cells (Bays 1987)",
+		Rule:        "B6/S567",
+		Period:      1,
 		Width:       3,
 		Height:      3,
 		Depth:       3,
@@ -120,6 +125,8 @@ func Beehive3D() *Pattern3D {
 	return &Pattern3D{
 		Name:        "3D Beehive",
 		Description: "Stable beehive structure, 14 cells (Bays 1987)",
+		Rule:        "B6/S567",
+		Period:      1,
 		Width:       5,
 		Height:      4,
 		Depth:       4,
@@ -158,6 +165,7 @@ func Bucket3D() *Pattern3D {
 	return &Pattern3D{
 		Name:        "Bucket",
 		Description: "Stable container structure, 16 cells (Bays 1987)",
+		Rule:        "B6/S567",
 		Width:       5,
 		Height:      5,
 		Depth:       3,
@@ -184,6 +192,8 @@ func Blinker3D() *Pattern3D {
 	return &Pattern3D{
 		Name:        "3D Blinker",
 		Description: "Period-2 oscillator, 6 cells (Bays 1987)",
+		Rule:        "B6/S567",
+		Period:      2,
 		Width:       4,
 		Height:      3,
 		Depth:       3,
@@ -221,6 +231,7 @@ func Flashlight3D() *Pattern3D {
 	return &Pattern3D{
 		Name:        "Flashlight",
 		Description: "Period-4 oscillator, 14 cells (Bays 1987)",
+		Rule:        "B6/S567",
 		Width:       5,
 		Height:      5,
 		Depth:       4,
@@ -254,6 +265,7 @@ func Wheel3D() *Pattern3D {
 	return &Pattern3D{
 		Name:        "Wheel",
 		Description: "Period-2 oscillator, 12 cells (Bays 1987)",
+		Rule:        "B6/S567",
 		Width:       5,
 		Height:      5,
 		Depth:       4,
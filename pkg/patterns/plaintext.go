@@ -0,0 +1,104 @@
+package patterns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// ReadPlaintext reads a pattern in the plaintext (.cells) format: "!"
+// comment lines, where "!Name: ..." gives the name and the others the
+// description, then one line per row with 'O' (or '*') for live cells and
+// '.' for dead ones. Rows may be cut short after their last live cell.
+func ReadPlaintext(r io.Reader) (Pattern2D, error) {
+	var p Pattern2D
+	var description []string
+	var rows []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.HasPrefix(line, "!") {
+			text := strings.TrimSpace(line[1:])
+			if name, ok := strings.CutPrefix(text, "Name:"); ok {
+				p.Name = strings.TrimSpace(name)
+			} else if text != "" {
+				description = append(description, text)
+			}
+			continue
+		}
+		if line == "" && len(rows) == 0 {
+			continue // Blank lines before the first row
+		}
+		rows = append(rows, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return Pattern2D{}, fmt.Errorf("failed to read plaintext: %w", err)
+	}
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+
+	p.Description = strings.Join(description, "\n")
+	p.Height = len(rows)
+	for _, row := range rows {
+		p.Width = max(p.Width, len(row))
+	}
+	p.Cells = make([][]core.CellState, p.Height)
+	for y, row := range rows {
+		p.Cells[y] = make([]core.CellState, p.Width)
+		for x := 0; x < len(row); x++ {
+			switch row[x] {
+			case 'O', 'o', '*':
+				p.Cells[y][x] = core.Alive
+			case '.', ' ':
+			default:
+				return Pattern2D{}, fmt.Errorf("invalid plaintext: unexpected character %q in row %d", row[x], y+1)
+			}
+		}
+	}
+	return p, nil
+}
+
+// WritePlaintext writes a pattern in the plaintext (.cells) format: "!"
+// comment lines with the name and description, then one line per row with
+// 'O' for live cells and '.' for dead ones. Trailing dead cells are left
+// out. The format has two states, so every non-dead state is written as 'O'.
+func WritePlaintext(w io.Writer, p Pattern2D) error {
+	bw := bufio.NewWriter(w)
+	if p.Name != "" {
+		bw.WriteString("!Name: " + p.Name + "\n")
+	}
+	for _, line := range strings.Split(p.Description, "\n") {
+		if line != "" {
+			bw.WriteString("!" + line + "\n")
+		}
+	}
+
+	row := make([]byte, 0, p.Width)
+	for y := 0; y < p.Height; y++ {
+		row = row[:0]
+		for x := 0; x < p.Width; x++ {
+			if y < len(p.Cells) && x < len(p.Cells[y]) && p.Cells[y][x] != core.Dead {
+				row = append(row, 'O')
+			} else {
+				row = append(row, '.')
+			}
+		}
+		bw.Write(append(trimDead(row), '\n'))
+	}
+	return bw.Flush()
+}
+
+// trimDead drops the trailing dead cells of a plaintext row, keeping one
+// so that empty rows are not mistaken for blank lines
+func trimDead(row []byte) []byte {
+	end := len(row)
+	for end > 1 && row[end-1] == '.' {
+		end--
+	}
+	return row[:end]
+}
@@ -0,0 +1,70 @@
+package patterns
+
+import (
+	"bytes"
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"strings"
+	"testing"
+)
+
+func TestWritePlaintext(t *testing.T) {
+	var buf bytes.Buffer
+	p := Glider()
+	p.Cells = append(p.Cells, []core.CellState{core.Dead, core.Dead, core.Dead})
+	p.Height++
+	if err := WritePlaintext(&buf, p); err != nil {
+		t.Fatalf("WritePlaintext returned error: %v", err)
+	}
+
+	want := "!Name: Glider\n!A small pattern that moves diagonally\n.O\n..O\nOOO\n.\n"
+	if buf.String() != want {
+		t.Errorf("WritePlaintext wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestFromUniverse2D_Trim(t *testing.T) {
+	u := universe.New2D(20, 20, rules.ConwayRule{})
+	glider := Glider()
+	glider.LoadIntoUniverse(u, 7, 9)
+
+	p := FromUniverse2D(u, 0, 0, 20, 20)
+	if p.Width != 20 || p.Height != 20 {
+		t.Fatalf("Expected a 20x20 region, got %dx%d", p.Width, p.Height)
+	}
+
+	trimmed := p.Trim()
+	if trimmed.Width != 3 || trimmed.Height != 3 {
+		t.Fatalf("Trim should crop to the 3x3 glider, got %dx%d", trimmed.Width, trimmed.Height)
+	}
+	for y := range glider.Cells {
+		for x := range glider.Cells[y] {
+			if trimmed.Cells[y][x] != glider.Cells[y][x] {
+				t.Errorf("Cell (%d,%d) = %d, want %d", x, y, trimmed.Cells[y][x], glider.Cells[y][x])
+			}
+		}
+	}
+
+	if empty := FromUniverse2D(u, 0, 0, 5, 5).Trim(); empty.Width != 0 || empty.Height != 0 {
+		t.Errorf("An empty region should trim to 0x0, got %dx%d", empty.Width, empty.Height)
+	}
+}
+
+func TestReadPlaintext(t *testing.T) {
+	input := "!Name: Glider\n!A small pattern that moves diagonally\n.O\n..O\nOOO\n"
+	p, err := ReadPlaintext(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPlaintext returned error: %v", err)
+	}
+	if p.Name != "Glider" || p.Description != "A small pattern that moves diagonally" {
+		t.Errorf("Name/Description = %q/%q", p.Name, p.Description)
+	}
+	if !sameCells(p, Glider()) {
+		t.Errorf("ReadPlaintext cells = %v, want a glider", p.Cells)
+	}
+
+	if _, err := ReadPlaintext(strings.NewReader(".O\nOxO\n")); err == nil {
+		t.Error("ReadPlaintext should reject unknown characters")
+	}
+}
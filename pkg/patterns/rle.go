@@ -0,0 +1,452 @@
+package patterns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+	"golife/pkg/rules"
+)
+
+// rleLineWidth is the longest body line WriteRLE writes
+const rleLineWidth = 70
+
+// Limits on the patterns the readers build, so malformed input cannot
+// exhaust memory
+const (
+	maxPatternSide  = 1 << 16
+	maxPatternCells = 1 << 24
+)
+
+// checkPatternSize returns an error for a pattern beyond the size limits
+func checkPatternSize(width, height int) error {
+	if width < 0 || height < 0 || width > maxPatternSide || height > maxPatternSide || width*height > maxPatternCells {
+		return fmt.Errorf("pattern of %dx%d cells is too large", width, height)
+	}
+	return nil
+}
+
+// ReadRLE reads a pattern in the run-length encoded (.rle) format used by
+// Golly and LifeWiki: "#N" (name) and "#C"/"#c"/"#O" (description) comment
+// lines, a header line such as "x = 3, y = 3, rule = B3/S23", then runs of
+// cells ending with '!'. Both the two-state letters (b dead, o alive) and the
+// multi-state letters ('.' empty, A-X, then pA-yO for states 25-255) are
+// read, and runs may be wrapped over any number of lines.
+//
+// States are mapped to the pattern's rule: live cells of two-state rules are
+// core.Alive, Generations states count down from core.Alive, and rule table
+// states are kept as indices.
+func ReadRLE(r io.Reader) (Pattern2D, error) {
+	var p Pattern2D
+	var description []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	// Comments and the header
+	headerFound := false
+	for !headerFound && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			tag, text := rleComment(line)
+			switch tag {
+			case 'N':
+				p.Name = text
+			case 'C', 'c', 'O':
+				description = append(description, text)
+			case 'r':
+				p.Rule = text
+			}
+		default:
+			fields, rule, err := parseRLEHeader(line)
+			if err != nil {
+				return Pattern2D{}, err
+			}
+			p.Width, p.Height = fields["x"], fields["y"]
+			if rule != "" {
+				p.Rule = rule
+			}
+			headerFound = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Pattern2D{}, fmt.Errorf("failed to read RLE: %w", err)
+	}
+	if !headerFound {
+		return Pattern2D{}, fmt.Errorf("RLE has no header line")
+	}
+	p.Description = strings.Join(description, "\n")
+	if err := checkPatternSize(p.Width, p.Height); err != nil {
+		return Pattern2D{}, fmt.Errorf("invalid RLE: %w", err)
+	}
+
+	layers, err := readRLECells(scanner, false, rleLimits{maxPatternSide, maxPatternSide, 1, maxPatternCells})
+	if err != nil {
+		return Pattern2D{}, err
+	}
+	rows := layers[0]
+
+	// The header gives the size, but a pattern overrunning it is kept whole
+	p.Height = max(p.Height, len(rows))
+	for _, row := range rows {
+		p.Width = max(p.Width, len(row))
+	}
+	if err := checkPatternSize(p.Width, p.Height); err != nil {
+		return Pattern2D{}, fmt.Errorf("invalid RLE: %w", err)
+	}
+	toState := stateMapping(p.Rule)
+	p.Cells = make([][]core.CellState, p.Height)
+	for y := range p.Cells {
+		p.Cells[y] = make([]core.CellState, p.Width)
+		if y < len(rows) {
+			for x, s := range rows[y] {
+				p.Cells[y][x] = toState(s)
+			}
+		}
+	}
+	return p, nil
+}
+
+// rleLimits bound what readRLECells reads: the cells of a row, the rows of a
+// layer, the layers and the cells in all
+type rleLimits struct {
+	width, height, depth, cells int
+}
+
+// readRLECells reads runs of cells up to '!' and returns the file states of
+// each row. With layered set, '/' ends a layer as '$' ends a row (the 3D
+// format); otherwise there is a single layer. Runs that go beyond the limits
+// are rejected before they are stored.
+func readRLECells(scanner *bufio.Scanner, layered bool, limits rleLimits) ([][][]int, error) {
+	var layers [][][]int
+	var rows [][]int
+	var row []int
+	cells := 0
+	count := 0
+	prefix := byte(0) // Pending multi-state prefix letter p-y
+	done := false
+	for !done && scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for i := 0; i < len(line) && !done; i++ {
+			c := line[i]
+			switch {
+			case c == ' ' || c == '\t' || c == '\r':
+				continue
+			case c >= '0' && c <= '9':
+				if prefix != 0 {
+					return nil, fmt.Errorf("invalid RLE: count after state prefix %q", prefix)
+				}
+				count = count*10 + int(c-'0')
+				if count > 1<<24 {
+					return nil, fmt.Errorf("invalid RLE: run of %d cells is too long", count)
+				}
+				continue
+			}
+
+			n := max(count, 1)
+			count = 0
+			state := -1
+			switch {
+			case c == '!':
+				done = true
+			case c == '$':
+				if len(rows)+n > limits.height {
+					return nil, fmt.Errorf("invalid RLE: more than %d rows", limits.height)
+				}
+				rows = append(rows, row)
+				for j := 1; j < n; j++ {
+					rows = append(rows, nil)
+				}
+				row = nil
+			case c == '/' && layered:
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+				if len(layers)+n > limits.depth {
+					return nil, fmt.Errorf("invalid RLE: more than %d layers", limits.depth)
+				}
+				layers = append(layers, rows)
+				for j := 1; j < n; j++ {
+					layers = append(layers, nil)
+				}
+				rows, row = nil, nil
+			case prefix != 0:
+				if c < 'A' || c > 'X' {
+					return nil, fmt.Errorf("invalid RLE: state prefix %q followed by %q", prefix, c)
+				}
+				state = int(prefix-'p'+1)*24 + int(c-'A') + 1
+				if state > 255 {
+					return nil, fmt.Errorf("invalid RLE: state %d is out of range", state)
+				}
+				prefix = 0
+			case c == 'b' || c == '.':
+				state = 0
+			case c >= 'A' && c <= 'X':
+				state = int(c-'A') + 1
+			case c >= 'p' && c <= 'y' && i+1 < len(line) && line[i+1] >= 'A' && line[i+1] <= 'X':
+				prefix = c
+				count = n // The run applies to the state the prefix starts
+				continue
+			case c >= 'a' && c <= 'z':
+				state = 1 // Two-state patterns may use any letter other than b for live cells
+			default:
+				return nil, fmt.Errorf("invalid RLE: unexpected character %q", c)
+			}
+			if state >= 0 {
+				if len(rows) >= limits.height || len(layers) >= limits.depth {
+					return nil, fmt.Errorf("invalid RLE: cells beyond %d rows and %d layers", limits.height, limits.depth)
+				}
+				if len(row)+n > limits.width {
+					return nil, fmt.Errorf("invalid RLE: row of more than %d cells", limits.width)
+				}
+				if cells += n; cells > limits.cells {
+					return nil, fmt.Errorf("invalid RLE: more than %d cells", limits.cells)
+				}
+			}
+			for j := 0; state >= 0 && j < n; j++ {
+				row = append(row, state)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read RLE: %w", err)
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return append(layers, rows), nil
+}
+
+// rleComment splits a "#X text" comment line into its tag and text
+func rleComment(line string) (byte, string) {
+	if len(line) < 2 {
+		return 0, ""
+	}
+	return line[1], strings.TrimSpace(line[2:])
+}
+
+// parseRLEHeader reads a header line such as "x = 3, y = 3, rule = B3/S23"
+// into its numeric fields and the rule. The rule runs to the end of the
+// line, since some rulestrings contain commas.
+func parseRLEHeader(line string) (map[string]int, string, error) {
+	fields := make(map[string]int)
+	rule := ""
+	rest := line
+	for rest != "" {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid RLE header %q", line)
+		}
+		key = strings.TrimSpace(key)
+		if key == "rule" {
+			// Drop a Golly bounded-grid suffix such as ":T100,100"
+			rule, _, _ = strings.Cut(value, ":")
+			rule = strings.TrimSpace(rule)
+			break
+		}
+		value, rest, _ = strings.Cut(value, ",")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return nil, "", fmt.Errorf("invalid RLE header %q: bad %s", line, key)
+		}
+		fields[key] = n
+	}
+	return fields, rule, nil
+}
+
+// stateMapping returns how file states 0..255 map to cell states under rule.
+// Rule tables and rules that cannot be parsed keep their state indices.
+func stateMapping(rule string) func(int) core.CellState {
+	r, err := parseRule(rule)
+	switch r := r.(type) {
+	case core.TransitionRule:
+		return func(s int) core.CellState { return core.CellState(s) }
+	case core.MultiStateRule:
+		// File state 1 is alive and 2, 3, ... decay towards dead
+		n := r.States()
+		return func(s int) core.CellState {
+			switch {
+			case s == 1:
+				return core.Alive
+			case s > 1 && s < n:
+				return core.CellState(n - s)
+			}
+			return core.Dead
+		}
+	}
+	if err != nil {
+		return rawState
+	}
+	return func(s int) core.CellState {
+		if s != 0 {
+			return core.Alive
+		}
+		return core.Dead
+	}
+}
+
+// fileStateMapping is the inverse of stateMapping
+func fileStateMapping(rule string) func(core.CellState) int {
+	r, err := parseRule(rule)
+	switch r := r.(type) {
+	case core.TransitionRule:
+		return func(s core.CellState) int { return int(s) }
+	case core.MultiStateRule:
+		n := r.States()
+		return func(s core.CellState) int {
+			switch {
+			case s == core.Alive:
+				return 1
+			case s != core.Dead && int(s) < n-1:
+				return n - int(s)
+			}
+			return 0
+		}
+	}
+	if err != nil {
+		return rawFileState
+	}
+	return func(s core.CellState) int {
+		if s != core.Dead {
+			return 1
+		}
+		return 0
+	}
+}
+
+// rawState keeps a file state as the cell state, except that 1 is alive
+func rawState(s int) core.CellState {
+	if s == 1 {
+		return core.Alive
+	}
+	return core.CellState(s)
+}
+
+// rawFileState is the inverse of rawState
+func rawFileState(s core.CellState) int {
+	if s == core.Alive {
+		return 1
+	}
+	return int(s)
+}
+
+// parseRule parses the rule a pattern is made for; an empty rule is Conway's
+func parseRule(rule string) (core.Rule, error) {
+	if rule == "" {
+		return rules.ConwayRule{}, nil
+	}
+	return rules.Parse(rule)
+}
+
+// WriteRLE writes a pattern in the RLE format, with "#N" and "#C" comment
+// lines for its name and description and a header giving its size and rule.
+// Patterns whose states are all dead or alive are written with b and o,
+// others with the multi-state letters. Use FromUniverse2D to write a region
+// of a universe.
+func WriteRLE(w io.Writer, p Pattern2D) error {
+	bw := bufio.NewWriter(w)
+	if p.Name != "" {
+		fmt.Fprintf(bw, "#N %s\n", p.Name)
+	}
+	for _, line := range strings.Split(p.Description, "\n") {
+		if line != "" {
+			fmt.Fprintf(bw, "#C %s\n", line)
+		}
+	}
+	fmt.Fprintf(bw, "x = %d, y = %d", p.Width, p.Height)
+	if p.Rule != "" {
+		fmt.Fprintf(bw, ", rule = %s", p.Rule)
+	}
+	bw.WriteString("\n")
+
+	toFile := fileStateMapping(p.Rule)
+	states := make([][]int, p.Height)
+	multiState := false
+	for y := range states {
+		states[y] = make([]int, p.Width)
+		for x := range states[y] {
+			if y < len(p.Cells) && x < len(p.Cells[y]) {
+				states[y][x] = toFile(p.Cells[y][x])
+				multiState = multiState || states[y][x] > 1
+			}
+		}
+	}
+
+	enc := rleEncoder{w: bw}
+	enc.rows(states, multiState)
+	enc.run(1, "!")
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// rleLetter returns the letters for a file state
+func rleLetter(state int, multiState bool) string {
+	switch {
+	case !multiState && state == 0:
+		return "b"
+	case !multiState:
+		return "o"
+	case state == 0:
+		return "."
+	case state <= 24:
+		return string(rune('A' + state - 1))
+	default:
+		return string([]byte{byte('p' + (state-25)/24), byte('A' + (state-25)%24)})
+	}
+}
+
+// rleEncoder writes run tokens, wrapping lines at rleLineWidth without
+// splitting a token
+type rleEncoder struct {
+	w    *bufio.Writer
+	line int
+}
+
+// rows writes the runs of a grid of file states, leaving out trailing dead
+// cells and rows
+func (e *rleEncoder) rows(states [][]int, multiState bool) {
+	pendingRows := 0 // Row ends not yet written, so trailing empty rows are dropped
+	for _, row := range states {
+		end := len(row)
+		for end > 0 && row[end-1] == 0 {
+			end--
+		}
+		if end > 0 {
+			e.run(pendingRows, "$")
+			pendingRows = 0
+		}
+		for x := 0; x < end; {
+			run := 1
+			for x+run < end && row[x+run] == row[x] {
+				run++
+			}
+			e.run(run, rleLetter(row[x], multiState))
+			x += run
+		}
+		pendingRows++
+	}
+}
+
+// run writes n repeats of letter, or nothing when n is 0
+func (e *rleEncoder) run(n int, letter string) {
+	if n == 0 {
+		return
+	}
+	token := letter
+	if n > 1 {
+		token = strconv.Itoa(n) + letter
+	}
+	if e.line+len(token) > rleLineWidth {
+		e.w.WriteString("\n")
+		e.line = 0
+	}
+	e.w.WriteString(token)
+	e.line += len(token)
+}
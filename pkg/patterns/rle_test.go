@@ -0,0 +1,227 @@
+package patterns
+
+import (
+	"bytes"
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"strings"
+	"testing"
+)
+
+// sameCells reports whether two patterns have the same size and cells
+func sameCells(a, b Pattern2D) bool {
+	if a.Width != b.Width || a.Height != b.Height {
+		return false
+	}
+	for y := 0; y < a.Height; y++ {
+		for x := 0; x < a.Width; x++ {
+			if cellAt(a, x, y) != cellAt(b, x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func cellAt(p Pattern2D, x, y int) core.CellState {
+	if y < len(p.Cells) && x < len(p.Cells[y]) {
+		return p.Cells[y][x]
+	}
+	return core.Dead
+}
+
+func TestReadRLE(t *testing.T) {
+	input := `#N Glider
+#C A small pattern
+#C that moves diagonally
+x = 3, y = 3, rule = B3/S23
+bo$2bo
+$3o!
+`
+	p, err := ReadRLE(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadRLE returned error: %v", err)
+	}
+	if p.Name != "Glider" || p.Rule != "B3/S23" {
+		t.Errorf("Name/Rule = %q/%q, want Glider/B3/S23", p.Name, p.Rule)
+	}
+	if p.Description != "A small pattern\nthat moves diagonally" {
+		t.Errorf("Description = %q", p.Description)
+	}
+	if !sameCells(p, Glider()) {
+		t.Errorf("ReadRLE cells = %v, want a glider", p.Cells)
+	}
+}
+
+func TestReadRLE_Formats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][]core.CellState
+		rule  string
+	}{
+		{
+			name:  "empty rows and no rule",
+			input: "x = 2, y = 4\no2$bo!",
+			want:  [][]core.CellState{{core.Alive, 0}, {0, 0}, {0, core.Alive}, {0, 0}},
+		},
+		{
+			name:  "bounded grid suffix",
+			input: "x = 1, y = 1, rule = B3/S23:T20,20\no!",
+			want:  [][]core.CellState{{core.Alive}},
+			rule:  "B3/S23",
+		},
+		{
+			name:  "rule table states",
+			input: "x = 4, y = 1, rule = WireWorld\n.ABC!",
+			want:  [][]core.CellState{{0, 1, 2, 3}},
+			rule:  "WireWorld",
+		},
+		{
+			name:  "generations states",
+			input: "x = 3, y = 1, rule = B2/S/C3\nA.B!",
+			want:  [][]core.CellState{{core.Alive, 0, 1}},
+			rule:  "B2/S/C3",
+		},
+		{
+			name:  "multi-state letters on a Life rule",
+			input: "x = 3, y = 1, rule = B3/S23\nA.A!",
+			want:  [][]core.CellState{{core.Alive, 0, core.Alive}},
+			rule:  "B3/S23",
+		},
+		{
+			name:  "prefixed states of an unknown rule",
+			input: "x = 3, y = 1, rule = NoSuchRule\n2pBA!",
+			want:  [][]core.CellState{{26, 26, core.Alive}},
+			rule:  "NoSuchRule",
+		},
+		{
+			name:  "pattern larger than header",
+			input: "x = 1, y = 1\n3o!",
+			want:  [][]core.CellState{{core.Alive, core.Alive, core.Alive}},
+		},
+		{
+			name:  "rule with commas",
+			input: "x = 1, y = 1, rule = R2,C0,M1,S2..3,B3..3,NM\no!",
+			want:  [][]core.CellState{{core.Alive}},
+			rule:  "R2,C0,M1,S2..3,B3..3,NM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ReadRLE(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadRLE returned error: %v", err)
+			}
+			want := Pattern2D{Width: len(tt.want[0]), Height: len(tt.want), Cells: tt.want}
+			if !sameCells(p, want) {
+				t.Errorf("ReadRLE cells = %v (%dx%d), want %v", p.Cells, p.Width, p.Height, tt.want)
+			}
+			if p.Rule != tt.rule {
+				t.Errorf("Rule = %q, want %q", p.Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestReadRLE_Errors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"#C only a comment\n",
+		"x = a, y = 3\no!",
+		"x = 3, y = 3\no?o!",
+		"x = 3, y = 3\npZ!",
+		"x = 3, y = 99999999999999\no!",
+		"x = 60000, y = 60000\no!",
+	} {
+		if _, err := ReadRLE(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadRLE(%q) should return an error", input)
+		}
+	}
+}
+
+func TestWriteRLE(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRLE(&buf, Glider()); err != nil {
+		t.Fatalf("WriteRLE returned error: %v", err)
+	}
+	want := "#N Glider\n#C A small pattern that moves diagonally\nx = 3, y = 3\nbo$2bo$3o!\n"
+	if buf.String() != want {
+		t.Errorf("WriteRLE wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteRLE_WrapsLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRLE(&buf, GliderGun()); err != nil {
+		t.Fatalf("WriteRLE returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 4 {
+		t.Errorf("Expected the glider gun to wrap over several lines, got\n%s", buf.String())
+	}
+	for _, line := range lines {
+		if len(line) > rleLineWidth {
+			t.Errorf("Line of %d characters exceeds %d: %s", len(line), rleLineWidth, line)
+		}
+	}
+}
+
+func TestRLE_RoundTrip(t *testing.T) {
+	for name, p := range AllPatterns() {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteRLE(&buf, p); err != nil {
+				t.Fatalf("WriteRLE returned error: %v", err)
+			}
+			got, err := ReadRLE(&buf)
+			if err != nil {
+				t.Fatalf("ReadRLE returned error: %v", err)
+			}
+			if !sameCells(got, p) {
+				t.Errorf("Round trip changed the cells:\n%v\nwant\n%v", got.Cells, p.Cells)
+			}
+			if got.Name != p.Name || got.Description != p.Description || got.Rule != p.Rule {
+				t.Errorf("Round trip changed name/description/rule to %q/%q/%q", got.Name, got.Description, got.Rule)
+			}
+		})
+	}
+}
+
+func TestRLE_UniverseRegion(t *testing.T) {
+	rule, err := rules.Parse("brians-brain")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	u := universe.New2D(30, 30, rule)
+	u.RandomizeWith(universe.RandomOptions{Seed: 5, Density: 0.3})
+	for i := 0; i < 3; i++ {
+		u.Step() // Leave some cells in the dying state
+	}
+
+	region := FromUniverse2D(u, 4, 6, 20, 15)
+	var buf bytes.Buffer
+	if err := WriteRLE(&buf, region); err != nil {
+		t.Fatalf("WriteRLE returned error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "rule = B2/S/C3") || !strings.Contains(buf.String(), "B") {
+		t.Errorf("Expected a Generations header and dying cells, got\n%s", buf.String())
+	}
+
+	got, err := ReadRLE(&buf)
+	if err != nil {
+		t.Fatalf("ReadRLE returned error: %v", err)
+	}
+	copied := universe.New2D(30, 30, rule)
+	got.LoadIntoUniverse(copied, 4, 6)
+	for y := 6; y < 21; y++ {
+		for x := 4; x < 24; x++ {
+			c := core.NewCoord2D(x, y)
+			if copied.Get(c) != u.Get(c) {
+				t.Fatalf("Cell (%d,%d) = %d after the round trip, want %d", x, y, copied.Get(c), u.Get(c))
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+
+	"golife/pkg/core"
+)
+
+// DistanceDecayRule wraps a rule so that neighbors contribute less the farther
+// away they are: a neighbor at Euclidean distance d weighs 1/d^Falloff.
+// Birth and survival are decided by the base rule on the rounded weighted sum.
+//
+// The universe adopts the rule's neighborhood (core.NeighborhoodRule), so a
+// range-R neighborhood lets distant cells contribute a fraction of a neighbor.
+type DistanceDecayRule struct {
+	base         core.Rule
+	neighborhood core.Neighborhood
+	falloff      float64
+}
+
+// NewDistanceDecayRule creates a distance-decay rule over the given neighborhood.
+// A negative falloff is treated as 0 (uniform weights).
+func NewDistanceDecayRule(base core.Rule, neighborhood core.Neighborhood, falloff float64) *DistanceDecayRule {
+	if falloff < 0 {
+		falloff = 0
+	}
+	return &DistanceDecayRule{
+		base:         base,
+		neighborhood: neighborhood,
+		falloff:      falloff,
+	}
+}
+
+// Name returns the name of this rule
+func (r *DistanceDecayRule) Name() string {
+	return fmt.Sprintf("%s (distance decay 1/d^%g, %s R%d)",
+		r.base.Name(), r.falloff, r.neighborhood.Type, r.neighborhood.Range)
+}
+
+// Neighborhood returns the neighborhood the weights are applied over
+func (r *DistanceDecayRule) Neighborhood() core.Neighborhood {
+	return r.neighborhood
+}
+
+// States returns the number of states of the base rule (2 unless it is a Generations rule)
+func (r *DistanceDecayRule) States() int {
+	if ms, ok := r.base.(core.MultiStateRule); ok {
+		return ms.States()
+	}
+	return 2
+}
+
+// ShouldBirth determines if a dead cell should become alive
+func (r *DistanceDecayRule) ShouldBirth(neighborCount int) bool {
+	return r.base.ShouldBirth(neighborCount)
+}
+
+// ShouldSurvive determines if a live cell should stay alive
+func (r *DistanceDecayRule) ShouldSurvive(neighborCount int, currentState core.CellState) bool {
+	return r.base.ShouldSurvive(neighborCount, currentState)
+}
+
+// NeighborWeight returns 1/distance^falloff
+func (r *DistanceDecayRule) NeighborWeight(distance float64) float64 {
+	if distance <= 0 {
+		return 1.0
+	}
+	return math.Pow(distance, -r.falloff)
+}
@@ -0,0 +1,49 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"math"
+	"testing"
+)
+
+func TestDistanceDecayRule_NeighborWeight(t *testing.T) {
+	rule := NewDistanceDecayRule(ConwayRule{}, core.MooreNeighborhood(2), 2)
+
+	tests := []struct {
+		distance float64
+		want     float64
+	}{
+		{1, 1},
+		{2, 0.25},
+		{math.Sqrt2, 0.5},
+		{0, 1},
+	}
+
+	for _, tt := range tests {
+		if got := rule.NeighborWeight(tt.distance); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("NeighborWeight(%v) = %v, want %v", tt.distance, got, tt.want)
+		}
+	}
+}
+
+func TestDistanceDecayRule_DelegatesToBase(t *testing.T) {
+	rule := NewDistanceDecayRule(StarWars(), core.CircularNeighborhood(3), -1)
+
+	if rule.NeighborWeight(3) != 1 {
+		t.Error("Negative falloff should be treated as uniform weights")
+	}
+	if rule.States() != 4 {
+		t.Errorf("States() = %d, want 4 from the base rule", rule.States())
+	}
+	if rule.Neighborhood().Type != core.Circular || rule.Neighborhood().Range != 3 {
+		t.Errorf("Neighborhood() = %+v, want Circular R3", rule.Neighborhood())
+	}
+	for n := 0; n <= 8; n++ {
+		if rule.ShouldBirth(n) != StarWars().ShouldBirth(n) {
+			t.Errorf("ShouldBirth(%d) differs from the base rule", n)
+		}
+		if rule.ShouldSurvive(n, core.Alive) != StarWars().ShouldSurvive(n, core.Alive) {
+			t.Errorf("ShouldSurvive(%d) differs from the base rule", n)
+		}
+	}
+}
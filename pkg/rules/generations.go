@@ -0,0 +1,163 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// GenerationsRule implements a Generations rule: an outer-totalistic birth/survival
+// rule where cells that fail to survive decay through States()-2 intermediate
+// states before dying. Dying cells do not count as live neighbors.
+//
+// With 2 states a GenerationsRule behaves like an ordinary Life-like rule.
+type GenerationsRule struct {
+	name    string
+	birth   []bool // birth[n] is true if a dead cell with n neighbors is born
+	survive []bool // survive[n] is true if a live cell with n neighbors survives
+	states  int
+}
+
+// NewGenerationsRule creates a Generations rule from birth and survival neighbor counts.
+// states is clamped to the range 2-255 (CellState values 1-254 hold the decay states).
+// If name is empty, a name is derived from the counts in B/S/C notation.
+func NewGenerationsRule(name string, birth, survive []int, states int) *GenerationsRule {
+	if states < 2 {
+		states = 2
+	}
+	if states > 255 {
+		states = 255
+	}
+
+	r := &GenerationsRule{
+		birth:   countsToTable(birth),
+		survive: countsToTable(survive),
+		states:  states,
+	}
+	if name == "" {
+		name = r.Notation()
+	}
+	r.name = name
+	return r
+}
+
+// countsToTable converts a list of neighbor counts into a lookup table
+func countsToTable(counts []int) []bool {
+	size := 0
+	for _, n := range counts {
+		if n >= size {
+			size = n + 1
+		}
+	}
+	table := make([]bool, size)
+	for _, n := range counts {
+		if n >= 0 {
+			table[n] = true
+		}
+	}
+	return table
+}
+
+// BriansBrain returns Brian's Brain (B2/S/C3)
+// Every live cell dies after one generation, leaving a "dying" trail behind
+func BriansBrain() *GenerationsRule {
+	return NewGenerationsRule("Brian's Brain B2/S/C3", []int{2}, nil, 3)
+}
+
+// StarWars returns Star Wars (B2/S345/C4)
+func StarWars() *GenerationsRule {
+	return NewGenerationsRule("Star Wars B2/S345/C4", []int{2}, []int{3, 4, 5}, 4)
+}
+
+// Clouds3D returns the 3D "Clouds" rule (S13-26/B13-14,17-19, 2 states, Moore)
+// Random soups condense into solid cloud-like blobs
+func Clouds3D() *GenerationsRule {
+	survive := make([]int, 0, 14)
+	for n := 13; n <= 26; n++ {
+		survive = append(survive, n)
+	}
+	return NewGenerationsRule("Clouds (3D)", []int{13, 14, 17, 18, 19}, survive, 2)
+}
+
+// Rule445 returns the 3D Generations rule "445" (S4/B4, 5 states, Moore)
+func Rule445() *GenerationsRule {
+	return NewGenerationsRule("445 (3D Generations)", []int{4}, []int{4}, 5)
+}
+
+// Name returns the name of this rule
+func (r *GenerationsRule) Name() string {
+	return r.name
+}
+
+// States returns the total number of states, including Dead and Alive
+func (r *GenerationsRule) States() int {
+	return r.states
+}
+
+// ShouldBirth determines if a dead cell should become alive
+func (r *GenerationsRule) ShouldBirth(neighborCount int) bool {
+	return neighborCount >= 0 && neighborCount < len(r.birth) && r.birth[neighborCount]
+}
+
+// ShouldSurvive determines if a live cell should stay alive
+func (r *GenerationsRule) ShouldSurvive(neighborCount int, currentState core.CellState) bool {
+	if currentState == core.Dead {
+		return false
+	}
+	return neighborCount >= 0 && neighborCount < len(r.survive) && r.survive[neighborCount]
+}
+
+// NeighborWeight returns 1.0 for all neighbors (uniform weight)
+func (r *GenerationsRule) NeighborWeight(distance float64) float64 {
+	return 1.0
+}
+
+// Notation returns the rule in B/S/C notation, e.g. "B2/S345/C4".
+// Counts above 9 are written as comma-separated numbers and ranges, e.g. "S13-26".
+func (r *GenerationsRule) Notation() string {
+	s := "B" + formatCounts(r.birth) + "/S" + formatCounts(r.survive)
+	if r.states > 2 {
+		s += fmt.Sprintf("/C%d", r.states)
+	}
+	return s
+}
+
+// formatCounts formats a lookup table as a list of neighbor counts.
+// Single-digit tables are written digit by digit ("23"); wider tables as
+// ranges ("13-14,17"), which Parse reads back unambiguously.
+func formatCounts(table []bool) string {
+	if len(table) <= 10 {
+		var sb strings.Builder
+		for n, ok := range table {
+			if ok {
+				sb.WriteString(strconv.Itoa(n))
+			}
+		}
+		return sb.String()
+	}
+
+	var parts []string
+	for n := 0; n < len(table); n++ {
+		if !table[n] {
+			continue
+		}
+		start := n
+		for n+1 < len(table) && table[n+1] {
+			n++
+		}
+		if start == n {
+			parts = append(parts, strconv.Itoa(n))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, n))
+		}
+	}
+
+	s := strings.Join(parts, ",")
+	if !strings.ContainsAny(s, ",-") {
+		// A lone count such as 13 would read back as 1 and 3
+		s += "-" + s
+	}
+	return s
+}
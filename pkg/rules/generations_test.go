@@ -0,0 +1,84 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"testing"
+)
+
+func TestGenerationsRule_Named(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *GenerationsRule
+		states   int
+		notation string
+	}{
+		{"Brian's Brain", BriansBrain(), 3, "B2/S/C3"},
+		{"Star Wars", StarWars(), 4, "B2/S345/C4"},
+		{"Clouds 3D", Clouds3D(), 2, "B13-14,17-19/S13-26"},
+		{"445", Rule445(), 5, "B4/S4/C5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.States(); got != tt.states {
+				t.Errorf("States() = %d, want %d", got, tt.states)
+			}
+			if got := tt.rule.Notation(); got != tt.notation {
+				t.Errorf("Notation() = %q, want %q", got, tt.notation)
+			}
+			if tt.rule.Name() == "" {
+				t.Error("Name() should not be empty")
+			}
+		})
+	}
+}
+
+func TestGenerationsRule_ImplementsMultiStateRule(t *testing.T) {
+	var rule core.Rule = StarWars()
+	ms, ok := rule.(core.MultiStateRule)
+	if !ok {
+		t.Fatal("GenerationsRule should implement core.MultiStateRule")
+	}
+	if ms.States() != 4 {
+		t.Errorf("States() = %d, want 4", ms.States())
+	}
+}
+
+func TestGenerationsRule_BirthSurvive(t *testing.T) {
+	rule := StarWars()
+
+	for n := 0; n <= 8; n++ {
+		wantBirth := n == 2
+		if got := rule.ShouldBirth(n); got != wantBirth {
+			t.Errorf("ShouldBirth(%d) = %v, want %v", n, got, wantBirth)
+		}
+		wantSurvive := n >= 3 && n <= 5
+		if got := rule.ShouldSurvive(n, core.Alive); got != wantSurvive {
+			t.Errorf("ShouldSurvive(%d) = %v, want %v", n, got, wantSurvive)
+		}
+	}
+
+	// Out-of-range counts never match
+	if rule.ShouldBirth(-1) || rule.ShouldBirth(100) {
+		t.Error("ShouldBirth should be false for out-of-range counts")
+	}
+	if rule.ShouldSurvive(3, core.Dead) {
+		t.Error("ShouldSurvive should be false for dead cells")
+	}
+}
+
+func TestNewGenerationsRule_Defaults(t *testing.T) {
+	rule := NewGenerationsRule("", []int{3}, []int{2, 3}, 0)
+
+	if rule.States() != 2 {
+		t.Errorf("States() = %d, want 2 (clamped)", rule.States())
+	}
+	if rule.Name() != "B3/S23" {
+		t.Errorf("Name() = %q, want %q", rule.Name(), "B3/S23")
+	}
+
+	rule = NewGenerationsRule("", nil, nil, 1000)
+	if rule.States() != 255 {
+		t.Errorf("States() = %d, want 255 (clamped)", rule.States())
+	}
+}
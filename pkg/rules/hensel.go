@@ -0,0 +1,314 @@
+package rules
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// Neighbor bits of a core.MaskRule mask
+const (
+	nbNW uint8 = 1 << iota
+	nbN
+	nbNE
+	nbW
+	nbE
+	nbSW
+	nbS
+	nbSE
+)
+
+// henselLetters lists the configuration letters for each neighbor count, in
+// canonical order. Counts 5-8 use the letters of 8-n.
+var henselLetters = [9]string{"", "ce", "ceaikn", "ceaiknjqry", "ceaiknjqrytwz", "ceaiknjqry", "ceaikn", "ce", ""}
+
+// henselRepresentatives gives one neighborhood for each letter of counts 1-4.
+// The other members of a class are its rotations and reflections; counts 5-7
+// are the complements of counts 3-1.
+var henselRepresentatives = map[int]map[byte]uint8{
+	1: {'c': nbNW, 'e': nbN},
+	2: {
+		'c': nbNW | nbNE, 'e': nbN | nbW, 'a': nbNW | nbN,
+		'i': nbW | nbE, 'k': nbNW | nbE, 'n': nbNE | nbSW,
+	},
+	3: {
+		'c': nbNW | nbNE | nbSW, 'e': nbN | nbW | nbE, 'a': nbNW | nbN | nbW,
+		'i': nbNW | nbN | nbNE, 'k': nbN | nbE | nbSW, 'n': nbNW | nbNE | nbW,
+		'j': nbN | nbNE | nbW, 'q': nbN | nbNE | nbSW, 'r': nbNW | nbW | nbE,
+		'y': nbNW | nbE | nbSW,
+	},
+	4: {
+		'c': nbNW | nbNE | nbSW | nbSE, 'e': nbN | nbW | nbE | nbS, 'a': nbNW | nbN | nbNE | nbW,
+		'i': nbNW | nbNE | nbW | nbE, 'k': nbNW | nbN | nbE | nbSW, 'n': nbNW | nbN | nbNE | nbSW,
+		'j': nbN | nbW | nbE | nbSW, 'q': nbN | nbNE | nbE | nbSW, 'r': nbNW | nbN | nbW | nbE,
+		'y': nbNW | nbNE | nbE | nbSW, 't': nbNW | nbW | nbE | nbSW, 'w': nbN | nbNE | nbW | nbSW,
+		'z': nbNE | nbW | nbE | nbSW,
+	},
+}
+
+// henselClass maps every neighborhood mask to its configuration letter
+// (0 for counts 0 and 8, which have a single configuration)
+var henselClass = buildHenselClasses()
+
+// neighborOffsets lists the (dx, dy) offset of each mask bit
+var neighborOffsets = [8][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+// buildHenselClasses expands the representatives under the 8 symmetries of the square
+func buildHenselClasses() [256]byte {
+	var classes [256]byte
+	for count, letters := range henselRepresentatives {
+		for letter, mask := range letters {
+			for _, m := range symmetries(mask) {
+				classes[m] = letter
+				if count < 4 {
+					classes[^m] = letter // Complement: count 8-n shares the letter
+				}
+			}
+		}
+	}
+	return classes
+}
+
+// symmetries returns the 8 rotations and reflections of a mask
+func symmetries(mask uint8) []uint8 {
+	transforms := [8]func(x, y int) (int, int){
+		func(x, y int) (int, int) { return x, y },
+		func(x, y int) (int, int) { return -y, x },
+		func(x, y int) (int, int) { return -x, -y },
+		func(x, y int) (int, int) { return y, -x },
+		func(x, y int) (int, int) { return -x, y },
+		func(x, y int) (int, int) { return x, -y },
+		func(x, y int) (int, int) { return y, x },
+		func(x, y int) (int, int) { return -y, -x },
+	}
+
+	result := make([]uint8, 0, len(transforms))
+	for _, transform := range transforms {
+		var out uint8
+		for bit, offset := range neighborOffsets {
+			if mask&(1<<bit) == 0 {
+				continue
+			}
+			tx, ty := transform(offset[0], offset[1])
+			out |= maskBit(tx, ty)
+		}
+		result = append(result, out)
+	}
+	return result
+}
+
+// maskBit returns the mask bit for a neighbor offset
+func maskBit(dx, dy int) uint8 {
+	for bit, offset := range neighborOffsets {
+		if offset[0] == dx && offset[1] == dy {
+			return 1 << bit
+		}
+	}
+	return 0
+}
+
+// HenselRule implements an isotropic non-totalistic rule written in Hensel
+// notation, e.g. "B2-a/S12" or "B3/S2-i34q" (tlife). Each neighbor count may be
+// followed by letters selecting which arrangements of that many neighbors apply;
+// a '-' before the letters excludes them instead.
+//
+// HenselRule implements core.MaskRule. Universe2D evaluates it through a
+// 256-entry lookup table indexed by the neighborhood mask.
+type HenselRule struct {
+	name    string
+	birth   [256]bool
+	survive [256]bool
+	states  int
+}
+
+// Name returns the name of this rule
+func (r *HenselRule) Name() string {
+	return r.name
+}
+
+// States returns the total number of states, including Dead and Alive
+func (r *HenselRule) States() int {
+	return r.states
+}
+
+// ShouldBirthMask determines if a dead cell with the given live neighbors should become alive
+func (r *HenselRule) ShouldBirthMask(mask uint8) bool {
+	return r.birth[mask]
+}
+
+// ShouldSurviveMask determines if a live cell with the given live neighbors should stay alive
+func (r *HenselRule) ShouldSurviveMask(mask uint8) bool {
+	return r.survive[mask]
+}
+
+// ShouldBirth reports whether every arrangement of neighborCount neighbors gives birth.
+// It is used by universes that only provide neighbor counts.
+func (r *HenselRule) ShouldBirth(neighborCount int) bool {
+	return allOfCount(&r.birth, neighborCount)
+}
+
+// ShouldSurvive reports whether every arrangement of neighborCount neighbors survives.
+// It is used by universes that only provide neighbor counts.
+func (r *HenselRule) ShouldSurvive(neighborCount int, currentState core.CellState) bool {
+	if currentState == core.Dead {
+		return false
+	}
+	return allOfCount(&r.survive, neighborCount)
+}
+
+// NeighborWeight returns 1.0 for all neighbors (uniform weight)
+func (r *HenselRule) NeighborWeight(distance float64) float64 {
+	return 1.0
+}
+
+// Notation returns the rule in canonical Hensel notation
+func (r *HenselRule) Notation() string {
+	s := "B" + formatHensel(&r.birth) + "/S" + formatHensel(&r.survive)
+	if r.states > 2 {
+		s += fmt.Sprintf("/C%d", r.states)
+	}
+	return s
+}
+
+// allOfCount reports whether the table is set for every mask with n bits
+func allOfCount(table *[256]bool, n int) bool {
+	if n < 0 || n > 8 {
+		return false
+	}
+	for mask := 0; mask < 256; mask++ {
+		if bits.OnesCount8(uint8(mask)) == n && !table[mask] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseHenselSection parses the body of a B or S section such as "2-a3" or "2ae34"
+func parseHenselSection(body string) ([256]bool, error) {
+	var table [256]bool
+
+	for i := 0; i < len(body); {
+		c := body[i]
+		if c < '0' || c > '8' {
+			return table, fmt.Errorf("expected a neighbor count 0-8, found %q", c)
+		}
+		count := int(c - '0')
+		i++
+
+		negate := false
+		if i < len(body) && body[i] == '-' {
+			negate = true
+			i++
+		}
+
+		start := i
+		for i < len(body) && (body[i] < '0' || body[i] > '9') {
+			i++
+		}
+		letters := strings.ToLower(body[start:i])
+		if negate && letters == "" {
+			return table, fmt.Errorf("'-' after %d must be followed by letters", count)
+		}
+		for _, l := range letters {
+			if !strings.ContainsRune(henselLetters[count], l) {
+				return table, fmt.Errorf("letter %q is not valid for %d neighbors (valid: %q)", l, count, henselLetters[count])
+			}
+		}
+
+		for mask := 0; mask < 256; mask++ {
+			if bits.OnesCount8(uint8(mask)) != count {
+				continue
+			}
+			selected := letters == "" || strings.IndexByte(letters, henselClass[mask]) >= 0
+			if negate {
+				selected = !selected
+			}
+			if selected {
+				table[mask] = true
+			}
+		}
+	}
+	return table, nil
+}
+
+// formatHensel formats a table in canonical Hensel notation, listing for each
+// count either the included or the excluded letters, whichever is shorter
+func formatHensel(table *[256]bool) string {
+	var sb strings.Builder
+	for count := 0; count <= 8; count++ {
+		enabled := make(map[byte]bool)
+		for mask := 0; mask < 256; mask++ {
+			if bits.OnesCount8(uint8(mask)) == count && table[mask] {
+				enabled[henselClass[mask]] = true
+			}
+		}
+		if len(enabled) == 0 {
+			continue
+		}
+
+		var on, off strings.Builder
+		for i := 0; i < len(henselLetters[count]); i++ {
+			l := henselLetters[count][i]
+			if enabled[l] {
+				on.WriteByte(l)
+			} else {
+				off.WriteByte(l)
+			}
+		}
+
+		sb.WriteString(strconv.Itoa(count))
+		switch {
+		case off.Len() == 0:
+			// All configurations: the plain count
+		case on.Len() <= off.Len():
+			sb.WriteString(on.String())
+		default:
+			sb.WriteString("-" + off.String())
+		}
+	}
+	return sb.String()
+}
+
+// isHenselNotation reports whether a B/S rulestring uses configuration letters.
+// Sections with ',' are range lists and never Hensel.
+func isHenselNotation(s string) bool {
+	for _, part := range strings.Split(s, "/") {
+		if len(part) < 2 || strings.Contains(part, ",") {
+			continue
+		}
+		prefix := strings.ToUpper(part[:1])
+		if prefix != "B" && prefix != "S" {
+			continue
+		}
+		if strings.ContainsAny(strings.ToLower(part[1:]), "ceaiknjqrytwz") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHensel parses a B/S/C rulestring in Hensel notation
+func parseHensel(s, original string) (core.Rule, error) {
+	sections, err := splitSections(s, original)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &HenselRule{states: 2}
+	if r.birth, err = parseHenselSection(sections["B"]); err != nil {
+		return nil, fmt.Errorf("invalid rulestring %q: B section: %w", original, err)
+	}
+	if r.survive, err = parseHenselSection(sections["S"]); err != nil {
+		return nil, fmt.Errorf("invalid rulestring %q: S section: %w", original, err)
+	}
+	if body, ok := sections["C"]; ok {
+		if r.states, err = parseStates(body); err != nil {
+			return nil, fmt.Errorf("invalid rulestring %q: C section: %w", original, err)
+		}
+	}
+	r.name = r.Notation()
+	return r, nil
+}
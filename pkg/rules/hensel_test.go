@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"math/bits"
+	"strings"
+	"testing"
+)
+
+func TestHenselClasses_CoverEveryMask(t *testing.T) {
+	classes := make(map[int]map[byte]int) // count -> letter -> masks
+
+	for mask := 0; mask < 256; mask++ {
+		count := bits.OnesCount8(uint8(mask))
+		letter := henselClass[mask]
+		if count == 0 || count == 8 {
+			if letter != 0 {
+				t.Errorf("Mask %08b: counts 0 and 8 have no letter, got %q", mask, letter)
+			}
+			continue
+		}
+		if !strings.ContainsRune(henselLetters[count], rune(letter)) {
+			t.Fatalf("Mask %08b (%d neighbors) has invalid letter %q", mask, count, letter)
+		}
+		if classes[count] == nil {
+			classes[count] = make(map[byte]int)
+		}
+		classes[count][letter]++
+	}
+
+	// Every letter is used: 2 + 6 + 10 + 13 + 10 + 6 + 2 classes
+	for count := 1; count <= 7; count++ {
+		if got, want := len(classes[count]), len(henselLetters[count]); got != want {
+			t.Errorf("%d neighbors: %d classes, want %d", count, got, want)
+		}
+	}
+}
+
+func TestHenselClasses_AreIsotropic(t *testing.T) {
+	for mask := 0; mask < 256; mask++ {
+		for _, m := range symmetries(uint8(mask)) {
+			if henselClass[m] != henselClass[mask] {
+				t.Fatalf("Mask %08b and its symmetry %08b have different letters", mask, m)
+			}
+		}
+	}
+}
+
+func TestParse_Hensel(t *testing.T) {
+	tests := []struct {
+		input    string
+		notation string
+	}{
+		{"B2-a/S12", "B2-a/S12"},
+		{"tlife", "B3/S2-i34q"},
+		{"B2ce3/S23", "B2ce3/S23"},
+		{"b2cekin/s12", "B2-a/S12"},
+		{"B3/S2-ace3", "B3/S2ikn3"},
+		{"B2a/S/C3", "B2a/S/C3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			h, ok := rule.(*HenselRule)
+			if !ok {
+				t.Fatalf("Parse(%q) returned %T, want *HenselRule", tt.input, rule)
+			}
+			if got := h.Notation(); got != tt.notation {
+				t.Errorf("Notation() = %q, want %q", got, tt.notation)
+			}
+		})
+	}
+}
+
+func TestHenselRule_Masks(t *testing.T) {
+	rule, err := Parse("B2-a/S12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := rule.(*HenselRule)
+
+	if h.ShouldBirthMask(nbN | nbNE) {
+		t.Error("2a (adjacent pair) should not give birth")
+	}
+	if !h.ShouldBirthMask(nbN | nbS) {
+		t.Error("2i (opposite edges) should give birth")
+	}
+	if !h.ShouldSurviveMask(nbSE) || !h.ShouldSurviveMask(nbW|nbSE) {
+		t.Error("Any 1 or 2 neighbors should survive")
+	}
+	if h.ShouldSurviveMask(nbN | nbS | nbE) {
+		t.Error("3 neighbors should not survive")
+	}
+}
+
+func TestHenselRule_CountFallback(t *testing.T) {
+	rule, err := Parse("B2-a3/S12")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rule.ShouldBirth(2) {
+		t.Error("ShouldBirth(2) should be false when only some 2-neighbor arrangements give birth")
+	}
+	if !rule.ShouldBirth(3) {
+		t.Error("ShouldBirth(3) should be true when all 3-neighbor arrangements give birth")
+	}
+	if !rule.ShouldSurvive(1, core.Alive) || rule.ShouldSurvive(1, core.Dead) {
+		t.Error("ShouldSurvive(1) should hold only for live cells")
+	}
+}
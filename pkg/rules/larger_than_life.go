@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"fmt"
+
+	"golife/pkg/core"
+)
+
+// LargerThanLifeRule implements Larger than Life (LtL): a totalistic rule on a
+// range-R neighborhood where birth and survival are given as count intervals.
+// It is written in Golly notation as "R5,C0,M1,S34..58,B34..45,NM".
+//
+// The universe adopts the rule's neighborhood (core.NeighborhoodRule).
+// With StateCount > 2 the rule decays like a Generations rule.
+type LargerThanLifeRule struct {
+	Range         int                   // Neighborhood radius (R)
+	Shape         core.NeighborhoodType // core.Moore, core.VonNeumann or core.Circular (N)
+	StateCount    int                   // Number of states; 0 or 2 for two-state rules (C)
+	IncludeCenter bool                  // A live cell counts itself as a neighbor (M1)
+	BirthMin      int                   // Birth interval (B)
+	BirthMax      int
+	SurviveMin    int // Survival interval (S)
+	SurviveMax    int
+}
+
+// Bosco returns Bosco's Rule (R5,C0,M1,S34..58,B34..45,NM), which supports
+// the "bosco" spaceship
+func Bosco() LargerThanLifeRule {
+	return LargerThanLifeRule{
+		Range: 5, Shape: core.Moore, IncludeCenter: true,
+		BirthMin: 34, BirthMax: 45, SurviveMin: 34, SurviveMax: 58,
+	}
+}
+
+// Majority returns the Majority rule (R4,C0,M1,S41..81,B41..81,NM):
+// a cell takes the state held by the majority of its 9x9 block
+func Majority() LargerThanLifeRule {
+	return LargerThanLifeRule{
+		Range: 4, Shape: core.Moore, IncludeCenter: true,
+		BirthMin: 41, BirthMax: 81, SurviveMin: 41, SurviveMax: 81,
+	}
+}
+
+// Name returns the rule in Golly LtL notation
+func (r LargerThanLifeRule) Name() string {
+	middle := 0
+	if r.IncludeCenter {
+		middle = 1
+	}
+	states := r.StateCount
+	if states <= 2 {
+		states = 0
+	}
+	return fmt.Sprintf("R%d,C%d,M%d,S%d..%d,B%d..%d,N%s",
+		r.Range, states, middle, r.SurviveMin, r.SurviveMax, r.BirthMin, r.BirthMax, shapeLetter(r.Shape))
+}
+
+// Neighborhood returns the range-R neighborhood of the rule's shape
+func (r LargerThanLifeRule) Neighborhood() core.Neighborhood {
+	return core.Neighborhood{Type: r.Shape, Range: r.Range}
+}
+
+// States returns the total number of states, including Dead and Alive
+func (r LargerThanLifeRule) States() int {
+	if r.StateCount < 2 {
+		return 2
+	}
+	return r.StateCount
+}
+
+// ShouldBirth determines if a dead cell should become alive
+func (r LargerThanLifeRule) ShouldBirth(neighborCount int) bool {
+	return neighborCount >= r.BirthMin && neighborCount <= r.BirthMax
+}
+
+// ShouldSurvive determines if a live cell should stay alive
+func (r LargerThanLifeRule) ShouldSurvive(neighborCount int, currentState core.CellState) bool {
+	if currentState == core.Dead {
+		return false
+	}
+	if r.IncludeCenter {
+		neighborCount++ // The cell itself is part of its neighborhood
+	}
+	return neighborCount >= r.SurviveMin && neighborCount <= r.SurviveMax
+}
+
+// NeighborWeight returns 1.0 for all neighbors (uniform weight)
+func (r LargerThanLifeRule) NeighborWeight(distance float64) float64 {
+	return 1.0
+}
+
+// shapeLetter returns the Golly letter for a neighborhood shape
+func shapeLetter(shape core.NeighborhoodType) string {
+	switch shape {
+	case core.VonNeumann:
+		return "N"
+	case core.Circular:
+		return "C"
+	default:
+		return "M"
+	}
+}
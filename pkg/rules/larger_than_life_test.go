@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"testing"
+)
+
+func TestLargerThanLife_Name(t *testing.T) {
+	if got := Bosco().Name(); got != "R5,C0,M1,S34..58,B34..45,NM" {
+		t.Errorf("Bosco().Name() = %q", got)
+	}
+
+	rule := LargerThanLifeRule{Range: 3, Shape: core.Circular, StateCount: 4, BirthMin: 5, BirthMax: 7, SurviveMin: 4, SurviveMax: 9}
+	if got := rule.Name(); got != "R3,C4,M0,S4..9,B5..7,NC" {
+		t.Errorf("Name() = %q", got)
+	}
+}
+
+func TestLargerThanLife_Interfaces(t *testing.T) {
+	var rule core.Rule = Bosco()
+
+	nr, ok := rule.(core.NeighborhoodRule)
+	if !ok {
+		t.Fatal("LargerThanLifeRule should implement core.NeighborhoodRule")
+	}
+	n := nr.Neighborhood()
+	if n.Type != core.Moore || n.Range != 5 {
+		t.Errorf("Neighborhood() = %v R%d, want Moore R5", n.Type, n.Range)
+	}
+
+	ms, ok := rule.(core.MultiStateRule)
+	if !ok {
+		t.Fatal("LargerThanLifeRule should implement core.MultiStateRule")
+	}
+	if ms.States() != 2 {
+		t.Errorf("States() = %d, want 2", ms.States())
+	}
+}
+
+func TestLargerThanLife_BirthSurvive(t *testing.T) {
+	rule := Bosco()
+
+	tests := []struct {
+		count   int
+		birth   bool
+		survive bool
+	}{
+		{32, false, false},
+		{33, false, true}, // 33 neighbors + the cell itself = 34
+		{34, true, true},
+		{45, true, true},
+		{46, false, true},
+		{57, false, true},
+		{58, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := rule.ShouldBirth(tt.count); got != tt.birth {
+			t.Errorf("ShouldBirth(%d) = %v, want %v", tt.count, got, tt.birth)
+		}
+		if got := rule.ShouldSurvive(tt.count, core.Alive); got != tt.survive {
+			t.Errorf("ShouldSurvive(%d) = %v, want %v", tt.count, got, tt.survive)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package rules
+
+import "golife/pkg/core"
+
+// Life4D_B9S7_10 implements the 4D Life rule: B9/S7-10
+// This rule scales Conway's ratios to the 80-cell 4D Moore neighborhood.
+//
+// Birth: exactly 9 neighbors
+// Survival: 7, 8, 9, or 10 neighbors
+// Neighborhood: Moore (80 neighbors in 4D)
+//
+// References:
+// - "Higher Dimensional Games of Life" (ResearchGate)
+type Life4D_B9S7_10 struct{}
+
+// Name returns the name of this rule
+func (r Life4D_B9S7_10) Name() string {
+	return "B9/S7-10 (4D Life)"
+}
+
+// ShouldBirth determines if a dead cell should become alive
+// In B9/S7-10, birth occurs only when there are exactly 9 neighbors
+func (r Life4D_B9S7_10) ShouldBirth(neighborCount int) bool {
+	return neighborCount == 9
+}
+
+// ShouldSurvive determines if a live cell should stay alive
+// In B9/S7-10, survival occurs when there are 7 to 10 neighbors
+func (r Life4D_B9S7_10) ShouldSurvive(neighborCount int, currentState core.CellState) bool {
+	if currentState == core.Dead {
+		return false
+	}
+	return neighborCount >= 7 && neighborCount <= 10
+}
+
+// NeighborWeight returns 1.0 for all neighbors (uniform weight)
+func (r Life4D_B9S7_10) NeighborWeight(distance float64) float64 {
+	return 1.0
+}
@@ -0,0 +1,47 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"testing"
+)
+
+func TestLife4D_B9S7_10_Name(t *testing.T) {
+	rule := Life4D_B9S7_10{}
+	if name := rule.Name(); name != "B9/S7-10 (4D Life)" {
+		t.Errorf("Expected name 'B9/S7-10 (4D Life)', got '%s'", name)
+	}
+}
+
+func TestLife4D_B9S7_10_ShouldBirth(t *testing.T) {
+	rule := Life4D_B9S7_10{}
+
+	for n := 0; n <= 80; n++ {
+		want := n == 9
+		if got := rule.ShouldBirth(n); got != want {
+			t.Errorf("ShouldBirth(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestLife4D_B9S7_10_ShouldSurvive(t *testing.T) {
+	rule := Life4D_B9S7_10{}
+
+	for n := 0; n <= 80; n++ {
+		want := n >= 7 && n <= 10
+		if got := rule.ShouldSurvive(n, core.Alive); got != want {
+			t.Errorf("ShouldSurvive(%d) = %v, want %v", n, got, want)
+		}
+		if rule.ShouldSurvive(n, core.Dead) {
+			t.Errorf("Dead cell should not survive with %d neighbors", n)
+		}
+	}
+}
+
+func TestLife4D_B9S7_10_NeighborWeight(t *testing.T) {
+	rule := Life4D_B9S7_10{}
+	for _, dist := range []float64{0.0, 1.0, 1.414, 1.732, 2.0} {
+		if weight := rule.NeighborWeight(dist); weight != 1.0 {
+			t.Errorf("NeighborWeight(%f) = %f, want 1.0", dist, weight)
+		}
+	}
+}
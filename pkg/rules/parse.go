@@ -0,0 +1,331 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// maxNeighborCount bounds the neighbor counts accepted in a rulestring.
+// It is large enough for the 80-neighbor 4D Moore neighborhood and
+// extended-range neighborhoods.
+const maxNeighborCount = 1024
+
+// namedRules maps well-known rule names to their rulestrings
+var namedRules = map[string]string{
+	"life":         "B3/S23",
+	"conway":       "B3/S23",
+	"highlife":     "B36/S23",
+	"seeds":        "B2/S",
+	"day-night":    "B3678/S34678",
+	"brians-brain": "B2/S/C3",
+	"star-wars":    "B2/S345/C4",
+	"3d-life":      "5766",
+	"clouds":       "B13,14,17-19/S13-26",
+	"445":          "B4/S4/C5",
+	"bosco":        "R5,C0,M1,S34..58,B34..45,NM",
+	"majority":     "R4,C0,M1,S41..81,B41..81,NM",
+	"tlife":        "B3/S2-i34q",
+}
+
+// Parse parses a rulestring into a rule. Supported notations:
+//
+//   - B/S notation: "B3/S23", "S23/B3" (case-insensitive, either order)
+//   - Generations: "B2/S/C3" or "B2/S/G3" (C or G gives the number of states)
+//   - Bays' 3D notation: "5766" meaning survive 5-7, birth 6-6
+//   - Ranges: "B13-14,17-19/S13-26". When a count list contains ',' or '-'
+//     each item is a full number or range; otherwise each digit is one count.
+//   - Isotropic non-totalistic (Hensel): "B2-a/S12", "B3/S2-i34q" (letters after a count)
+//   - Larger than Life (Golly): "R5,C0,M1,S34..58,B34..45,NM" with shape NM
+//     (Moore), NN (von Neumann) or NC (circular)
+//   - Names: "life", "highlife", "brians-brain", "star-wars", "3d-life", "clouds", "445", "bosco", ...
+//   - Rule tables: "wireworld", "langtons-loops"
+//
+// Parse never reads files, so it is safe for untrusted input; load Golly
+// .rule and .table files with LoadRuleTable.
+//
+// Larger than Life rules are returned as a LargerThanLifeRule, Hensel rules as a
+// *HenselRule, rule tables as a *RuleTable and everything else as a *GenerationsRule; with 2 states it behaves
+// like an ordinary Life-like rule.
+func Parse(s string) (core.Rule, error) {
+	input := strings.TrimSpace(s)
+	if input == "" {
+		return nil, fmt.Errorf("empty rulestring")
+	}
+
+	if named, ok := namedRules[strings.ToLower(input)]; ok {
+		input = named
+	}
+	if _, ok := namedRuleTables[strings.ToLower(input)]; ok {
+		return loadBuiltinTable(input)
+	}
+	if ext := strings.ToLower(filepath.Ext(input)); ext == ".rule" || ext == ".table" {
+		return nil, fmt.Errorf("invalid rulestring %q: rule table files are loaded with LoadRuleTable", s)
+	}
+
+	if isBaysNotation(input) {
+		return parseBays(input)
+	}
+	if isLtLNotation(input) {
+		return parseLtL(input, s)
+	}
+	if isHenselNotation(input) {
+		return parseHensel(input, s)
+	}
+	return parseBS(input, s)
+}
+
+// isBaysNotation reports whether s is four digits, e.g. "5766"
+func isBaysNotation(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBays parses Bays' EsEuFlFu notation: a live cell survives with
+// El..Eu neighbors and a dead cell is born with Fl..Fu neighbors
+func parseBays(s string) (core.Rule, error) {
+	el, eu := int(s[0]-'0'), int(s[1]-'0')
+	fl, fu := int(s[2]-'0'), int(s[3]-'0')
+	if el > eu {
+		return nil, fmt.Errorf("invalid rulestring %q: survival range %d-%d is reversed", s, el, eu)
+	}
+	if fl > fu {
+		return nil, fmt.Errorf("invalid rulestring %q: birth range %d-%d is reversed", s, fl, fu)
+	}
+	return NewGenerationsRule("", countRange(fl, fu), countRange(el, eu), 2), nil
+}
+
+// parseBS parses B/S/C notation in any part order
+func parseBS(s, original string) (core.Rule, error) {
+	sections, err := splitSections(s, original)
+	if err != nil {
+		return nil, err
+	}
+
+	birth, err := parseCounts(sections["B"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid rulestring %q: B section: %w", original, err)
+	}
+	survive, err := parseCounts(sections["S"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid rulestring %q: S section: %w", original, err)
+	}
+	states := 2
+	if body, ok := sections["C"]; ok {
+		if states, err = parseStates(body); err != nil {
+			return nil, fmt.Errorf("invalid rulestring %q: C section: %w", original, err)
+		}
+	}
+
+	return NewGenerationsRule("", birth, survive, states), nil
+}
+
+// splitSections splits a B/S/C rulestring into its section bodies keyed by
+// "B", "S" and "C" (a G section is stored as "C"). B and S are required.
+func splitSections(s, original string) (map[string]string, error) {
+	sections := make(map[string]string, 3)
+
+	for _, part := range strings.Split(s, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid rulestring %q: empty section", original)
+		}
+
+		prefix, body := strings.ToUpper(part[:1]), part[1:]
+		switch prefix {
+		case "B", "S", "C":
+		case "G":
+			prefix = "C"
+		default:
+			return nil, fmt.Errorf("invalid rulestring %q: unexpected section %q (expected B, S or C)", original, part)
+		}
+		if _, dup := sections[prefix]; dup {
+			return nil, fmt.Errorf("invalid rulestring %q: duplicate %s section", original, prefix)
+		}
+		sections[prefix] = body
+	}
+
+	_, hasB := sections["B"]
+	_, hasS := sections["S"]
+	if !hasB || !hasS {
+		return nil, fmt.Errorf("invalid rulestring %q: both B and S sections are required", original)
+	}
+	return sections, nil
+}
+
+// isLtLNotation reports whether s looks like Golly's Larger than Life notation
+func isLtLNotation(s string) bool {
+	return (s[0] == 'R' || s[0] == 'r') && strings.Contains(s, ",")
+}
+
+// parseLtL parses Golly's Larger than Life notation "Rr,Cc,Mm,Ss..t,Bb..c,Nn"
+func parseLtL(s, original string) (core.Rule, error) {
+	rule := LargerThanLifeRule{Shape: core.Moore}
+	seen := make(map[byte]bool)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid rulestring %q: empty section", original)
+		}
+		key, body := strings.ToUpper(part[:1])[0], part[1:]
+		if seen[key] {
+			return nil, fmt.Errorf("invalid rulestring %q: duplicate %c section", original, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case 'R':
+			rule.Range, err = parseCount(body)
+			if err == nil && rule.Range < 1 {
+				err = fmt.Errorf("range must be at least 1")
+			}
+		case 'C':
+			if body == "0" {
+				rule.StateCount = 0 // Golly writes two-state rules as C0
+			} else {
+				rule.StateCount, err = parseStates(body)
+			}
+		case 'M':
+			switch body {
+			case "0":
+				rule.IncludeCenter = false
+			case "1":
+				rule.IncludeCenter = true
+			default:
+				err = fmt.Errorf("middle flag %q must be 0 or 1", body)
+			}
+		case 'S':
+			rule.SurviveMin, rule.SurviveMax, err = parseInterval(body)
+		case 'B':
+			rule.BirthMin, rule.BirthMax, err = parseInterval(body)
+		case 'N':
+			switch strings.ToUpper(body) {
+			case "M":
+				rule.Shape = core.Moore
+			case "N":
+				rule.Shape = core.VonNeumann
+			case "C":
+				rule.Shape = core.Circular
+			default:
+				err = fmt.Errorf("unknown neighborhood %q (expected M, N or C)", body)
+			}
+		default:
+			return nil, fmt.Errorf("invalid rulestring %q: unexpected section %q (expected R, C, M, S, B or N)", original, part)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid rulestring %q: %c section: %w", original, key, err)
+		}
+	}
+
+	for _, key := range []byte{'R', 'S', 'B'} {
+		if !seen[key] {
+			return nil, fmt.Errorf("invalid rulestring %q: missing %c section", original, key)
+		}
+	}
+	return rule, nil
+}
+
+// parseInterval parses a Larger than Life count interval "min..max"
+func parseInterval(body string) (int, int, error) {
+	lo, hi, ok := strings.Cut(body, "..")
+	if !ok {
+		return 0, 0, fmt.Errorf("interval %q must be written as min..max", body)
+	}
+	from, err := parseCount(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := parseCount(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("interval %q is reversed", body)
+	}
+	return from, to, nil
+}
+
+// parseCounts parses the neighbor counts of a B or S section.
+// Without ',' or '-' every digit is a separate count ("23" = 2 and 3);
+// otherwise items are comma-separated numbers or ranges ("13-14,17").
+func parseCounts(body string) ([]int, error) {
+	if body == "" {
+		return nil, nil
+	}
+
+	if !strings.ContainsAny(body, ",-") {
+		counts := make([]int, 0, len(body))
+		for _, c := range body {
+			if c < '0' || c > '9' {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			counts = append(counts, int(c-'0'))
+		}
+		return counts, nil
+	}
+
+	var counts []int
+	for _, item := range strings.Split(body, ",") {
+		lo, hi, isRange := strings.Cut(item, "-")
+		from, err := parseCount(lo)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseCount(hi); err != nil {
+				return nil, err
+			}
+			if from > to {
+				return nil, fmt.Errorf("range %q is reversed", item)
+			}
+		}
+		counts = append(counts, countRange(from, to)...)
+	}
+	return counts, nil
+}
+
+// parseCount parses a single neighbor count
+func parseCount(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a neighbor count", s)
+	}
+	if n < 0 || n > maxNeighborCount {
+		return 0, fmt.Errorf("neighbor count %d out of range 0-%d", n, maxNeighborCount)
+	}
+	return n, nil
+}
+
+// parseStates parses the state count of a C section
+func parseStates(body string) (int, error) {
+	states, err := strconv.Atoi(body)
+	if err != nil {
+		return 0, fmt.Errorf("state count %q is not a number", body)
+	}
+	if states < 2 || states > 255 {
+		return 0, fmt.Errorf("state count %d out of range 2-255", states)
+	}
+	return states, nil
+}
+
+// countRange returns the counts from..to inclusive
+func countRange(from, to int) []int {
+	counts := make([]int, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		counts = append(counts, n)
+	}
+	return counts
+}
@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"strings"
+	"testing"
+)
+
+func TestParse_Valid(t *testing.T) {
+	tests := []struct {
+		input    string
+		notation string
+		states   int
+	}{
+		{"B3/S23", "B3/S23", 2},
+		{"S23/B3", "B3/S23", 2},
+		{"b36/s23", "B36/S23", 2},
+		{"B2/S", "B2/S", 2},
+		{"B2/S/C3", "B2/S/C3", 3},
+		{"B2/S345/G4", "B2/S345/C4", 4},
+		{"5766", "B6/S567", 2},
+		{"4555", "B5/S45", 2},
+		{"B6/S5-7", "B6/S567", 2},
+		{"B13-14,17-19/S13-26", "B13-14,17-19/S13-26", 2},
+		{"B9/S7-10", "B9/S7-10", 2},
+		{" life ", "B3/S23", 2},
+		{"Brians-Brain", "B2/S/C3", 3},
+		{"3d-life", "B6/S567", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			g, ok := rule.(*GenerationsRule)
+			if !ok {
+				t.Fatalf("Parse(%q) returned %T, want *GenerationsRule", tt.input, rule)
+			}
+			if got := g.Notation(); got != tt.notation {
+				t.Errorf("Notation() = %q, want %q", got, tt.notation)
+			}
+			if got := g.States(); got != tt.states {
+				t.Errorf("States() = %d, want %d", got, tt.states)
+			}
+		})
+	}
+}
+
+func TestParse_MatchesBuiltinRules(t *testing.T) {
+	tests := []struct {
+		input    string
+		builtin  core.Rule
+		maxCount int
+	}{
+		{"B3/S23", ConwayRule{}, 8},
+		{"5766", Life3D_B6S567{}, 26},
+		{"B9/S7-10", Life4D_B9S7_10{}, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			for n := 0; n <= tt.maxCount; n++ {
+				if rule.ShouldBirth(n) != tt.builtin.ShouldBirth(n) {
+					t.Errorf("ShouldBirth(%d) differs from %s", n, tt.builtin.Name())
+				}
+				if rule.ShouldSurvive(n, core.Alive) != tt.builtin.ShouldSurvive(n, core.Alive) {
+					t.Errorf("ShouldSurvive(%d) differs from %s", n, tt.builtin.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestParse_NotationRoundTrip(t *testing.T) {
+	for _, rule := range []*GenerationsRule{
+		BriansBrain(), StarWars(), Clouds3D(), Rule445(),
+		NewGenerationsRule("", []int{13}, []int{2, 3}, 2),
+	} {
+		parsed, err := Parse(rule.Notation())
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", rule.Notation(), err)
+		}
+		if got := parsed.(*GenerationsRule).Notation(); got != rule.Notation() {
+			t.Errorf("Round trip of %q gave %q", rule.Notation(), got)
+		}
+	}
+}
+
+func TestParse_LargerThanLife(t *testing.T) {
+	rule, err := Parse("R5,C0,M1,S34..58,B34..45,NM")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if rule != Bosco() {
+		t.Errorf("Parse gave %+v, want Bosco %+v", rule, Bosco())
+	}
+
+	rule, err = Parse("r2,c3,m0,s1..4,b3..3,nc")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got := rule.Name(); got != "R2,C3,M0,S1..4,B3..3,NC" {
+		t.Errorf("Name() = %q", got)
+	}
+
+	if _, err := Parse("majority"); err != nil {
+		t.Errorf("Parse(\"majority\") returned error: %v", err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"", "empty rulestring"},
+		{"B3", "both B and S sections are required"},
+		{"B3/S23/B4", "duplicate B section"},
+		{"B3//S23", "empty section"},
+		{"X3/S23", "unexpected section"},
+		{"B3x/S23", "unexpected character"},
+		{"B3/S2,x", "not a neighbor count"},
+		{"B2x/S2a", "letter 'x' is not valid for 2 neighbors"},
+		{"B1k/S23", "letter 'k' is not valid for 1 neighbors"},
+		{"B2-/S2a", "must be followed by letters"},
+		{"B9a/S2", "expected a neighbor count 0-8"},
+		{"B3/S5-2", "reversed"},
+		{"B3/S2,5000", "out of range"},
+		{"B2/S/C1", "state count 1 out of range"},
+		{"B2/S/Cx", "not a number"},
+		{"7566", "survival range 7-5 is reversed"},
+		{"5776", "birth range 7-6 is reversed"},
+		{"R5,C0,M1,S34..58,NM", "missing B section"},
+		{"R0,S1..2,B1..2", "range must be at least 1"},
+		{"R2,S1-2,B1..2", "must be written as min..max"},
+		{"R2,S1..2,B1..2,NX", "unknown neighborhood"},
+		{"R2,S1..2,B1..2,M2", "middle flag"},
+		{"R2,S1..2,B1..2,Q1", "unexpected section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) should fail", tt.input)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
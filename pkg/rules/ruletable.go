@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"embed"
+	"fmt"
+	"image/color"
+	"math/bits"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// tableNeighborOffsets lists the neighbors of each rule table neighborhood in
+// Golly's order: clockwise starting from north
+var tableNeighborOffsets = map[core.NeighborhoodType][]core.Coord{
+	core.Moore: {
+		{X: 0, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 0}, {X: 1, Y: 1},
+		{X: 0, Y: 1}, {X: -1, Y: 1}, {X: -1, Y: 0}, {X: -1, Y: -1},
+	},
+	core.VonNeumann: {
+		{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0},
+	},
+}
+
+// builtinRuleTables holds the rule tables shipped with golife
+//
+//go:embed ruletables/*.rule
+var builtinRuleTables embed.FS
+
+// namedRuleTables maps rule names to the embedded rule table files
+var namedRuleTables = map[string]string{
+	"wireworld":      "ruletables/WireWorld.rule",
+	"langtons-loops": "ruletables/Langtons-Loops.rule",
+}
+
+// Wireworld returns Brian Silverman's Wireworld: 0 empty, 1 electron head,
+// 2 electron tail, 3 conductor
+func Wireworld() *RuleTable {
+	return mustLoadBuiltinTable("wireworld")
+}
+
+// LangtonsLoops returns Langton's self-reproducing loops (8 states, von Neumann neighborhood)
+func LangtonsLoops() *RuleTable {
+	return mustLoadBuiltinTable("langtons-loops")
+}
+
+// loadBuiltinTable parses an embedded rule table by name
+func loadBuiltinTable(name string) (*RuleTable, error) {
+	path, ok := namedRuleTables[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule table %q", name)
+	}
+	f, err := builtinRuleTables.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return ParseRuleTable(f, name)
+}
+
+// mustLoadBuiltinTable parses an embedded rule table, which is known to be valid
+func mustLoadBuiltinTable(name string) *RuleTable {
+	t, err := loadBuiltinTable(name)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// RuleTable implements a multi-state rule defined by a Golly rule table
+// (.rule or .table file), such as Wireworld or Langton's loops.
+//
+// Each transition lists the states a cell and its neighbors must have and the
+// state the cell changes to. Transitions are tried in file order; a cell that
+// matches none keeps its state. Cell states are state indices 0..NumStates()-1.
+//
+// RuleTable implements core.TransitionRule. Universe2D evaluates it directly;
+// matching uses one bitset of transitions per (position, state) pair, so a
+// lookup is a handful of ANDs however many transitions the table has.
+type RuleTable struct {
+	name         string
+	states       int
+	neighborhood core.NeighborhoodType
+	symmetry     string
+	colors       []color.RGBA
+
+	// lut[(position*states+state)*words : ...] is the bitset of transitions
+	// accepting state at position; position 0 is the cell itself
+	lut     []uint64
+	words   int
+	outputs []core.CellState
+}
+
+// Name returns the name of this rule
+func (r *RuleTable) Name() string {
+	return r.name
+}
+
+// NumStates returns the number of states, including the empty state 0
+func (r *RuleTable) NumStates() int {
+	return r.states
+}
+
+// NeighborOffsets returns the neighbor offsets in Golly's order
+// (N, NE, E, SE, S, SW, W, NW for Moore; N, E, S, W for von Neumann)
+func (r *RuleTable) NeighborOffsets() []core.Coord {
+	return append([]core.Coord(nil), tableNeighborOffsets[r.neighborhood]...)
+}
+
+// NeighborhoodType returns the neighborhood the table is written for
+func (r *RuleTable) NeighborhoodType() core.NeighborhoodType {
+	return r.neighborhood
+}
+
+// Symmetry returns the symmetries the transitions were expanded with
+func (r *RuleTable) Symmetry() string {
+	return r.symmetry
+}
+
+// Transitions returns the number of transitions after expanding variables and symmetries
+func (r *RuleTable) Transitions() int {
+	return len(r.outputs)
+}
+
+// Colors returns the display color of each state, from the @COLORS section
+// or a default palette
+func (r *RuleTable) Colors() []color.RGBA {
+	return append([]color.RGBA(nil), r.colors...)
+}
+
+// Next returns the output of the first transition matching the cell and its
+// neighbors, or the current state when none matches
+func (r *RuleTable) Next(current core.CellState, neighbors []core.CellState) core.CellState {
+	if int(current) >= r.states || len(neighbors) != len(tableNeighborOffsets[r.neighborhood]) {
+		return current
+	}
+	for _, n := range neighbors {
+		if int(n) >= r.states {
+			return current
+		}
+	}
+
+	stride := r.states * r.words
+	for w := 0; w < r.words; w++ {
+		match := r.lut[int(current)*r.words+w]
+		for i := 0; i < len(neighbors) && match != 0; i++ {
+			match &= r.lut[(i+1)*stride+int(neighbors[i])*r.words+w]
+		}
+		if match != 0 {
+			return r.outputs[w*64+bits.TrailingZeros64(match)]
+		}
+	}
+	return current
+}
+
+// ShouldBirth always returns false: a rule table cannot be reduced to neighbor
+// counts, so universes without rule table support leave its cells empty
+func (r *RuleTable) ShouldBirth(neighborCount int) bool {
+	return false
+}
+
+// ShouldSurvive always returns false (see ShouldBirth)
+func (r *RuleTable) ShouldSurvive(neighborCount int, currentState core.CellState) bool {
+	return false
+}
+
+// NeighborWeight returns 1.0 for all neighbors (uniform weight)
+func (r *RuleTable) NeighborWeight(distance float64) float64 {
+	return 1.0
+}
+
+// defaultTableColors returns Golly's default palette: empty cells are black
+// and live states shade from red to yellow
+func defaultTableColors(states int) []color.RGBA {
+	colors := make([]color.RGBA, states)
+	colors[0] = color.RGBA{A: 255}
+	if states == 2 {
+		colors[1] = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+		return colors
+	}
+	for s := 1; s < states; s++ {
+		g := uint8(255 * (s - 1) / (states - 2))
+		colors[s] = color.RGBA{R: 255, G: g, A: 255}
+	}
+	return colors
+}
@@ -0,0 +1,517 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golife/pkg/core"
+)
+
+// stateSet is a set of rule table states (up to 256)
+type stateSet [4]uint64
+
+func (s *stateSet) add(state int) {
+	s[state>>6] |= 1 << (state & 63)
+}
+
+func (s *stateSet) has(state int) bool {
+	return s[state>>6]&(1<<(state&63)) != 0
+}
+
+// members returns the states of the set in increasing order
+func (s *stateSet) members() []int {
+	var states []int
+	for state := 0; state < 256; state++ {
+		if s.has(state) {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// tableTransition is a transition with its variables bound: the states
+// accepted for the cell (index 0) and each neighbor, and the resulting state
+type tableTransition struct {
+	inputs []stateSet
+	output core.CellState
+}
+
+// tableParser accumulates the contents of a rule table file
+type tableParser struct {
+	name         string
+	states       int
+	neighborhood core.NeighborhoodType
+	hasNeighbors bool
+	symmetry     string
+	vars         map[string]stateSet
+	transitions  []tableTransition
+	colorLines   [][]int
+	colorLineNos []int
+}
+
+// LoadRuleTable loads a Golly rule table from a .rule or .table file.
+// A bare .table file is named after the file.
+func LoadRuleTable(path string) (*RuleTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseRuleTable(f, name)
+}
+
+// ParseRuleTable parses a rule table in Golly's .rule format (an @RULE line,
+// an @TABLE section and an optional @COLORS section) or a bare .table file.
+// name is used when the input has no @RULE line.
+//
+// The table header sets n_states (2-256), neighborhood (Moore or vonNeumann)
+// and symmetries (none, rotate4, rotate8, reflect, rotate4reflect,
+// rotate8reflect or permute). Variables are declared as "var a={0,1,2}";
+// a variable used more than once in a transition is bound to the same state
+// at every position. Transitions list the cell, its neighbors clockwise from
+// north and the new state, comma-separated or as single digits ("01234").
+// Other sections (@ICONS, @NAMES) are ignored.
+func ParseRuleTable(r io.Reader, name string) (*RuleTable, error) {
+	p := &tableParser{name: name, symmetry: "none", vars: make(map[string]stateSet)}
+
+	section := "table" // A bare .table file has no section headers
+	sawTable := false
+	bare := true
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			bare = false
+			keyword, rest, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(keyword) {
+			case "@RULE":
+				if rest = strings.TrimSpace(rest); rest != "" {
+					p.name = rest
+				}
+				section = "rule"
+			case "@TABLE":
+				section = "table"
+				sawTable = true
+			case "@COLORS":
+				section = "colors"
+			case "@TREE":
+				return nil, fmt.Errorf("invalid rule table %q: line %d: @TREE rule trees are not supported", p.name, lineNo)
+			default:
+				section = "other"
+			}
+			continue
+		}
+
+		var err error
+		switch section {
+		case "table":
+			err = p.tableLine(line)
+		case "colors":
+			err = p.colorLine(line, lineNo)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule table %q: line %d: %w", p.name, lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !bare && !sawTable {
+		return nil, fmt.Errorf("invalid rule table %q: missing @TABLE section", p.name)
+	}
+	if p.states == 0 {
+		return nil, fmt.Errorf("invalid rule table %q: n_states is required", p.name)
+	}
+	return p.build()
+}
+
+// tableLine handles one line of the @TABLE section
+func (p *tableParser) tableLine(line string) error {
+	if strings.HasPrefix(line, "var ") || strings.HasPrefix(line, "var\t") {
+		return p.parseVar(strings.TrimSpace(line[4:]))
+	}
+	if key, value, ok := strings.Cut(line, ":"); ok {
+		return p.parseHeader(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return p.parseTransition(line)
+}
+
+// parseHeader handles the n_states, neighborhood and symmetries lines
+func (p *tableParser) parseHeader(key, value string) error {
+	if len(p.transitions) > 0 {
+		return fmt.Errorf("%s must come before the transitions", key)
+	}
+
+	switch key {
+	case "n_states":
+		states, err := strconv.Atoi(value)
+		if err != nil || states < 2 || states > 256 {
+			return fmt.Errorf("n_states %q must be a number from 2 to 256", value)
+		}
+		p.states = states
+	case "neighborhood":
+		switch strings.ToLower(value) {
+		case "moore":
+			p.neighborhood = core.Moore
+		case "vonneumann":
+			p.neighborhood = core.VonNeumann
+		default:
+			return fmt.Errorf("unsupported neighborhood %q (expected Moore or vonNeumann)", value)
+		}
+		p.hasNeighbors = true
+	case "symmetries":
+		switch value {
+		case "none", "rotate4", "rotate8", "reflect", "rotate4reflect", "rotate8reflect", "permute":
+			p.symmetry = value
+		default:
+			return fmt.Errorf("unsupported symmetries %q", value)
+		}
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return nil
+}
+
+// parseVar handles "name={a,b,c}", where each item is a state or an earlier variable
+func (p *tableParser) parseVar(decl string) error {
+	if p.states == 0 {
+		return fmt.Errorf("n_states must be set before variables")
+	}
+	name, body, ok := strings.Cut(decl, "=")
+	name = strings.TrimSpace(name)
+	body = strings.TrimSpace(body)
+	if !ok || name == "" || !strings.HasPrefix(body, "{") || !strings.HasSuffix(body, "}") {
+		return fmt.Errorf("variable must be declared as var name={states}")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("variable name %q must not be a number", name)
+	}
+
+	var set stateSet
+	for _, item := range strings.Split(body[1:len(body)-1], ",") {
+		item = strings.TrimSpace(item)
+		if other, ok := p.vars[item]; ok {
+			for i := range set {
+				set[i] |= other[i]
+			}
+			continue
+		}
+		state, err := p.parseState(item)
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", name, err)
+		}
+		set.add(state)
+	}
+	p.vars[name] = set
+	return nil
+}
+
+// parseState parses a state index
+func (p *tableParser) parseState(s string) (int, error) {
+	state, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is neither a state nor a variable", s)
+	}
+	if state < 0 || state >= p.states {
+		return 0, fmt.Errorf("state %d out of range 0-%d", state, p.states-1)
+	}
+	return state, nil
+}
+
+// parseTransition expands a transition line over its bound variables and the
+// table's symmetries
+func (p *tableParser) parseTransition(line string) error {
+	if p.states == 0 || !p.hasNeighbors {
+		return fmt.Errorf("n_states and neighborhood must be set before the transitions")
+	}
+	neighbors := len(tableNeighborOffsets[p.neighborhood])
+
+	var tokens []string
+	switch {
+	case strings.Contains(line, ","):
+		tokens = strings.Split(line, ",")
+	case len(strings.Fields(line)) > 1:
+		tokens = strings.Fields(line)
+	default:
+		tokens = strings.Split(line, "")
+	}
+	if len(tokens) != neighbors+2 {
+		return fmt.Errorf("expected %d entries (cell, %d neighbors, new state), found %d", neighbors+2, neighbors, len(tokens))
+	}
+
+	// Variables that appear more than once are bound: every occurrence takes
+	// the same state, so the transition is expanded for each of its states
+	uses := make(map[string]int)
+	var bound []string
+	for i := range tokens {
+		tokens[i] = strings.TrimSpace(tokens[i])
+		if _, ok := p.vars[tokens[i]]; ok {
+			uses[tokens[i]]++
+			if uses[tokens[i]] == 2 {
+				bound = append(bound, tokens[i])
+			}
+		}
+	}
+
+	outputToken := tokens[len(tokens)-1]
+	if _, ok := p.vars[outputToken]; ok && uses[outputToken] < 2 {
+		return fmt.Errorf("new state variable %q must also appear in the inputs", outputToken)
+	}
+
+	values := make(map[string]int, len(bound))
+	var expand func(i int) error
+	expand = func(i int) error {
+		if i < len(bound) {
+			set := p.vars[bound[i]]
+			for _, state := range set.members() {
+				values[bound[i]] = state
+				if err := expand(i + 1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		inputs := make([]stateSet, neighbors+1)
+		for j, token := range tokens[:neighbors+1] {
+			if state, ok := values[token]; ok {
+				inputs[j].add(state)
+			} else if set, ok := p.vars[token]; ok {
+				inputs[j] = set
+			} else {
+				state, err := p.parseState(token)
+				if err != nil {
+					return err
+				}
+				inputs[j].add(state)
+			}
+		}
+
+		output, ok := values[outputToken]
+		if !ok {
+			var err error
+			if output, err = p.parseState(outputToken); err != nil {
+				return err
+			}
+		}
+		p.addWithSymmetries(inputs, core.CellState(output))
+		return nil
+	}
+	return expand(0)
+}
+
+// addWithSymmetries appends a transition and its distinct symmetric variants
+func (p *tableParser) addWithSymmetries(inputs []stateSet, output core.CellState) {
+	seen := make(map[string]bool)
+	add := func(neighbors []stateSet) {
+		key := fmt.Sprint(neighbors)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		variant := append([]stateSet{inputs[0]}, neighbors...)
+		p.transitions = append(p.transitions, tableTransition{inputs: variant, output: output})
+	}
+
+	if p.symmetry == "permute" {
+		forEachPermutation(inputs[1:], add)
+		return
+	}
+	for _, perm := range symmetryPermutations(p.symmetry, len(inputs)-1) {
+		neighbors := make([]stateSet, len(perm))
+		for i, from := range perm {
+			neighbors[i] = inputs[1+from]
+		}
+		add(neighbors)
+	}
+}
+
+// symmetryPermutations returns the neighbor permutations of a symmetry for n
+// neighbors arranged clockwise; a quarter turn moves each neighbor n/4 places
+func symmetryPermutations(symmetry string, n int) [][]int {
+	rotations := func(step int, reflect bool) [][]int {
+		var perms [][]int
+		for k := 0; k < n; k += step {
+			perm := make([]int, n)
+			for i := range perm {
+				perm[i] = (i + k) % n
+				if reflect {
+					perm[i] = (n - perm[i]) % n
+				}
+			}
+			perms = append(perms, perm)
+		}
+		return perms
+	}
+
+	quarter := n / 4
+	switch symmetry {
+	case "rotate4":
+		return rotations(quarter, false)
+	case "rotate8":
+		return rotations(1, false)
+	case "reflect":
+		return append(rotations(n, false), rotations(n, true)...)
+	case "rotate4reflect":
+		return append(rotations(quarter, false), rotations(quarter, true)...)
+	case "rotate8reflect":
+		return append(rotations(1, false), rotations(1, true)...)
+	default:
+		return rotations(n, false) // none: identity only
+	}
+}
+
+// forEachPermutation calls fn with every distinct ordering of the sets
+func forEachPermutation(sets []stateSet, fn func([]stateSet)) {
+	// Number the distinct sets and walk the lexicographic permutations of the
+	// numbers, which visits each distinct ordering once
+	var distinct []stateSet
+	ids := make([]int, len(sets))
+	for i, set := range sets {
+		ids[i] = -1
+		for id, d := range distinct {
+			if d == set {
+				ids[i] = id
+			}
+		}
+		if ids[i] < 0 {
+			ids[i] = len(distinct)
+			distinct = append(distinct, set)
+		}
+	}
+	sort.Ints(ids)
+
+	for {
+		perm := make([]stateSet, len(ids))
+		for i, id := range ids {
+			perm[i] = distinct[id]
+		}
+		fn(perm)
+
+		i := len(ids) - 2
+		for i >= 0 && ids[i] >= ids[i+1] {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		j := len(ids) - 1
+		for ids[j] <= ids[i] {
+			j--
+		}
+		ids[i], ids[j] = ids[j], ids[i]
+		for a, b := i+1, len(ids)-1; a < b; a, b = a+1, b-1 {
+			ids[a], ids[b] = ids[b], ids[a]
+		}
+	}
+}
+
+// colorLine handles "state r g b", or "r1 g1 b1 r2 g2 b2" for a gradient over
+// the live states. Lines are applied once n_states is known.
+func (p *tableParser) colorLine(line string, lineNo int) error {
+	fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+	if len(fields) != 4 && len(fields) != 6 {
+		return fmt.Errorf("expected \"state r g b\" or \"r1 g1 b1 r2 g2 b2\"")
+	}
+	values := make([]int, len(fields))
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil || v < 0 || v > 255 {
+			return fmt.Errorf("color value %q must be a number from 0 to 255", f)
+		}
+		values[i] = v
+	}
+	p.colorLines = append(p.colorLines, values)
+	p.colorLineNos = append(p.colorLineNos, lineNo)
+	return nil
+}
+
+// build assembles the rule table and its lookup bitsets
+func (p *tableParser) build() (*RuleTable, error) {
+	if p.name == "" {
+		p.name = "RuleTable"
+	}
+	if !p.hasNeighbors {
+		return nil, fmt.Errorf("invalid rule table %q: neighborhood is required", p.name)
+	}
+	if p.symmetry == "rotate8" || p.symmetry == "rotate8reflect" {
+		if p.neighborhood != core.Moore {
+			return nil, fmt.Errorf("invalid rule table %q: symmetries %s need the Moore neighborhood", p.name, p.symmetry)
+		}
+	}
+
+	colors := defaultTableColors(p.states)
+	for i, values := range p.colorLines {
+		if len(values) == 6 {
+			for s := 1; s < p.states; s++ {
+				colors[s] = gradient(values, s, p.states)
+			}
+			continue
+		}
+		if values[0] >= p.states {
+			return nil, fmt.Errorf("invalid rule table %q: line %d: color for state %d out of range 0-%d",
+				p.name, p.colorLineNos[i], values[0], p.states-1)
+		}
+		colors[values[0]] = color.RGBA{R: uint8(values[1]), G: uint8(values[2]), B: uint8(values[3]), A: 255}
+	}
+
+	t := &RuleTable{
+		name:         p.name,
+		states:       p.states,
+		neighborhood: p.neighborhood,
+		symmetry:     p.symmetry,
+		colors:       colors,
+		words:        (len(p.transitions) + 63) / 64,
+		outputs:      make([]core.CellState, len(p.transitions)),
+	}
+	positions := len(tableNeighborOffsets[p.neighborhood]) + 1
+	t.lut = make([]uint64, positions*p.states*t.words)
+	for i, tr := range p.transitions {
+		t.outputs[i] = tr.output
+		for pos, set := range tr.inputs {
+			for state := 0; state < p.states; state++ {
+				if set.has(state) {
+					t.lut[(pos*p.states+state)*t.words+i/64] |= 1 << (i % 64)
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// gradient interpolates the color of state s between two endpoint colors
+func gradient(values []int, s, states int) color.RGBA {
+	mix := func(from, to int) uint8 {
+		if states <= 2 {
+			return uint8(from)
+		}
+		return uint8(from + (to-from)*(s-1)/(states-2))
+	}
+	return color.RGBA{
+		R: mix(values[0], values[3]),
+		G: mix(values[1], values[4]),
+		B: mix(values[2], values[5]),
+		A: 255,
+	}
+}
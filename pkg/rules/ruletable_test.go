@@ -0,0 +1,258 @@
+package rules
+
+import (
+	"golife/pkg/core"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuleTable_Wireworld(t *testing.T) {
+	r := Wireworld()
+
+	if r.Name() != "WireWorld" {
+		t.Errorf("Name() = %q, want WireWorld", r.Name())
+	}
+	if r.NumStates() != 4 {
+		t.Errorf("NumStates() = %d, want 4", r.NumStates())
+	}
+	// 1 + 1 + 8 arrangements of one head + 28 arrangements of two heads
+	if r.Transitions() != 38 {
+		t.Errorf("Transitions() = %d, want 38", r.Transitions())
+	}
+
+	tests := []struct {
+		name      string
+		current   core.CellState
+		neighbors []core.CellState
+		want      core.CellState
+	}{
+		{"head becomes tail", 1, []core.CellState{3, 3, 0, 0, 0, 0, 0, 3}, 2},
+		{"tail becomes conductor", 2, []core.CellState{1, 0, 0, 0, 0, 0, 0, 0}, 3},
+		{"conductor with one head", 3, []core.CellState{0, 0, 0, 0, 0, 2, 1, 3}, 1},
+		{"conductor with two heads", 3, []core.CellState{1, 0, 0, 0, 1, 0, 0, 0}, 1},
+		{"conductor with three heads", 3, []core.CellState{1, 0, 1, 0, 1, 0, 0, 0}, 3},
+		{"empty stays empty", 0, []core.CellState{1, 1, 0, 0, 0, 0, 0, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := r.Next(tt.current, tt.neighbors); got != tt.want {
+			t.Errorf("%s: Next = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	colors := r.Colors()
+	if colors[3] != (color.RGBA{R: 255, G: 128, A: 255}) {
+		t.Errorf("Conductor color = %v, want the @COLORS value", colors[3])
+	}
+}
+
+func TestRuleTable_LangtonsLoopsRotations(t *testing.T) {
+	r := LangtonsLoops()
+
+	if r.NumStates() != 8 || r.NeighborhoodType() != core.VonNeumann || r.Symmetry() != "rotate4" {
+		t.Fatalf("Unexpected header: %d states, %s, %s", r.NumStates(), r.NeighborhoodType(), r.Symmetry())
+	}
+	if len(r.NeighborOffsets()) != 4 {
+		t.Errorf("Expected 4 neighbor offsets, got %d", len(r.NeighborOffsets()))
+	}
+
+	// 012321: C=0 N=1 E=2 S=3 W=2 -> 1, and each quarter turn of it
+	neighbors := []core.CellState{1, 2, 3, 2}
+	for turn := 0; turn < 4; turn++ {
+		if got := r.Next(0, neighbors); got != 1 {
+			t.Errorf("Rotation %d of 012321: Next = %d, want 1", turn, got)
+		}
+		neighbors = append(neighbors[1:], neighbors[0])
+	}
+
+	// No transition matches: the cell keeps its state
+	if got := r.Next(7, []core.CellState{7, 7, 7, 7}); got != 7 {
+		t.Errorf("Unmatched cell should keep its state, got %d", got)
+	}
+}
+
+func TestParseRuleTable_BoundVariables(t *testing.T) {
+	table := `n_states:3
+neighborhood:vonNeumann
+symmetries:none
+var a={1,2}
+var b={0,1,2}
+0,a,a,b,0,a
+`
+	r, err := ParseRuleTable(strings.NewReader(table), "bound")
+	if err != nil {
+		t.Fatalf("ParseRuleTable returned error: %v", err)
+	}
+	if r.Name() != "bound" {
+		t.Errorf("Name() = %q, want the name passed in", r.Name())
+	}
+	// a is bound (2 values); b appears once and stays a set
+	if r.Transitions() != 2 {
+		t.Errorf("Transitions() = %d, want 2", r.Transitions())
+	}
+
+	tests := []struct {
+		neighbors []core.CellState
+		want      core.CellState
+	}{
+		{[]core.CellState{1, 1, 0, 0}, 1},
+		{[]core.CellState{2, 2, 2, 0}, 2},
+		{[]core.CellState{1, 2, 0, 0}, 0}, // a must take one value
+		{[]core.CellState{1, 1, 0, 1}, 0},
+	}
+	for _, tt := range tests {
+		if got := r.Next(0, tt.neighbors); got != tt.want {
+			t.Errorf("Next(0, %v) = %d, want %d", tt.neighbors, got, tt.want)
+		}
+	}
+}
+
+func TestParseRuleTable_Symmetries(t *testing.T) {
+	tests := []struct {
+		neighborhood string
+		symmetry     string
+		transition   string
+		want         int
+	}{
+		{"vonNeumann", "none", "1,2,3,0,0,0", 1},
+		{"vonNeumann", "rotate4", "1,2,3,0,0,0", 4},
+		{"vonNeumann", "reflect", "1,2,3,0,0,0", 2},
+		{"vonNeumann", "rotate4reflect", "1,2,3,0,0,0", 8},
+		{"vonNeumann", "permute", "1,2,3,0,0,0", 12},
+		{"vonNeumann", "rotate4", "1,2,2,2,2,0", 1},
+		{"Moore", "rotate4", "1,2,0,0,0,0,0,0,0,0", 4},
+		{"Moore", "rotate8", "1,2,0,0,0,0,0,0,0,0", 8},
+		{"Moore", "rotate8reflect", "1,2,3,0,0,0,0,0,0,0", 16},
+		{"Moore", "permute", "1,2,2,0,0,0,0,0,0,0", 28},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.neighborhood+"/"+tt.symmetry, func(t *testing.T) {
+			table := "n_states:4\nneighborhood:" + tt.neighborhood + "\nsymmetries:" + tt.symmetry + "\n" + tt.transition + "\n"
+			r, err := ParseRuleTable(strings.NewReader(table), "sym")
+			if err != nil {
+				t.Fatalf("ParseRuleTable returned error: %v", err)
+			}
+			if r.Transitions() != tt.want {
+				t.Errorf("Transitions() = %d, want %d", r.Transitions(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleTable_Colors(t *testing.T) {
+	table := `@RULE Shaded
+@TABLE
+n_states:4
+neighborhood:Moore
+@COLORS
+200 200 0 0 0 255
+1 1 2 3
+`
+	r, err := ParseRuleTable(strings.NewReader(table), "")
+	if err != nil {
+		t.Fatalf("ParseRuleTable returned error: %v", err)
+	}
+	if r.Name() != "Shaded" {
+		t.Errorf("Name() = %q, want the @RULE name", r.Name())
+	}
+
+	colors := r.Colors()
+	want := []color.RGBA{
+		{A: 255},
+		{R: 1, G: 2, B: 3, A: 255},
+		{R: 100, G: 100, B: 127, A: 255},
+		{R: 0, G: 0, B: 255, A: 255},
+	}
+	for s := range want {
+		if colors[s] != want[s] {
+			t.Errorf("Color of state %d = %v, want %v", s, colors[s], want[s])
+		}
+	}
+
+	r, err = ParseRuleTable(strings.NewReader("n_states:3\nneighborhood:Moore\n"), "plain")
+	if err != nil {
+		t.Fatalf("ParseRuleTable returned error: %v", err)
+	}
+	if got := r.Colors(); got[1] != (color.RGBA{R: 255, A: 255}) || got[2] != (color.RGBA{R: 255, G: 255, A: 255}) {
+		t.Errorf("Default palette should shade from red to yellow, got %v", got)
+	}
+}
+
+func TestLoadRuleTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Flip.table")
+	table := "n_states:2\nneighborhood:vonNeumann\nsymmetries:permute\n0,1,0,0,0,1\n1,0,0,0,0,0\n"
+	if err := os.WriteFile(path, []byte(table), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := LoadRuleTable(path)
+	if err != nil {
+		t.Fatalf("LoadRuleTable returned error: %v", err)
+	}
+	if r.Name() != "Flip" {
+		t.Errorf("Name() = %q, want the file name", r.Name())
+	}
+
+	if _, err := Parse(path); err == nil {
+		t.Errorf("Parse(%q) should not read files", path)
+	}
+
+	if _, err := LoadRuleTable(filepath.Join(t.TempDir(), "missing.rule")); err == nil {
+		t.Error("Loading a missing file should fail")
+	}
+}
+
+func TestParse_NamedRuleTables(t *testing.T) {
+	for _, name := range []string{"wireworld", "Langtons-Loops"} {
+		rule, err := Parse(name)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", name, err)
+		}
+		if _, ok := rule.(core.TransitionRule); !ok {
+			t.Errorf("Parse(%q) returned %T, want a core.TransitionRule", name, rule)
+		}
+	}
+}
+
+func TestParseRuleTable_Errors(t *testing.T) {
+	header := "n_states:3\nneighborhood:vonNeumann\n"
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"no states", "neighborhood:Moore\n", "n_states is required"},
+		{"too many states", "n_states:300\n", "from 2 to 256"},
+		{"hexagonal", "n_states:2\nneighborhood:hexagonal\n", "unsupported neighborhood"},
+		{"bad symmetry", "n_states:2\nsymmetries:spin\n", "unsupported symmetries"},
+		{"unknown setting", "n_states:2\ncolors:3\n", "unknown setting"},
+		{"no neighborhood", "n_states:2\n", "neighborhood is required"},
+		{"transition first", "n_states:2\n0,0,0,0,0,0\n", "must be set before the transitions"},
+		{"entry count", header + "0,1,2,0\n", "expected 6 entries"},
+		{"state range", header + "0,1,2,0,0,5\n", "state 5 out of range"},
+		{"unknown variable", header + "0,x,0,0,0,1\n", "neither a state nor a variable"},
+		{"unbound output", header + "var a={1,2}\n0,a,0,0,0,b\n", "neither a state nor a variable"},
+		{"output only", header + "var a={1,2}\nvar b={1,2}\n0,a,0,0,0,b\n", "must also appear in the inputs"},
+		{"bad var", header + "var a=1,2\n", "var name={states}"},
+		{"rotate8 vonNeumann", header + "symmetries:rotate8\n", "need the Moore neighborhood"},
+		{"tree", "@RULE T\n@TREE\n", "not supported"},
+		{"no table", "@RULE T\n@COLORS\n0 0 0 0\n", "missing @TABLE section"},
+		{"color range", "@RULE T\n@TABLE\n" + header + "@COLORS\n5 1 2 3\n", "color for state 5"},
+		{"color value", "@RULE T\n@TABLE\n" + header + "@COLORS\n1 1 2 300\n", "from 0 to 255"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuleTable(strings.NewReader(tt.input), "test")
+			if err == nil {
+				t.Fatalf("ParseRuleTable should fail for %q", tt.input)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
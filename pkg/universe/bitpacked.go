@@ -0,0 +1,476 @@
+package universe
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+
+	"golife/pkg/core"
+)
+
+// BitUniverse2D is a 2D universe for two-state B/S rules that stores 64
+// cells per uint64 and computes each generation with bitwise adders, 64
+// cells at a time, instead of calling the rule for every cell.
+//
+// Each row carries one ghost column on either side and the grid one ghost
+// row above and below. Before a step the ghosts are filled from the
+// boundary topology, so every Boundary supported by Universe2D works here.
+type BitUniverse2D struct {
+	width, height int
+	stride        int      // Words per row, including the ghost columns
+	cells         []uint64 // (height+2) rows of stride words; cell (x, y) is bit x+1 of row y+1
+	nextCells     []uint64 // Double buffering
+	rowMask       []uint64 // Bits of a row word that hold real cells
+	born          []int32  // Generation each live cell was born in; nil when ages are off
+	generation    int32    // Generations stepped, the clock ages are measured against
+	rule          core.Rule
+	birth         [9]bool
+	survive       [9]bool
+	boundary      Boundary
+	workers       int        // Goroutines used by StepParallel; 0 means one per CPU
+	counts        stepCounts // Births and deaths of the last step
+}
+
+var _ core.Universe = (*BitUniverse2D)(nil)
+
+// NewBit2D creates a bit-packed 2D universe. It returns an error if the rule
+// is not a two-state B/S rule on the range-1 Moore neighborhood.
+func NewBit2D(width, height int, rule core.Rule) (*BitUniverse2D, error) {
+	if err := checkBitRule(rule); err != nil {
+		return nil, err
+	}
+
+	stride := (width + 2 + 63) / 64
+	u := &BitUniverse2D{
+		width:     width,
+		height:    height,
+		stride:    stride,
+		cells:     make([]uint64, (height+2)*stride),
+		nextCells: make([]uint64, (height+2)*stride),
+		rowMask:   make([]uint64, stride),
+		born:      make([]int32, width*height),
+		rule:      rule,
+	}
+	for x := 0; x < width; x++ {
+		u.rowMask[(x+1)/64] |= 1 << ((x + 1) % 64)
+	}
+	for n := 0; n <= 8; n++ {
+		u.birth[n] = rule.ShouldBirth(n)
+		u.survive[n] = rule.ShouldSurvive(n, core.Alive)
+	}
+	return u, nil
+}
+
+// checkBitRule reports why a rule cannot run bit-packed, if it cannot
+func checkBitRule(rule core.Rule) error {
+	if _, ok := rule.(core.TransitionRule); ok {
+		return fmt.Errorf("bit-packed universe does not support rule tables (%s)", rule.Name())
+	}
+	if _, ok := rule.(core.MaskRule); ok {
+		return fmt.Errorf("bit-packed universe does not support non-totalistic rules (%s)", rule.Name())
+	}
+	if ms, ok := rule.(core.MultiStateRule); ok && ms.States() > 2 {
+		return fmt.Errorf("bit-packed universe does not support multi-state rules (%s has %d states)", rule.Name(), ms.States())
+	}
+	if nr, ok := rule.(core.NeighborhoodRule); ok {
+		n := nr.Neighborhood()
+		if n.Type != core.Moore || n.Range != 1 {
+			return fmt.Errorf("bit-packed universe only supports the range-1 Moore neighborhood (%s uses %s range %d)", rule.Name(), n.Type, n.Range)
+		}
+	}
+	for _, o := range core.MooreNeighborhood(1).OffsetsFor(core.Dim2D) {
+		if rule.NeighborWeight(o.Distance()) != 1.0 {
+			return fmt.Errorf("bit-packed universe does not support weighted neighbors (%s)", rule.Name())
+		}
+	}
+	return nil
+}
+
+// Dimension returns the dimensionality (2D)
+func (u *BitUniverse2D) Dimension() core.Dimension {
+	return core.Dim2D
+}
+
+// bit returns the word index and bit mask of a cell, ghosts included
+func (u *BitUniverse2D) bit(x, y int) (int, uint64) {
+	return (y+1)*u.stride + (x+1)/64, 1 << ((x + 1) % 64)
+}
+
+// Get returns the state of a cell at the given coordinate
+func (u *BitUniverse2D) Get(coord core.Coord) core.CellState {
+	if coord.X < 0 || coord.X >= u.width || coord.Y < 0 || coord.Y >= u.height {
+		return core.Dead
+	}
+	i, mask := u.bit(coord.X, coord.Y)
+	if u.cells[i]&mask != 0 {
+		return core.Alive
+	}
+	return core.Dead
+}
+
+// Set sets the state of a cell at the given coordinate. Any state other
+// than core.Dead is stored as core.Alive.
+func (u *BitUniverse2D) Set(coord core.Coord, state core.CellState) {
+	if coord.X < 0 || coord.X >= u.width || coord.Y < 0 || coord.Y >= u.height {
+		return
+	}
+	i, mask := u.bit(coord.X, coord.Y)
+	switch {
+	case state == core.Dead:
+		u.cells[i] &^= mask
+	case u.cells[i]&mask == 0:
+		u.cells[i] |= mask
+		if u.born != nil {
+			u.born[coord.Y*u.width+coord.X] = u.generation + 1 // Age 0 until it survives a step
+		}
+	}
+}
+
+// Size returns the dimensions of the universe
+func (u *BitUniverse2D) Size() core.Coord {
+	return core.NewCoord2D(u.width, u.height)
+}
+
+// SetBoundary sets the boundary topology (the Z axis is ignored)
+func (u *BitUniverse2D) SetBoundary(b Boundary) {
+	u.boundary = b
+	if b.IsDead() {
+		// fillGhosts leaves dead ghosts alone, so clear what another
+		// topology left in them
+		u.clearGhosts(u.cells)
+		u.clearGhosts(u.nextCells)
+	}
+}
+
+// Boundary returns the boundary topology
+func (u *BitUniverse2D) Boundary() Boundary {
+	return u.boundary
+}
+
+// SetAgeTracking turns age tracking on or off. Ages cost time in proportion
+// to the number of births; with tracking off GetAge always returns 0.
+// Turning tracking on gives every live cell age 1.
+func (u *BitUniverse2D) SetAgeTracking(enabled bool) {
+	switch {
+	case enabled && u.born == nil:
+		u.born = make([]int32, u.width*u.height)
+		u.startAges()
+	case !enabled:
+		u.born = nil
+	}
+}
+
+// fillGhosts copies the cells beyond each edge into the ghost rows and
+// columns according to the boundary topology
+func (u *BitUniverse2D) fillGhosts() {
+	if u.boundary.IsDead() {
+		return // Ghosts are never set, so they stay dead
+	}
+	ghost := func(x, y int) {
+		i, mask := u.bit(x, y)
+		state := core.Dead
+		if rx, ry, _, outside, ok := u.boundary.resolve(x, y, 0, u.width, u.height, 1); !ok {
+			state = outside
+		} else {
+			state = u.Get(core.NewCoord2D(rx, ry))
+		}
+		if state == core.Dead {
+			u.cells[i] &^= mask
+		} else {
+			u.cells[i] |= mask
+		}
+	}
+	for y := 0; y < u.height; y++ {
+		ghost(-1, y)
+		ghost(u.width, y)
+	}
+	for x := -1; x <= u.width; x++ {
+		ghost(x, -1)
+		ghost(x, u.height)
+	}
+}
+
+// clearGhosts kills the ghost rows and columns of a cell buffer
+func (u *BitUniverse2D) clearGhosts(cells []uint64) {
+	for y := 0; y < u.height+2; y++ {
+		row := cells[y*u.stride : (y+1)*u.stride]
+		for j := range row {
+			if y == 0 || y == u.height+1 {
+				row[j] = 0
+			} else {
+				row[j] &= u.rowMask[j]
+			}
+		}
+	}
+}
+
+// Step executes one generation
+func (u *BitUniverse2D) Step() {
+	u.fillGhosts()
+	u.counts.reset()
+	u.stepRows(0, u.height)
+	u.cells, u.nextCells = u.nextCells, u.cells
+	u.generation++
+}
+
+// StepParallel executes one generation using parallel processing.
+// The grid is divided into bands of rows that are processed concurrently
+// by SetWorkers workers; the result is identical to Step.
+func (u *BitUniverse2D) StepParallel() {
+	u.fillGhosts()
+	u.counts.reset()
+	parallelBands(u.height, workerCount(u.workers, u.height), u.stepRows)
+	u.cells, u.nextCells = u.nextCells, u.cells
+	u.generation++
+}
+
+// SetWorkers sets the number of goroutines StepParallel uses.
+// 0, the default, uses one per CPU.
+func (u *BitUniverse2D) SetWorkers(n int) {
+	u.workers = n
+}
+
+// Births returns the number of dead cells that came alive in the last step
+func (u *BitUniverse2D) Births() int {
+	return int(u.counts.births.Load())
+}
+
+// Deaths returns the number of cells that died in the last step
+func (u *BitUniverse2D) Deaths() int {
+	return int(u.counts.deaths.Load())
+}
+
+// stepRows computes rows [yStart, yEnd) of the next generation into nextCells
+// and records the births
+func (u *BitUniverse2D) stepRows(yStart, yEnd int) {
+	s := u.stride
+	births, deaths := 0, 0
+	for y := yStart; y < yEnd; y++ {
+		up := u.cells[y*s : (y+1)*s]
+		mid := u.cells[(y+1)*s : (y+2)*s]
+		down := u.cells[(y+2)*s : (y+3)*s]
+		out := u.nextCells[(y+1)*s : (y+2)*s]
+
+		for j := 0; j < s; j++ {
+			uw, ue := shiftedNeighbors(up, j)
+			mw, me := shiftedNeighbors(mid, j)
+			dw, de := shiftedNeighbors(down, j)
+			alive := mid[j]
+
+			// Add the eight neighbor bits of all 64 cells at once
+			s0a, c0a := fullAdd(uw, up[j], ue)
+			s0b, c0b := fullAdd(mw, me, dw)
+			s0c, c0c := down[j]^de, down[j]&de
+			bit0, k := fullAdd(s0a, s0b, s0c)
+			t0, t1 := fullAdd(c0a, c0b, c0c)
+			bit1, t2 := t0^k, t0&k
+			bit2, bit3 := t1^t2, t1&t2
+
+			var next uint64
+			for n := 0; n <= 8; n++ {
+				if !u.birth[n] && !u.survive[n] {
+					continue
+				}
+				eq := selectBit(bit0, n&1) & selectBit(bit1, n&2) & selectBit(bit2, n&4) & selectBit(bit3, n&8)
+				switch {
+				case u.birth[n] && u.survive[n]:
+					next |= eq
+				case u.birth[n]:
+					next |= eq &^ alive
+				default:
+					next |= eq & alive
+				}
+			}
+			next &= u.rowMask[j]
+			out[j] = next
+			born := next &^ alive
+			births += bits.OnesCount64(born)
+			deaths += bits.OnesCount64(alive &^ next & u.rowMask[j])
+
+			if u.born != nil {
+				u.recordBirths(y, j, born)
+			}
+		}
+	}
+	u.counts.add(births, deaths)
+}
+
+// shiftedNeighbors returns the words holding the west and east neighbor of
+// every cell in word j of a row
+func shiftedNeighbors(row []uint64, j int) (west, east uint64) {
+	west, east = row[j]<<1, row[j]>>1
+	if j > 0 {
+		west |= row[j-1] >> 63
+	}
+	if j+1 < len(row) {
+		east |= row[j+1] << 63
+	}
+	return west, east
+}
+
+// fullAdd adds three bits in each position, returning the sum and carry bits
+func fullAdd(a, b, c uint64) (sum, carry uint64) {
+	ab := a ^ b
+	return ab ^ c, a&b | c&ab
+}
+
+// selectBit returns w where the count bit must be set, and ^w where it must not
+func selectBit(w uint64, want int) uint64 {
+	if want != 0 {
+		return w
+	}
+	return ^w
+}
+
+// recordBirths stamps the cells born in word j of row y with the generation
+// being computed. Survivors age by the clock alone, so only births cost time.
+func (u *BitUniverse2D) recordBirths(y, j int, births uint64) {
+	base := y*u.width + j*64 - 1 // Bit 1 of word 0 is x = 0
+	for w := births; w != 0; w &= w - 1 {
+		u.born[base+bits.TrailingZeros64(w)] = u.generation + 1
+	}
+}
+
+// Clone creates a deep copy of the universe
+func (u *BitUniverse2D) Clone() core.Universe {
+	clone, _ := NewBit2D(u.width, u.height, u.rule) // The rule was already checked
+	clone.boundary = u.boundary
+	clone.workers = u.workers
+	clone.generation = u.generation
+	copy(clone.cells, u.cells)
+	if u.born == nil {
+		clone.born = nil
+	} else {
+		copy(clone.born, u.born)
+	}
+	return clone
+}
+
+// Clear resets all cells to dead state
+func (u *BitUniverse2D) Clear() {
+	clear(u.cells)
+}
+
+// CountLiving returns the number of living cells
+func (u *BitUniverse2D) CountLiving() int {
+	count := 0
+	for y := 0; y < u.height; y++ {
+		row := u.cells[(y+1)*u.stride : (y+2)*u.stride]
+		for j, w := range row {
+			count += bits.OnesCount64(w & u.rowMask[j])
+		}
+	}
+	return count
+}
+
+// Randomize fills the universe with random cells at DefaultDensity, seeded
+// from the clock. Use RandomizeWith for reproducible fills.
+func (u *BitUniverse2D) Randomize() {
+	u.RandomizeWith(RandomOptions{Seed: time.Now().UnixNano()})
+}
+
+// RandomizeWith clears the universe and fills it with RandomFill.
+// Live cells start with age 1. It returns the number of live cells placed.
+func (u *BitUniverse2D) RandomizeWith(opts RandomOptions) int {
+	u.Clear()
+	placed := RandomFill(u, opts)
+	u.startAges()
+	return placed
+}
+
+// startAges gives every live cell age 1, as if it had just been born
+func (u *BitUniverse2D) startAges() {
+	if u.born == nil {
+		return
+	}
+	for y := 0; y < u.height; y++ {
+		for x := 0; x < u.width; x++ {
+			if u.Get(core.NewCoord2D(x, y)) != core.Dead {
+				u.born[y*u.width+x] = u.generation
+			}
+		}
+	}
+}
+
+// Width returns the width of the universe
+func (u *BitUniverse2D) Width() int {
+	return u.width
+}
+
+// Height returns the height of the universe
+func (u *BitUniverse2D) Height() int {
+	return u.height
+}
+
+// Rule returns the rule driving this universe
+func (u *BitUniverse2D) Rule() core.Rule {
+	return u.rule
+}
+
+// Bounds returns the inclusive bounding box of the live cells, found a word
+// at a time. ok is false if every cell is dead.
+func (u *BitUniverse2D) Bounds() (minC, maxC core.Coord, ok bool) {
+	minX, maxX, minY, maxY := u.width, -1, -1, -1
+	for y := 0; y < u.height; y++ {
+		row := u.cells[(y+1)*u.stride : (y+2)*u.stride]
+		first, last := -1, -1
+		for j, w := range row {
+			if w &= u.rowMask[j]; w != 0 {
+				if first < 0 {
+					first = j*64 + bits.TrailingZeros64(w) - 1
+				}
+				last = j*64 + 63 - bits.LeadingZeros64(w) - 1
+			}
+		}
+		if first < 0 {
+			continue
+		}
+		minX, maxX = min(minX, first), max(maxX, last)
+		if minY < 0 {
+			minY = y
+		}
+		maxY = y
+	}
+	if minY < 0 {
+		return core.Coord{}, core.Coord{}, false
+	}
+	return core.NewCoord2D(minX, minY), core.NewCoord2D(maxX, maxY), true
+}
+
+// AgeStats returns the mean and maximum age of the live cells that have an
+// age, as GetAge reports them; both are 0 when ages are off
+func (u *BitUniverse2D) AgeStats() (mean float64, maxAge int) {
+	if u.born == nil {
+		return 0, 0
+	}
+	// Sum the birth generations rather than the ages, which follow from them
+	bornSum, aged := 0, 0
+	oldest := u.generation
+	for y := 0; y < u.height; y++ {
+		row := u.cells[(y+1)*u.stride : (y+2)*u.stride]
+		for j, w := range row {
+			base := y*u.width + j*64 - 1 // Bit 1 of word 0 is x = 0
+			for w &= u.rowMask[j]; w != 0; w &= w - 1 {
+				b := u.born[base+bits.TrailingZeros64(w)]
+				if b <= u.generation { // Cells set since the last step have no age yet
+					bornSum += int(b)
+					aged++
+					oldest = min(oldest, b)
+				}
+			}
+		}
+	}
+	if aged == 0 {
+		return 0, 0
+	}
+	mean = float64(aged*(int(u.generation)+1)-bornSum) / float64(aged)
+	return mean, int(u.generation-oldest) + 1
+}
+
+// GetAge returns the age of a cell at the given coordinate
+func (u *BitUniverse2D) GetAge(x, y int) int {
+	if u.born == nil || u.Get(core.NewCoord2D(x, y)) == core.Dead {
+		return 0
+	}
+	return int(u.generation-u.born[y*u.width+x]) + 1
+}
@@ -0,0 +1,223 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+// newBitPair returns a Universe2D and a BitUniverse2D holding the same random soup
+func newBitPair(t testing.TB, width, height int, rule core.Rule, boundary Boundary) (*Universe2D, *BitUniverse2D) {
+	t.Helper()
+	grid := New2D(width, height, rule)
+	grid.SetBoundary(boundary)
+	grid.RandomizeWith(RandomOptions{Seed: 7, Density: 0.35})
+
+	packed, err := NewBit2D(width, height, rule)
+	if err != nil {
+		t.Fatalf("NewBit2D returned error: %v", err)
+	}
+	packed.SetBoundary(boundary)
+	packed.RandomizeWith(RandomOptions{Seed: 7, Density: 0.35})
+	return grid, packed
+}
+
+// assertSameCells fails if the two universes differ in any cell or age
+func assertSameCells(t *testing.T, grid *Universe2D, packed *BitUniverse2D, gen int) {
+	t.Helper()
+	for y := 0; y < grid.Height(); y++ {
+		for x := 0; x < grid.Width(); x++ {
+			c := core.NewCoord2D(x, y)
+			if grid.Get(c) != packed.Get(c) {
+				t.Fatalf("Generation %d: cell (%d,%d) is %d in Universe2D, %d bit-packed", gen, x, y, grid.Get(c), packed.Get(c))
+			}
+			if grid.GetAge(x, y) != packed.GetAge(x, y) {
+				t.Fatalf("Generation %d: age of (%d,%d) is %d in Universe2D, %d bit-packed", gen, x, y, grid.GetAge(x, y), packed.GetAge(x, y))
+			}
+		}
+	}
+	if grid.CountLiving() != packed.CountLiving() {
+		t.Fatalf("Generation %d: CountLiving %d vs %d", gen, grid.CountLiving(), packed.CountLiving())
+	}
+}
+
+func TestBitUniverse2D_MatchesUniverse2D(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		rule          string
+		boundary      Boundary
+	}{
+		{"conway dead", 130, 40, "B3/S23", Boundary{}},
+		{"conway torus", 128, 32, "B3/S23", TorusBoundary()},
+		{"highlife torus odd width", 67, 21, "B36/S23", TorusBoundary()},
+		{"day and night klein", 70, 30, "B3678/S34678", KleinBottleBoundary()},
+		{"seeds mirror", 63, 25, "B2/S", UniformBoundary(BoundaryMirror)},
+		{"life alive boundary", 65, 20, "B3/S23", UniformBoundary(BoundaryAlive)},
+		{"b0 rule", 40, 12, "B0/S8", Boundary{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := rules.Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.rule, err)
+			}
+			grid, packed := newBitPair(t, tt.width, tt.height, rule, tt.boundary)
+			assertSameCells(t, grid, packed, 0)
+
+			for gen := 1; gen <= 30; gen++ {
+				grid.Step()
+				packed.Step()
+				assertSameCells(t, grid, packed, gen)
+			}
+		})
+	}
+}
+
+func TestBitUniverse2D_StepParallelMatchesStep(t *testing.T) {
+	rule := rules.ConwayRule{}
+	_, serial := newBitPair(t, 300, 97, rule, TorusBoundary())
+	parallel := serial.Clone().(*BitUniverse2D)
+
+	for gen := 1; gen <= 20; gen++ {
+		serial.Step()
+		parallel.StepParallel()
+		for i := range serial.cells {
+			if serial.cells[i] != parallel.cells[i] {
+				t.Fatalf("Generation %d: word %d differs between Step and StepParallel", gen, i)
+			}
+		}
+	}
+}
+
+func TestBitUniverse2D_WrapThenDead(t *testing.T) {
+	packed, err := NewBit2D(16, 16, rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewBit2D returned error: %v", err)
+	}
+	packed.SetBoundary(TorusBoundary())
+	for _, c := range [][2]int{{14, 13}, {15, 14}, {13, 15}, {14, 15}, {15, 15}} {
+		packed.Set(core.NewCoord2D(c[0], c[1]), core.Alive) // Glider heading for the corner
+	}
+	for i := 0; i < 4; i++ {
+		packed.Step() // Wraps, leaving live cells in the ghosts of both buffers
+	}
+
+	// From here on the glider must break up at the edge as it does on a dead grid
+	packed.SetBoundary(Boundary{})
+	grid := New2D(16, 16, rules.ConwayRule{})
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			grid.Set(core.NewCoord2D(x, y), packed.Get(core.NewCoord2D(x, y)))
+		}
+	}
+	for gen := 1; gen <= 12; gen++ {
+		grid.Step()
+		packed.Step()
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				if c := core.NewCoord2D(x, y); grid.Get(c) != packed.Get(c) {
+					t.Fatalf("Generation %d: cell (%d,%d) is %d in Universe2D, %d bit-packed", gen, x, y, grid.Get(c), packed.Get(c))
+				}
+			}
+		}
+	}
+}
+
+func TestBitUniverse2D_GetSet(t *testing.T) {
+	u, err := NewBit2D(100, 10, rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewBit2D returned error: %v", err)
+	}
+
+	for _, x := range []int{0, 62, 63, 64, 99} {
+		u.Set(core.NewCoord2D(x, 3), core.Alive)
+	}
+	u.Set(core.NewCoord2D(100, 3), core.Alive) // Out of bounds: ignored
+	u.Set(core.NewCoord2D(-1, 3), core.Alive)
+
+	if u.CountLiving() != 5 {
+		t.Errorf("CountLiving() = %d, want 5", u.CountLiving())
+	}
+	if u.Get(core.NewCoord2D(63, 3)) != core.Alive || u.Get(core.NewCoord2D(61, 3)) != core.Dead {
+		t.Error("Get should read back the cells that were set")
+	}
+
+	u.Set(core.NewCoord2D(63, 3), core.Dead)
+	if u.Get(core.NewCoord2D(63, 3)) != core.Dead {
+		t.Error("Setting a cell dead should clear it")
+	}
+
+	u.Clear()
+	if u.CountLiving() != 0 {
+		t.Errorf("Clear should remove every cell, got %d", u.CountLiving())
+	}
+}
+
+func TestBitUniverse2D_AgeTrackingOff(t *testing.T) {
+	u, _ := NewBit2D(10, 10, rules.ConwayRule{})
+	u.SetAgeTracking(false)
+	for x := 3; x < 6; x++ {
+		u.Set(core.NewCoord2D(x, 4), core.Alive) // Blinker
+	}
+	u.Step()
+
+	if u.Get(core.NewCoord2D(4, 3)) != core.Alive || u.CountLiving() != 3 {
+		t.Error("Blinker should turn vertical with age tracking off")
+	}
+	if u.GetAge(4, 4) != 0 {
+		t.Errorf("GetAge should be 0 with tracking off, got %d", u.GetAge(4, 4))
+	}
+
+	u.SetAgeTracking(true)
+	if u.GetAge(4, 4) != 1 {
+		t.Errorf("Turning tracking back on should start live cells at age 1, got %d", u.GetAge(4, 4))
+	}
+}
+
+func TestNewBit2D_UnsupportedRules(t *testing.T) {
+	for _, name := range []string{"brians-brain", "bosco", "wireworld", "B3/S2-i34q"} {
+		rule, err := rules.Parse(name)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", name, err)
+		}
+		if _, err := NewBit2D(10, 10, rule); err == nil {
+			t.Errorf("NewBit2D should reject %s", name)
+		}
+	}
+}
+
+func BenchmarkUniverse2D_Step_4096(b *testing.B) {
+	grid, _ := newBitPair(b, 4096, 4096, rules.ConwayRule{}, Boundary{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grid.Step()
+	}
+}
+
+func BenchmarkBitUniverse2D_Step_4096(b *testing.B) {
+	_, packed := newBitPair(b, 4096, 4096, rules.ConwayRule{}, Boundary{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		packed.Step()
+	}
+}
+
+func BenchmarkBitUniverse2D_StepNoAges_4096(b *testing.B) {
+	_, packed := newBitPair(b, 4096, 4096, rules.ConwayRule{}, Boundary{})
+	packed.SetAgeTracking(false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		packed.Step()
+	}
+}
+
+func BenchmarkBitUniverse2D_StepParallel_4096(b *testing.B) {
+	_, packed := newBitPair(b, 4096, 4096, rules.ConwayRule{}, Boundary{})
+	packed.SetAgeTracking(false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		packed.StepParallel()
+	}
+}
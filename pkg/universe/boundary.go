@@ -0,0 +1,187 @@
+package universe
+
+import (
+	"fmt"
+	"golife/pkg/core"
+)
+
+// BoundaryMode defines how an axis behaves beyond the edge of the grid
+type BoundaryMode int
+
+const (
+	// BoundaryDead treats every cell outside the grid as dead (default)
+	BoundaryDead BoundaryMode = iota
+
+	// BoundaryAlive treats every cell outside the grid as alive
+	BoundaryAlive
+
+	// BoundaryWrap connects opposite edges (torus)
+	BoundaryWrap
+
+	// BoundaryMirror reflects the grid at its edges
+	BoundaryMirror
+
+	// BoundaryTwistedWrap connects opposite edges with a half twist:
+	// crossing an X edge mirrors Y, crossing a Y or Z edge mirrors X.
+	// Used for the Klein bottle and the projective plane.
+	BoundaryTwistedWrap
+)
+
+// String returns the string representation of the boundary mode
+func (m BoundaryMode) String() string {
+	switch m {
+	case BoundaryDead:
+		return "Dead"
+	case BoundaryAlive:
+		return "Alive"
+	case BoundaryWrap:
+		return "Wrap"
+	case BoundaryMirror:
+		return "Mirror"
+	case BoundaryTwistedWrap:
+		return "TwistedWrap"
+	default:
+		return "Unknown"
+	}
+}
+
+// Boundary holds the boundary mode for each axis.
+// The zero value is the classic "dead outside the grid" boundary.
+type Boundary struct {
+	X, Y, Z BoundaryMode
+}
+
+// UniformBoundary returns a boundary using the same mode on every axis
+func UniformBoundary(mode BoundaryMode) Boundary {
+	return Boundary{X: mode, Y: mode, Z: mode}
+}
+
+// TorusBoundary returns a boundary wrapping every axis
+func TorusBoundary() Boundary {
+	return UniformBoundary(BoundaryWrap)
+}
+
+// KleinBottleBoundary returns a boundary with a twisted X axis and wrapped Y/Z axes
+func KleinBottleBoundary() Boundary {
+	return Boundary{X: BoundaryTwistedWrap, Y: BoundaryWrap, Z: BoundaryWrap}
+}
+
+// ProjectivePlaneBoundary returns a boundary with twisted X and Y axes
+func ProjectivePlaneBoundary() Boundary {
+	return Boundary{X: BoundaryTwistedWrap, Y: BoundaryTwistedWrap, Z: BoundaryWrap}
+}
+
+// ParseBoundary returns the boundary for a topology name
+// Supported names: dead, alive, torus, klein, projective, mirror
+func ParseBoundary(name string) (Boundary, error) {
+	switch name {
+	case "", "dead":
+		return UniformBoundary(BoundaryDead), nil
+	case "alive":
+		return UniformBoundary(BoundaryAlive), nil
+	case "torus", "wrap":
+		return TorusBoundary(), nil
+	case "klein":
+		return KleinBottleBoundary(), nil
+	case "projective":
+		return ProjectivePlaneBoundary(), nil
+	case "mirror":
+		return UniformBoundary(BoundaryMirror), nil
+	default:
+		return Boundary{}, fmt.Errorf("unknown boundary '%s' (use dead, alive, torus, klein, projective or mirror)", name)
+	}
+}
+
+// IsDead reports whether every axis uses the default dead boundary
+func (b Boundary) IsDead() bool {
+	return b.X == BoundaryDead && b.Y == BoundaryDead && b.Z == BoundaryDead
+}
+
+// resolve maps a coordinate that may lie outside a width×height×depth grid onto the grid.
+// It returns ok=true with the in-grid coordinate, or ok=false with the constant
+// state of a cell beyond a Dead or Alive boundary.
+func (b Boundary) resolve(x, y, z, width, height, depth int) (rx, ry, rz int, outside core.CellState, ok bool) {
+	if x < 0 || x >= width {
+		switch b.X {
+		case BoundaryDead:
+			return 0, 0, 0, core.Dead, false
+		case BoundaryAlive:
+			return 0, 0, 0, core.Alive, false
+		case BoundaryWrap:
+			x = wrap(x, width)
+		case BoundaryMirror:
+			x = mirror(x, width)
+		case BoundaryTwistedWrap:
+			if (floorDiv(x, width) & 1) != 0 {
+				y = height - 1 - y
+			}
+			x = wrap(x, width)
+		}
+	}
+
+	if y < 0 || y >= height {
+		switch b.Y {
+		case BoundaryDead:
+			return 0, 0, 0, core.Dead, false
+		case BoundaryAlive:
+			return 0, 0, 0, core.Alive, false
+		case BoundaryWrap:
+			y = wrap(y, height)
+		case BoundaryMirror:
+			y = mirror(y, height)
+		case BoundaryTwistedWrap:
+			if (floorDiv(y, height) & 1) != 0 {
+				x = width - 1 - x
+			}
+			y = wrap(y, height)
+		}
+	}
+
+	if z < 0 || z >= depth {
+		switch b.Z {
+		case BoundaryDead:
+			return 0, 0, 0, core.Dead, false
+		case BoundaryAlive:
+			return 0, 0, 0, core.Alive, false
+		case BoundaryWrap:
+			z = wrap(z, depth)
+		case BoundaryMirror:
+			z = mirror(z, depth)
+		case BoundaryTwistedWrap:
+			if (floorDiv(z, depth) & 1) != 0 {
+				x = width - 1 - x
+			}
+			z = wrap(z, depth)
+		}
+	}
+
+	return x, y, z, core.Dead, true
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, n int) int {
+	q := a / n
+	if a%n != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// wrap maps a coordinate onto [0, n) periodically
+func wrap(a, n int) int {
+	a %= n
+	if a < 0 {
+		a += n
+	}
+	return a
+}
+
+// mirror maps a coordinate onto [0, n) by reflecting at the edges,
+// so that -1 maps to 0 and n maps to n-1
+func mirror(a, n int) int {
+	a = wrap(a, 2*n)
+	if a >= n {
+		a = 2*n - 1 - a
+	}
+	return a
+}
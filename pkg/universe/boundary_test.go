@@ -0,0 +1,208 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+func TestBoundary_Resolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		boundary  Boundary
+		x, y      int
+		wantX     int
+		wantY     int
+		wantOK    bool
+		wantState core.CellState
+	}{
+		{"dead", UniformBoundary(BoundaryDead), -1, 2, 0, 0, false, core.Dead},
+		{"alive", UniformBoundary(BoundaryAlive), 5, 2, 0, 0, false, core.Alive},
+		{"wrap left", TorusBoundary(), -1, 2, 4, 2, true, core.Dead},
+		{"wrap right", TorusBoundary(), 5, 2, 0, 2, true, core.Dead},
+		{"wrap corner", TorusBoundary(), -1, -1, 4, 3, true, core.Dead},
+		{"mirror left", UniformBoundary(BoundaryMirror), -1, 2, 0, 2, true, core.Dead},
+		{"mirror right", UniformBoundary(BoundaryMirror), 6, 2, 3, 2, true, core.Dead},
+		{"klein across x", KleinBottleBoundary(), 5, 1, 0, 2, true, core.Dead},
+		{"klein across y", KleinBottleBoundary(), 1, 4, 1, 0, true, core.Dead},
+		{"projective across y", ProjectivePlaneBoundary(), 1, 4, 3, 0, true, core.Dead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 5x4 grid
+			x, y, _, state, ok := tt.boundary.resolve(tt.x, tt.y, 0, 5, 4, 1)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				if state != tt.wantState {
+					t.Errorf("outside state = %d, want %d", state, tt.wantState)
+				}
+				return
+			}
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("resolved to (%d,%d), want (%d,%d)", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestParseBoundary(t *testing.T) {
+	valid := map[string]Boundary{
+		"dead":       UniformBoundary(BoundaryDead),
+		"alive":      UniformBoundary(BoundaryAlive),
+		"torus":      TorusBoundary(),
+		"klein":      KleinBottleBoundary(),
+		"projective": ProjectivePlaneBoundary(),
+		"mirror":     UniformBoundary(BoundaryMirror),
+	}
+	for name, want := range valid {
+		got, err := ParseBoundary(name)
+		if err != nil {
+			t.Errorf("ParseBoundary(%q) returned error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("ParseBoundary(%q) = %+v, want %+v", name, got, want)
+		}
+	}
+
+	if _, err := ParseBoundary("moebius"); err == nil {
+		t.Error("ParseBoundary should reject unknown names")
+	}
+}
+
+func TestUniverse2D_TorusGliderReturns(t *testing.T) {
+	const size = 8
+	u := New2D(size, size, rules.ConwayRule{})
+	u.SetBoundary(TorusBoundary())
+
+	glider := []core.Coord{
+		core.NewCoord2D(1, 0), core.NewCoord2D(2, 1),
+		core.NewCoord2D(0, 2), core.NewCoord2D(1, 2), core.NewCoord2D(2, 2),
+	}
+	for _, c := range glider {
+		u.Set(c, core.Alive)
+	}
+
+	// A glider moves one cell diagonally every 4 generations,
+	// so it is back at its start after 4*size generations on a torus
+	for i := 0; i < 4*size; i++ {
+		u.Step()
+		if u.CountLiving() != 5 {
+			t.Fatalf("Generation %d: glider should keep 5 cells on a torus, got %d", i+1, u.CountLiving())
+		}
+	}
+
+	for _, c := range glider {
+		if u.Get(c) != core.Alive {
+			t.Errorf("Cell %v should be alive after a full lap", c)
+		}
+	}
+}
+
+func TestUniverse2D_AliveBoundary(t *testing.T) {
+	u := New2D(5, 5, rules.ConwayRule{})
+	u.SetBoundary(UniformBoundary(BoundaryAlive))
+
+	// A corner cell sees 5 living cells beyond the edges
+	if count := u.countNeighbors(0, 0); count != 5 {
+		t.Errorf("Corner cell should see 5 living outside cells, got %d", count)
+	}
+	// An interior cell is unaffected
+	if count := u.countNeighbors(2, 2); count != 0 {
+		t.Errorf("Interior cell should see 0 neighbors, got %d", count)
+	}
+}
+
+func TestUniverse2D_MirrorBoundary(t *testing.T) {
+	u := New2D(5, 5, rules.ConwayRule{})
+	u.SetBoundary(UniformBoundary(BoundaryMirror))
+
+	// The cell at (0,2) is reflected to (-1,1), (-1,2) and (-1,3)
+	u.Set(core.NewCoord2D(0, 2), core.Alive)
+	if count := u.countNeighbors(0, 1); count != 2 {
+		t.Errorf("Expected the edge cell and its reflection, got %d neighbors", count)
+	}
+}
+
+func TestUniverse2D_CloneKeepsBoundary(t *testing.T) {
+	u := New2D(5, 5, rules.ConwayRule{})
+	u.SetBoundary(KleinBottleBoundary())
+
+	clone := u.Clone().(*Universe2D)
+	if clone.Boundary() != KleinBottleBoundary() {
+		t.Errorf("Clone should keep the boundary, got %+v", clone.Boundary())
+	}
+}
+
+func TestUniverse3D_TorusMatchesAcrossEdges(t *testing.T) {
+	rule := rules.Life3D_B6S567{}
+	u := New3D(6, 6, 6, rule)
+	u.SetBoundary(TorusBoundary())
+
+	// Place a cube straddling the corner of the grid; on a torus it behaves
+	// exactly like a cube in the middle of the grid
+	ref := New3D(6, 6, 6, rule)
+	for _, d := range []int{0, 1} {
+		for _, e := range []int{0, 1} {
+			for _, f := range []int{0, 1} {
+				u.Set(core.NewCoord3D(wrap(d-1, 6), wrap(e-1, 6), wrap(f-1, 6)), core.Alive)
+				ref.Set(core.NewCoord3D(d+2, e+2, f+2), core.Alive)
+			}
+		}
+	}
+
+	for step := 0; step < 4; step++ {
+		u.Step()
+		ref.Step()
+		if u.CountLiving() != ref.CountLiving() {
+			t.Fatalf("Step %d: torus population %d differs from reference %d",
+				step, u.CountLiving(), ref.CountLiving())
+		}
+	}
+}
+
+func TestUniverse3D_BoundaryParallel(t *testing.T) {
+	rule := rules.Life3D_B6S567{}
+	u1 := New3D(10, 10, 10, rule)
+	u2 := New3D(10, 10, 10, rule)
+	u1.SetBoundary(TorusBoundary())
+	u2.SetBoundary(TorusBoundary())
+
+	cells := []core.Coord{
+		core.NewCoord3D(0, 0, 0), core.NewCoord3D(9, 0, 0), core.NewCoord3D(0, 9, 0),
+		core.NewCoord3D(0, 0, 9), core.NewCoord3D(9, 9, 9), core.NewCoord3D(9, 9, 0),
+		core.NewCoord3D(1, 0, 0), core.NewCoord3D(0, 1, 9),
+	}
+	for _, c := range cells {
+		u1.Set(c, core.Alive)
+		u2.Set(c, core.Alive)
+	}
+
+	for step := 0; step < 5; step++ {
+		u1.Step()
+		u2.StepParallel()
+		for i := range u1.cells {
+			if u1.cells[i] != u2.cells[i] {
+				t.Fatalf("Step %d: cell %d differs between Step and StepParallel", step, i)
+			}
+		}
+	}
+}
+
+func TestUniverse25D_VerticalWrap(t *testing.T) {
+	u := New25D(5, 5, 3, rules.ConwayRule{})
+	u.SetBoundary(TorusBoundary())
+
+	// The top layer is adjacent to the bottom layer on a torus
+	u.Set(core.NewCoord3D(2, 2, 2), core.Alive)
+	if count := u.countVerticalNeighbors(2, 2, 0); count != 1 {
+		t.Errorf("Bottom layer should see the top layer through the wrap, got %d", count)
+	}
+
+	// Layers inherit the X/Y topology
+	if u.GetLayer(0).Boundary() != TorusBoundary() {
+		t.Error("Layers should inherit the boundary")
+	}
+}
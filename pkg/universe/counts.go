@@ -0,0 +1,43 @@
+package universe
+
+import (
+	"sync/atomic"
+
+	"golife/pkg/core"
+)
+
+// stepCounts tallies the cells born and the cells that died in a step.
+// Workers count their own band and add it once, so the totals are exact
+// for parallel steps too.
+type stepCounts struct {
+	births atomic.Int64
+	deaths atomic.Int64
+}
+
+// reset clears the tallies before a step
+func (c *stepCounts) reset() {
+	c.births.Store(0)
+	c.deaths.Store(0)
+}
+
+// add adds a worker's tallies
+func (c *stepCounts) add(births, deaths int) {
+	if births != 0 {
+		c.births.Add(int64(births))
+	}
+	if deaths != 0 {
+		c.deaths.Add(int64(deaths))
+	}
+}
+
+// tally counts a cell going from current to next: a birth when a dead cell
+// comes alive, a death when a living or dying cell becomes dead
+func tally(current, next core.CellState, births, deaths *int) {
+	if current == core.Dead {
+		if next != core.Dead {
+			*births++
+		}
+	} else if next == core.Dead {
+		*deaths++
+	}
+}
@@ -0,0 +1,67 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+// countChanges counts the births and deaths between two snapshots of a universe
+func countChanges(before, after core.Universe) (births, deaths int) {
+	size := after.Size()
+	for z := 0; z < max(size.Z, 1); z++ {
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				c := core.NewCoord3D(x, y, z)
+				tally(before.Get(c), after.Get(c), &births, &deaths)
+			}
+		}
+	}
+	return births, deaths
+}
+
+// stepCounter is a universe counting births and deaths while stepping
+type stepCounter interface {
+	core.Universe
+	StepParallel()
+	Births() int
+	Deaths() int
+}
+
+func TestStepCounts(t *testing.T) {
+	brain, _ := rules.Parse("brians-brain")
+	interacting := New25D(30, 20, 3, rules.ConwayRule{})
+	interacting.SetLayerInteraction(true)
+	bit, _ := NewBit2D(70, 30, rules.ConwayRule{})
+
+	tests := []struct {
+		name string
+		u    stepCounter
+	}{
+		{"2D", New2D(70, 30, rules.ConwayRule{})},
+		{"2D generations", New2D(50, 30, brain)},
+		{"2.5D", New25D(30, 20, 3, rules.ConwayRule{})},
+		{"2.5D interacting", interacting},
+		{"3D", New3D(14, 14, 14, rules.Life3D_B6S567{})},
+		{"bit-packed", bit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RandomFill(tt.u, RandomOptions{Seed: 6, Density: 0.35})
+			for gen := 1; gen <= 12; gen++ {
+				before := tt.u.Clone()
+				if gen%2 == 0 {
+					tt.u.StepParallel()
+				} else {
+					tt.u.Step()
+				}
+				births, deaths := countChanges(before, tt.u)
+				if tt.u.Births() != births || tt.u.Deaths() != deaths {
+					t.Fatalf("Generation %d: counted %d births and %d deaths, want %d and %d",
+						gen, tt.u.Births(), tt.u.Deaths(), births, deaths)
+				}
+			}
+		})
+	}
+}
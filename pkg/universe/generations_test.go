@@ -0,0 +1,155 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+func TestGenerations_BriansBrainDecay2D(t *testing.T) {
+	u := New2D(10, 10, rules.BriansBrain())
+	u.Set(core.NewCoord2D(5, 5), core.Alive)
+
+	// A live cell always fails to survive in Brian's Brain: Alive -> 1 -> Dead
+	u.Step()
+	if got := u.Get(core.NewCoord2D(5, 5)); got != 1 {
+		t.Fatalf("After 1 step: state = %d, want 1 (dying)", got)
+	}
+	if u.GetAge(5, 5) != 0 {
+		t.Errorf("Dying cell should have age 0, got %d", u.GetAge(5, 5))
+	}
+
+	u.Step()
+	if got := u.Get(core.NewCoord2D(5, 5)); got != core.Dead {
+		t.Fatalf("After 2 steps: state = %d, want Dead", got)
+	}
+}
+
+func TestGenerations_DyingCellsAreNotNeighbors(t *testing.T) {
+	// Two live cells give (4,5) exactly 2 neighbors: birth under B2
+	u := New2D(10, 10, rules.BriansBrain())
+	u.Set(core.NewCoord2D(3, 5), core.Alive)
+	u.Set(core.NewCoord2D(5, 5), core.Alive)
+	u.Step()
+	if u.Get(core.NewCoord2D(4, 5)) != core.Alive {
+		t.Error("Dead cell with 2 live neighbors should be born")
+	}
+
+	// The same two cells in the dying state must not cause a birth
+	u = New2D(10, 10, rules.BriansBrain())
+	u.Set(core.NewCoord2D(3, 5), 1)
+	u.Set(core.NewCoord2D(5, 5), 1)
+	u.Step()
+	if u.Get(core.NewCoord2D(4, 5)) != core.Dead {
+		t.Error("Dying cells should not count as live neighbors")
+	}
+	if u.CountLiving() != 0 {
+		t.Errorf("Dying cells should have decayed, %d cells left", u.CountLiving())
+	}
+}
+
+func TestGenerations_StarWarsDecaySequence(t *testing.T) {
+	u := New2D(10, 10, rules.StarWars())
+	u.Set(core.NewCoord2D(5, 5), core.Alive)
+
+	// 4 states: Alive -> 2 -> 1 -> Dead
+	want := []core.CellState{2, 1, core.Dead}
+	for i, w := range want {
+		u.Step()
+		if got := u.Get(core.NewCoord2D(5, 5)); got != w {
+			t.Fatalf("Step %d: state = %d, want %d", i+1, got, w)
+		}
+	}
+}
+
+func TestGenerations_TwoStateRuleTreatsAnyNonZeroAsAlive(t *testing.T) {
+	// Existing callers use 1 as "alive" with two-state rules
+	u := New2D(10, 10, rules.ConwayRule{})
+	u.Set(core.NewCoord2D(4, 5), 1)
+	u.Set(core.NewCoord2D(5, 5), 1)
+	u.Set(core.NewCoord2D(6, 5), 1)
+
+	u.Step()
+
+	if u.CountLiving() != 3 || u.Get(core.NewCoord2D(5, 4)) != core.Alive {
+		t.Error("Blinker made of state 1 cells should still oscillate")
+	}
+}
+
+func TestGenerations_Rule445Decay3D(t *testing.T) {
+	u := New3D(10, 10, 10, rules.Rule445())
+	u.Set(core.NewCoord3D(5, 5, 5), core.Alive)
+
+	// 5 states: Alive -> 3 -> 2 -> 1 -> Dead
+	want := []core.CellState{3, 2, 1, core.Dead}
+	for i, w := range want {
+		u.Step()
+		if got := u.Get(core.NewCoord3D(5, 5, 5)); got != w {
+			t.Fatalf("Step %d: state = %d, want %d", i+1, got, w)
+		}
+	}
+}
+
+func TestGenerations_3DParallelMatchesSequential(t *testing.T) {
+	seq := New3D(12, 12, 12, rules.Rule445())
+	for i := 0; i < 300; i++ {
+		x, y, z := (i*7)%12, (i*5)%12, (i*11)%12
+		seq.Set(core.NewCoord3D(x, y, z), core.Alive)
+	}
+	par := seq.Clone().(*Universe3D)
+
+	for gen := 0; gen < 10; gen++ {
+		seq.Step()
+		par.StepParallel()
+	}
+
+	for i := range seq.cells {
+		if seq.cells[i] != par.cells[i] {
+			t.Fatalf("Mismatch at index %d: sequential=%d parallel=%d", i, seq.cells[i], par.cells[i])
+		}
+	}
+}
+
+func TestGenerations_SparseMatchesUniverse2D(t *testing.T) {
+	dense := New2D(40, 40, rules.BriansBrain())
+	sparse, err := NewSparse2D(rules.BriansBrain())
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+
+	seed := [][2]int{{18, 18}, {19, 18}, {18, 19}, {21, 20}, {22, 21}, {20, 22}}
+	for _, c := range seed {
+		dense.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+		sparse.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+	}
+
+	// Few enough generations that the pattern stays clear of the dense edges
+	for gen := 0; gen < 8; gen++ {
+		dense.Step()
+		sparse.Step()
+	}
+
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			c := core.NewCoord2D(x, y)
+			if dense.Get(c) != sparse.Get(c) {
+				t.Fatalf("Mismatch at (%d,%d): dense=%d sparse=%d", x, y, dense.Get(c), sparse.Get(c))
+			}
+		}
+	}
+}
+
+func TestGenerations_25DInteractionDecay(t *testing.T) {
+	u := New25D(10, 10, 3, rules.StarWars())
+	u.SetLayerInteraction(true)
+	u.Set(core.NewCoord3D(5, 5, 1), core.Alive)
+
+	// An isolated live cell decays through the intermediate states
+	want := []core.CellState{2, 1, core.Dead}
+	for i, w := range want {
+		u.Step()
+		if got := u.Get(core.NewCoord3D(5, 5, 1)); got != w {
+			t.Fatalf("Step %d: state = %d, want %d", i+1, got, w)
+		}
+	}
+}
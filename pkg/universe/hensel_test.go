@@ -0,0 +1,99 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"math/rand"
+	"testing"
+)
+
+func mustParse(t *testing.T, rulestring string) core.Rule {
+	t.Helper()
+	rule, err := rules.Parse(rulestring)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned error: %v", rulestring, err)
+	}
+	return rule
+}
+
+func TestUniverse2D_NeighborMask(t *testing.T) {
+	u := New2D(5, 5, mustParse(t, "tlife"))
+	if u.maskLUT == nil {
+		t.Fatal("Hensel rules should build a mask lookup table")
+	}
+
+	u.Set(core.NewCoord2D(2, 1), core.Alive) // N
+	u.Set(core.NewCoord2D(3, 3), core.Alive) // SE
+
+	if got := u.neighborMask(2, 2); got != 1<<1|1<<7 {
+		t.Errorf("neighborMask = %08b, want %08b", got, 1<<1|1<<7)
+	}
+
+	if New2D(5, 5, rules.ConwayRule{}).maskLUT != nil {
+		t.Error("Totalistic rules should not build a mask lookup table")
+	}
+}
+
+func TestUniverse2D_HenselTotalisticMatchesConway(t *testing.T) {
+	// Conway written out letter by letter goes through the lookup table
+	hensel := New2D(40, 40, mustParse(t, "B3cekaijnqry/S2ceaikn3"))
+	conway := New2D(40, 40, rules.ConwayRule{})
+
+	rng := rand.New(rand.NewSource(11))
+	for i := 0; i < 500; i++ {
+		c := core.NewCoord2D(rng.Intn(40), rng.Intn(40))
+		hensel.Set(c, core.Alive)
+		conway.Set(c, core.Alive)
+	}
+
+	for gen := 0; gen < 30; gen++ {
+		hensel.Step()
+		conway.Step()
+	}
+
+	for i := range conway.cells {
+		if hensel.cells[i] != conway.cells[i] {
+			t.Fatalf("Mismatch at index %d after 30 generations", i)
+		}
+	}
+}
+
+func TestUniverse2D_HenselTLifeBlinker(t *testing.T) {
+	// In tlife (B3/S2-i34q) the blinker's center sees two opposite neighbors
+	// (2i) and dies, while the cells above and below are born as in Life
+	u := New2D(7, 7, mustParse(t, "tlife"))
+	u.Set(core.NewCoord2D(2, 3), core.Alive)
+	u.Set(core.NewCoord2D(3, 3), core.Alive)
+	u.Set(core.NewCoord2D(4, 3), core.Alive)
+
+	u.Step()
+
+	if u.Get(core.NewCoord2D(3, 3)) != core.Dead {
+		t.Error("Center with a 2i neighborhood should die in tlife")
+	}
+	if u.Get(core.NewCoord2D(3, 2)) != core.Alive || u.Get(core.NewCoord2D(3, 4)) != core.Alive {
+		t.Error("Cells above and below should be born")
+	}
+	if u.CountLiving() != 2 {
+		t.Errorf("Expected 2 living cells, got %d", u.CountLiving())
+	}
+}
+
+func TestUniverse2D_HenselGenerations(t *testing.T) {
+	u := New2D(7, 7, mustParse(t, "B2a/S/C3"))
+	u.Set(core.NewCoord2D(2, 2), core.Alive)
+	u.Set(core.NewCoord2D(3, 2), core.Alive)
+
+	u.Step()
+
+	// Both live cells start dying; (2,1) and (3,1) etc. see an adjacent pair
+	if got := u.Get(core.NewCoord2D(2, 2)); got != 1 {
+		t.Errorf("Live cell should enter the dying state, got %d", got)
+	}
+	if u.Get(core.NewCoord2D(2, 1)) != core.Alive {
+		t.Error("Cell seeing a 2a pair (S and SE) should be born")
+	}
+	if u.Get(core.NewCoord2D(4, 3)) != core.Dead {
+		t.Error("Cell seeing a single corner neighbor should stay dead")
+	}
+}
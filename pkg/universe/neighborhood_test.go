@@ -0,0 +1,205 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"math/rand"
+	"testing"
+)
+
+func TestUniverse2D_VonNeumannNeighborhood(t *testing.T) {
+	u := New2D(5, 5, rules.ConwayRule{})
+	u.SetNeighborhood(core.VonNeumannNeighborhood(1))
+
+	// Fill the 3x3 block around the center
+	for y := 1; y <= 3; y++ {
+		for x := 1; x <= 3; x++ {
+			u.Set(core.NewCoord2D(x, y), core.Alive)
+		}
+	}
+
+	if count := u.countNeighbors(2, 2); count != 4 {
+		t.Errorf("Von Neumann neighborhood should count 4 neighbors, got %d", count)
+	}
+
+	u.SetNeighborhood(core.MooreNeighborhood(2))
+	if count := u.countNeighbors(2, 2); count != 8 {
+		t.Errorf("Moore range 2 should count the 8 living cells, got %d", count)
+	}
+}
+
+func TestUniverse2D_HexagonalNeighborhood(t *testing.T) {
+	u := New2D(3, 3, rules.ConwayRule{})
+	u.SetNeighborhood(core.HexagonalNeighborhood(1))
+
+	// NE (2,0) and SW (0,2) are not neighbors of the center on a hex grid
+	u.Set(core.NewCoord2D(2, 0), core.Alive)
+	u.Set(core.NewCoord2D(0, 2), core.Alive)
+	u.Set(core.NewCoord2D(0, 0), core.Alive)
+
+	if count := u.countNeighbors(1, 1); count != 1 {
+		t.Errorf("Hexagonal neighborhood should only count the NW cell, got %d", count)
+	}
+}
+
+func TestUniverse2D_CustomNeighborhood(t *testing.T) {
+	u := New2D(5, 5, rules.ConwayRule{})
+	u.SetNeighborhood(core.CustomNeighborhood([]core.Coord{
+		core.NewCoord2D(2, 0),
+		core.NewCoord2D(-2, 0),
+	}))
+
+	u.Set(core.NewCoord2D(0, 2), core.Alive)
+	u.Set(core.NewCoord2D(4, 2), core.Alive)
+	u.Set(core.NewCoord2D(2, 1), core.Alive)
+
+	if count := u.countNeighbors(2, 2); count != 2 {
+		t.Errorf("Custom neighborhood should count 2 neighbors, got %d", count)
+	}
+
+	clone := u.Clone().(*Universe2D)
+	if clone.Neighborhood().Type != core.Custom {
+		t.Error("Clone should keep the neighborhood")
+	}
+}
+
+func TestUniverse3D_NeighborhoodOffsets(t *testing.T) {
+	u := New3D(10, 10, 10, rules.Life3D_B6S567{})
+	u.SetNeighborhood(core.VonNeumannNeighborhood(1))
+
+	if len(u.neighborOffsets) != 6 {
+		t.Errorf("Expected 6 neighbor offsets, got %d", len(u.neighborOffsets))
+	}
+
+	u.SetNeighborhood(core.MooreNeighborhood(2))
+	if len(u.neighborOffsets) != 124 {
+		t.Errorf("Expected 124 neighbor offsets, got %d", len(u.neighborOffsets))
+	}
+	if u.radius != 2 {
+		t.Errorf("Expected radius 2, got %d", u.radius)
+	}
+}
+
+// bruteForceCount3D counts neighbors by scanning the offsets with bounds checks
+func bruteForceCount3D(u *Universe3D, x, y, z int) int {
+	count := 0
+	for _, o := range u.neighborhood.OffsetsFor(core.Dim3D) {
+		if u.Get(core.NewCoord3D(x+o.X, y+o.Y, z+o.Z)) != core.Dead {
+			count++
+		}
+	}
+	return count
+}
+
+func TestUniverse3D_RangeTwoFastPathMatchesSafePath(t *testing.T) {
+	u := New3D(9, 9, 9, rules.Life3D_B6S567{})
+	u.SetNeighborhood(core.MooreNeighborhood(2))
+
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		u.Set(core.NewCoord3D(r.Intn(9), r.Intn(9), r.Intn(9)), core.Alive)
+	}
+
+	for z := 2; z < 7; z++ {
+		for y := 2; y < 7; y++ {
+			for x := 2; x < 7; x++ {
+				idx := z*81 + y*9 + x
+				want := bruteForceCount3D(u, x, y, z)
+				if got := u.countNeighborsInterior(idx); got != want {
+					t.Fatalf("Interior count at (%d,%d,%d) = %d, want %d", x, y, z, got, want)
+				}
+				if got := u.countNeighbors(x, y, z); got != want {
+					t.Fatalf("Safe count at (%d,%d,%d) = %d, want %d", x, y, z, got, want)
+				}
+			}
+		}
+	}
+
+	// Step and StepParallel agree with a wide neighborhood
+	clone := u.Clone().(*Universe3D)
+	u.Step()
+	clone.StepParallel()
+	for i := range u.cells {
+		if u.cells[i] != clone.cells[i] {
+			t.Fatalf("Cell %d differs between Step and StepParallel", i)
+		}
+	}
+}
+
+func TestUniverse4D_VonNeumannNeighborhood(t *testing.T) {
+	u := New4D(5, 5, 5, 5, rules.Life4D_B9S7_10{})
+	u.SetNeighborhood(core.VonNeumannNeighborhood(1))
+
+	if len(u.neighborOffsets) != 8 {
+		t.Errorf("Expected 8 neighbor offsets, got %d", len(u.neighborOffsets))
+	}
+
+	u.Set(core.NewCoord4D(2, 2, 2, 1), core.Alive)
+	u.Set(core.NewCoord4D(1, 1, 2, 2), core.Alive) // Diagonal, not a von Neumann neighbor
+	if count := u.countNeighbors(2, 2, 2, 2); count != 1 {
+		t.Errorf("Expected 1 neighbor, got %d", count)
+	}
+}
+
+func TestUniverse25D_NeighborhoodAppliesToLayers(t *testing.T) {
+	u := New25D(5, 5, 3, rules.ConwayRule{})
+	u.SetNeighborhood(core.VonNeumannNeighborhood(1))
+
+	if u.GetLayer(1).Neighborhood().Type != core.VonNeumann {
+		t.Error("Layers should use the universe neighborhood")
+	}
+
+	// Vertical neighbors: directly above plus the von Neumann offsets
+	u.Set(core.NewCoord3D(2, 2, 0), core.Alive)
+	u.Set(core.NewCoord3D(3, 2, 0), core.Alive)
+	u.Set(core.NewCoord3D(3, 3, 0), core.Alive) // Diagonal, ignored
+	if count := u.countVerticalNeighbors(2, 2, 1); count != 2 {
+		t.Errorf("Expected 2 vertical neighbors, got %d", count)
+	}
+}
+
+func TestSparseUniverse_NeighborhoodMatchesDense(t *testing.T) {
+	const size = 80
+	n := core.VonNeumannNeighborhood(2)
+	dense := New2D(size, size, rules.ConwayRule{})
+	dense.SetNeighborhood(n)
+	sparse, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	sparse.SetNeighborhood(n)
+
+	r := rand.New(rand.NewSource(5))
+	for y := 30; y < 50; y++ {
+		for x := 30; x < 50; x++ {
+			if r.Intn(3) == 0 {
+				dense.Set(core.NewCoord2D(x, y), core.Alive)
+				sparse.Set(core.NewCoord2D(x-40, y-40), core.Alive)
+			}
+		}
+	}
+
+	for step := 0; step < 8; step++ {
+		dense.Step()
+		sparse.Step()
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if dense.Get(core.NewCoord2D(x, y)) != sparse.Get(core.NewCoord2D(x-40, y-40)) {
+					t.Fatalf("Step %d: cell (%d,%d) differs", step, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSparseUniverse_RejectsOversizedNeighborhood(t *testing.T) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	u.SetNeighborhood(core.MooreNeighborhood(chunkSize + 1))
+
+	if u.Neighborhood().Range == chunkSize+1 {
+		t.Error("Neighborhoods wider than a chunk should be ignored")
+	}
+}
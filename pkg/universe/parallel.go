@@ -0,0 +1,51 @@
+package universe
+
+import (
+	"runtime"
+	"sync"
+)
+
+// workerCount returns the number of workers to use for n rows of work:
+// the configured count, or one per CPU when it is 0, but never more than n
+func workerCount(configured, n int) int {
+	workers := configured
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
+// parallelBands splits [0, n) into one contiguous band per worker and calls
+// fn for each band concurrently, returning when all bands are done.
+// With a single worker fn runs on the calling goroutine.
+func parallelBands(n, workers int, fn func(start, end int)) {
+	if workers <= 1 {
+		if n > 0 {
+			fn(0, n)
+		}
+		return
+	}
+
+	bandSize := n / workers
+	remainder := n % workers
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	start := 0
+	for workerID := 0; workerID < workers; workerID++ {
+		end := start + bandSize
+		if workerID < remainder {
+			end++ // Spread the remainder over the first workers
+		}
+
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+		start = end
+	}
+	wg.Wait()
+}
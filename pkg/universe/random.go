@@ -0,0 +1,271 @@
+package universe
+
+import (
+	"fmt"
+	"math/rand"
+
+	"golife/pkg/core"
+)
+
+// DefaultDensity is the fraction of cells RandomFill makes alive when no density is given
+const DefaultDensity = 0.5
+
+// RegionShape selects the shape of the region RandomFill fills
+type RegionShape int
+
+const (
+	// RegionBox fills the whole box between Min and Max
+	RegionBox RegionShape = iota
+
+	// RegionSphere fills the ellipse (2D) or ellipsoid (3D, 4D) inscribed in the box
+	RegionSphere
+)
+
+// Symmetry selects the mirror symmetry of a random fill. Cells are mirrored
+// across the middle of the region, so a symmetric soup stays symmetric under
+// any isotropic rule.
+type Symmetry int
+
+const (
+	// SymmetryNone draws every cell independently
+	SymmetryNone Symmetry = iota
+
+	// SymmetryMirrorX mirrors left and right
+	SymmetryMirrorX
+
+	// SymmetryMirrorY mirrors top and bottom
+	SymmetryMirrorY
+
+	// SymmetryMirrorXY mirrors both X and Y (four-fold)
+	SymmetryMirrorXY
+
+	// SymmetryMirrorXYZ mirrors X, Y and Z (eight-fold in 3D)
+	SymmetryMirrorXYZ
+
+	// SymmetryRotate180 maps every cell to its point reflection through the center
+	SymmetryRotate180
+)
+
+// String returns the string representation of the symmetry
+func (s Symmetry) String() string {
+	switch s {
+	case SymmetryNone:
+		return "none"
+	case SymmetryMirrorX:
+		return "x"
+	case SymmetryMirrorY:
+		return "y"
+	case SymmetryMirrorXY:
+		return "xy"
+	case SymmetryMirrorXYZ:
+		return "xyz"
+	case SymmetryRotate180:
+		return "rot180"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSymmetry returns the symmetry for a name
+// Supported names: none, x, y, xy, xyz, rot180
+func ParseSymmetry(name string) (Symmetry, error) {
+	for s := SymmetryNone; s <= SymmetryRotate180; s++ {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	if name == "" {
+		return SymmetryNone, nil
+	}
+	return SymmetryNone, fmt.Errorf("unknown symmetry %q (expected none, x, y, xy, xyz or rot180)", name)
+}
+
+// flips returns the axis-flip masks of the symmetry group
+// (bit 0 X, bit 1 Y, bit 2 Z, bit 3 W)
+func (s Symmetry) flips() []int {
+	switch s {
+	case SymmetryMirrorX:
+		return []int{0, 1}
+	case SymmetryMirrorY:
+		return []int{0, 2}
+	case SymmetryMirrorXY:
+		return []int{0, 1, 2, 3}
+	case SymmetryMirrorXYZ:
+		return []int{0, 1, 2, 3, 4, 5, 6, 7}
+	case SymmetryRotate180:
+		return []int{0, 15}
+	default:
+		return []int{0}
+	}
+}
+
+// RandomOptions controls RandomFill
+type RandomOptions struct {
+	// Seed makes the fill reproducible: the same seed, options and universe
+	// size always give the same cells
+	Seed int64
+
+	// Density is the probability that a cell is alive, from 0 to 1.
+	// Zero uses DefaultDensity.
+	Density float64
+
+	// Min and Max bound the region to fill, Max exclusive. A zero Max fills
+	// the whole universe. Axes the universe does not have are ignored.
+	Min, Max core.Coord
+
+	// Shape is the shape of the region within the box
+	Shape RegionShape
+
+	// Symmetry mirrors the fill across the middle of the region
+	Symmetry Symmetry
+
+	// States lists the states a live cell may take, chosen uniformly.
+	// By default cells become core.Alive, or a random non-empty state
+	// when the universe runs a core.TransitionRule.
+	States []core.CellState
+}
+
+// RandomFill fills a region of any universe with random cells and returns
+// the number of live cells it placed. Cells in the region that are not
+// chosen are cleared; cells outside it are left untouched.
+func RandomFill(u core.Universe, opts RandomOptions) int {
+	density := opts.Density
+	switch {
+	case density == 0:
+		density = DefaultDensity
+	case density < 0:
+		density = 0
+	case density > 1:
+		density = 1
+	}
+
+	states := opts.States
+	if len(states) == 0 {
+		states = defaultLiveStates(u)
+	}
+
+	lo, hi := randomRegion(u, opts)
+	flips := opts.Symmetry.flips()
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	placed := 0
+	images := make([][4]int, 0, len(flips))
+	var c [4]int
+	for c[3] = lo[3]; c[3] < hi[3]; c[3]++ {
+		for c[2] = lo[2]; c[2] < hi[2]; c[2]++ {
+			for c[1] = lo[1]; c[1] < hi[1]; c[1]++ {
+				for c[0] = lo[0]; c[0] < hi[0]; c[0]++ {
+					if opts.Shape == RegionSphere && !insideEllipsoid(c, lo, hi) {
+						continue
+					}
+
+					// Draw once per orbit, at the orbit's first cell in scan order
+					images = images[:0]
+					canonical := true
+					for _, mask := range flips {
+						image := mirrorCell(c, mask, lo, hi)
+						if scanBefore(image, c) {
+							canonical = false
+							break
+						}
+						if !containsCell(images, image) {
+							images = append(images, image)
+						}
+					}
+					if !canonical {
+						continue
+					}
+
+					state := core.Dead
+					if rng.Float64() < density {
+						state = states[rng.Intn(len(states))]
+					}
+					for _, image := range images {
+						u.Set(core.NewCoord4D(image[0], image[1], image[2], image[3]), state)
+					}
+					if state != core.Dead {
+						placed += len(images)
+					}
+				}
+			}
+		}
+	}
+	return placed
+}
+
+// defaultLiveStates returns the states of live cells for a universe's rule
+func defaultLiveStates(u core.Universe) []core.CellState {
+	if ru, ok := u.(interface{ Rule() core.Rule }); ok {
+		if table, ok := ru.Rule().(core.TransitionRule); ok && table.NumStates() > 1 {
+			states := make([]core.CellState, table.NumStates()-1)
+			for i := range states {
+				states[i] = core.CellState(i + 1)
+			}
+			return states
+		}
+	}
+	return []core.CellState{core.Alive}
+}
+
+// randomRegion returns the region box per axis (X, Y, Z, W), Max exclusive.
+// Every axis spans at least one coordinate.
+func randomRegion(u core.Universe, opts RandomOptions) (lo, hi [4]int) {
+	minC, maxC := opts.Min, opts.Max
+	if maxC == (core.Coord{}) {
+		minC, maxC = core.Coord{}, u.Size()
+	}
+	lo = [4]int{minC.X, minC.Y, minC.Z, minC.W}
+	hi = [4]int{maxC.X, maxC.Y, maxC.Z, maxC.W}
+	for axis := range hi {
+		if hi[axis] <= lo[axis] {
+			hi[axis] = lo[axis] + 1
+		}
+	}
+	return lo, hi
+}
+
+// insideEllipsoid reports whether the center of cell c lies in the ellipsoid
+// inscribed in the box; axes one cell wide are ignored
+func insideEllipsoid(c, lo, hi [4]int) bool {
+	sum := 0.0
+	for axis := range c {
+		extent := hi[axis] - lo[axis]
+		if extent <= 1 {
+			continue
+		}
+		radius := float64(extent) / 2
+		d := (float64(c[axis]) + 0.5 - float64(lo[axis]) - radius) / radius
+		sum += d * d
+	}
+	return sum <= 1
+}
+
+// mirrorCell flips c across the middle of the region on the axes set in mask
+func mirrorCell(c [4]int, mask int, lo, hi [4]int) [4]int {
+	for axis := range c {
+		if mask&(1<<axis) != 0 {
+			c[axis] = lo[axis] + hi[axis] - 1 - c[axis]
+		}
+	}
+	return c
+}
+
+// containsCell reports whether cells contains c
+func containsCell(cells [][4]int, c [4]int) bool {
+	for _, cell := range cells {
+		if cell == c {
+			return true
+		}
+	}
+	return false
+}
+
+// scanBefore reports whether a comes before b in RandomFill's scan order
+func scanBefore(a, b [4]int) bool {
+	for axis := 3; axis >= 0; axis-- {
+		if a[axis] != b[axis] {
+			return a[axis] < b[axis]
+		}
+	}
+	return false
+}
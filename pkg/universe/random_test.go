@@ -0,0 +1,199 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+func TestRandomFill_SameSeedSameCells(t *testing.T) {
+	a := New2D(40, 30, rules.ConwayRule{})
+	b := New2D(40, 30, rules.ConwayRule{})
+	c := New2D(40, 30, rules.ConwayRule{})
+
+	RandomFill(a, RandomOptions{Seed: 42})
+	RandomFill(b, RandomOptions{Seed: 42})
+	RandomFill(c, RandomOptions{Seed: 43})
+
+	differs := false
+	for i := range a.cells {
+		if a.cells[i] != b.cells[i] {
+			t.Fatalf("Same seed gave different cells at index %d", i)
+		}
+		if a.cells[i] != c.cells[i] {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("Different seeds should give different cells")
+	}
+}
+
+func TestRandomFill_Density(t *testing.T) {
+	u := New2D(200, 200, rules.ConwayRule{})
+	placed := RandomFill(u, RandomOptions{Seed: 1, Density: 0.2})
+
+	if placed != u.CountLiving() {
+		t.Errorf("RandomFill reported %d cells, universe has %d", placed, u.CountLiving())
+	}
+	density := float64(placed) / (200 * 200)
+	if density < 0.18 || density > 0.22 {
+		t.Errorf("Density = %.3f, want about 0.2", density)
+	}
+}
+
+func TestRandomFill_BoxRegion(t *testing.T) {
+	u := New2D(30, 30, rules.ConwayRule{})
+	u.Set(core.NewCoord2D(0, 0), core.Alive) // Outside the region: left alone
+
+	RandomFill(u, RandomOptions{
+		Seed:    5,
+		Density: 1,
+		Min:     core.NewCoord2D(10, 5),
+		Max:     core.NewCoord2D(20, 15),
+	})
+
+	if u.CountLiving() != 101 {
+		t.Errorf("Expected the 10x10 region plus the existing cell, got %d cells", u.CountLiving())
+	}
+	if u.Get(core.NewCoord2D(9, 5)) != core.Dead || u.Get(core.NewCoord2D(20, 14)) != core.Dead {
+		t.Error("Cells outside the region should not be filled")
+	}
+}
+
+func TestRandomFill_Sphere3D(t *testing.T) {
+	u := New3D(20, 20, 20, rules.Life3D_B6S567{})
+	RandomFill(u, RandomOptions{Seed: 9, Density: 1, Shape: RegionSphere})
+
+	if u.Get(core.NewCoord3D(0, 0, 0)) != core.Dead {
+		t.Error("Corner of the box lies outside the sphere")
+	}
+	if u.Get(core.NewCoord3D(10, 10, 10)) != core.Alive || u.Get(core.NewCoord3D(0, 10, 10)) != core.Alive {
+		t.Error("Center and face midpoints lie inside the sphere")
+	}
+
+	// Volume of a sphere is about 52% of its bounding cube
+	fraction := float64(u.CountLiving()) / (20 * 20 * 20)
+	if fraction < 0.48 || fraction > 0.56 {
+		t.Errorf("Sphere fills %.3f of the box, want about 0.52", fraction)
+	}
+}
+
+func TestRandomFill_Symmetry(t *testing.T) {
+	const w, h = 17, 12
+	tests := []struct {
+		symmetry Symmetry
+		image    func(x, y int) (int, int)
+	}{
+		{SymmetryMirrorX, func(x, y int) (int, int) { return w - 1 - x, y }},
+		{SymmetryMirrorY, func(x, y int) (int, int) { return x, h - 1 - y }},
+		{SymmetryMirrorXY, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }},
+		{SymmetryRotate180, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symmetry.String(), func(t *testing.T) {
+			u := New2D(w, h, rules.ConwayRule{})
+			placed := RandomFill(u, RandomOptions{Seed: 3, Symmetry: tt.symmetry})
+			if placed != u.CountLiving() {
+				t.Errorf("RandomFill reported %d cells, universe has %d", placed, u.CountLiving())
+			}
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					ix, iy := tt.image(x, y)
+					if u.Get(core.NewCoord2D(x, y)) != u.Get(core.NewCoord2D(ix, iy)) {
+						t.Fatalf("(%d,%d) and its image (%d,%d) differ", x, y, ix, iy)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRandomFill_MirrorXYZ(t *testing.T) {
+	u := New3D(9, 8, 7, rules.Life3D_B6S567{})
+	RandomFill(u, RandomOptions{Seed: 11, Symmetry: SymmetryMirrorXYZ})
+
+	for z := 0; z < 7; z++ {
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 9; x++ {
+				state := u.Get(core.NewCoord3D(x, y, z))
+				if state != u.Get(core.NewCoord3D(8-x, y, z)) ||
+					state != u.Get(core.NewCoord3D(x, 7-y, z)) ||
+					state != u.Get(core.NewCoord3D(x, y, 6-z)) {
+					t.Fatalf("Cell (%d,%d,%d) breaks the mirror symmetry", x, y, z)
+				}
+			}
+		}
+	}
+}
+
+func TestRandomFill_SparseNeedsRegion(t *testing.T) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	placed := RandomFill(u, RandomOptions{
+		Seed:    2,
+		Density: 1,
+		Min:     core.NewCoord2D(-50, -50),
+		Max:     core.NewCoord2D(-40, -45),
+	})
+
+	if placed != 50 || u.CountLiving() != 50 {
+		t.Errorf("Expected 50 cells in the 10x5 region, placed %d, universe has %d", placed, u.CountLiving())
+	}
+}
+
+func TestRandomFill_RuleTableStates(t *testing.T) {
+	u := New2D(30, 30, rules.LangtonsLoops())
+	RandomFill(u, RandomOptions{Seed: 4, Density: 1})
+
+	seen := make(map[core.CellState]bool)
+	for _, cell := range u.cells {
+		if cell == core.Dead || cell > 7 {
+			t.Fatalf("Cell state %d is not a live state of the table", cell)
+		}
+		seen[cell] = true
+	}
+	if len(seen) != 7 {
+		t.Errorf("Expected all 7 live states to appear, got %d", len(seen))
+	}
+}
+
+func TestRandomizeWith_Universe25D(t *testing.T) {
+	a := New25D(16, 16, 4, rules.ConwayRule{})
+	b := New25D(16, 16, 4, rules.ConwayRule{})
+	a.SetLayerInteraction(false)
+	b.SetLayerInteraction(false)
+
+	placedA := a.RandomizeWith(RandomOptions{Seed: 8, Density: 0.3})
+	placedB := b.RandomizeWith(RandomOptions{Seed: 8, Density: 0.3})
+
+	if placedA != placedB || placedA != a.CountLiving() {
+		t.Fatalf("Expected identical fills, got %d and %d (%d living)", placedA, placedB, a.CountLiving())
+	}
+	for z := 0; z < 4; z++ {
+		if a.CountLivingInLayer(z) != b.CountLivingInLayer(z) {
+			t.Errorf("Layer %d differs between identical seeds", z)
+		}
+		if a.CountLivingInLayer(z) == 0 {
+			t.Errorf("Layer %d should have cells", z)
+		}
+	}
+	if age := a.GetLayer(0).GetAge(0, 0); a.Get(core.NewCoord3D(0, 0, 0)) != core.Dead && age != 1 {
+		t.Errorf("Randomized cells should start with age 1, got %d", age)
+	}
+}
+
+func TestParseSymmetry(t *testing.T) {
+	for _, s := range []Symmetry{SymmetryNone, SymmetryMirrorX, SymmetryMirrorY, SymmetryMirrorXY, SymmetryMirrorXYZ, SymmetryRotate180} {
+		parsed, err := ParseSymmetry(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("ParseSymmetry(%q) = %v, %v", s.String(), parsed, err)
+		}
+	}
+	if _, err := ParseSymmetry("spiral"); err == nil {
+		t.Error("ParseSymmetry should reject unknown names")
+	}
+}
@@ -0,0 +1,126 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+func TestRuleTable_WireworldElectronTravels(t *testing.T) {
+	u := New2D(10, 3, rules.Wireworld())
+	for x := 0; x < 10; x++ {
+		u.Set(core.NewCoord2D(x, 1), 3)
+	}
+	u.Set(core.NewCoord2D(0, 1), 2) // Tail
+	u.Set(core.NewCoord2D(1, 1), 1) // Head
+
+	for gen := 1; gen <= 5; gen++ {
+		u.Step()
+		if got := u.Get(core.NewCoord2D(1+gen, 1)); got != 1 {
+			t.Fatalf("Generation %d: expected the head at x=%d, got state %d", gen, 1+gen, got)
+		}
+		if got := u.Get(core.NewCoord2D(gen, 1)); got != 2 {
+			t.Fatalf("Generation %d: expected the tail at x=%d, got state %d", gen, gen, got)
+		}
+	}
+	if u.CountLiving() != 10 {
+		t.Errorf("The wire should keep all 10 cells, got %d", u.CountLiving())
+	}
+}
+
+func TestRuleTable_WireworldTorus(t *testing.T) {
+	u := New2D(6, 3, rules.Wireworld())
+	u.SetBoundary(TorusBoundary())
+	for x := 0; x < 6; x++ {
+		u.Set(core.NewCoord2D(x, 1), 3)
+	}
+	u.Set(core.NewCoord2D(4, 1), 2)
+	u.Set(core.NewCoord2D(5, 1), 1)
+
+	u.Step()
+
+	if got := u.Get(core.NewCoord2D(0, 1)); got != 1 {
+		t.Errorf("The electron should wrap around the torus, got state %d at x=0", got)
+	}
+}
+
+func TestRuleTable_LangtonsLoopReproduces(t *testing.T) {
+	rows := []string{
+		" 22222222",
+		"2170140142",
+		"2022222202",
+		"272    212",
+		"212    212",
+		"202    212",
+		"272    212",
+		"21222222122222",
+		"207107107111112",
+		" 2222222222222",
+	}
+	u := New2D(60, 60, rules.LangtonsLoops())
+	for y, row := range rows {
+		for x, c := range row {
+			if c != ' ' {
+				u.Set(core.NewCoord2D(20+x, 20+y), core.CellState(c-'0'))
+			}
+		}
+	}
+	initial := u.CountLiving()
+
+	for gen := 0; gen < 151; gen++ {
+		u.Step()
+	}
+
+	// The daughter loop has separated to the right of the parent
+	parent, daughter := 0, 0
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			if u.Get(core.NewCoord2D(x, y)) == core.Dead {
+				continue
+			}
+			if x < 30 {
+				parent++
+			} else {
+				daughter++
+			}
+		}
+	}
+	if daughter != initial {
+		t.Errorf("Daughter loop has %d cells, want %d like the original", daughter, initial)
+	}
+	if parent < initial-5 {
+		t.Errorf("Parent loop has only %d cells left", parent)
+	}
+}
+
+func TestRuleTable_RandomizeUsesTableStates(t *testing.T) {
+	u := New2D(30, 30, rules.Wireworld())
+	u.Randomize()
+
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			if s := u.Get(core.NewCoord2D(x, y)); s > 3 {
+				t.Fatalf("Randomize set state %d, beyond the table's 4 states", s)
+			}
+		}
+	}
+}
+
+func TestRuleTable_Clone(t *testing.T) {
+	u := New2D(10, 3, rules.Wireworld())
+	for x := 0; x < 10; x++ {
+		u.Set(core.NewCoord2D(x, 1), 3)
+	}
+	u.Set(core.NewCoord2D(3, 1), 1)
+
+	clone := u.Clone().(*Universe2D)
+	u.Step()
+	clone.Step()
+
+	for x := 0; x < 10; x++ {
+		c := core.NewCoord2D(x, 1)
+		if u.Get(c) != clone.Get(c) {
+			t.Errorf("Clone diverged at x=%d: %d vs %d", x, u.Get(c), clone.Get(c))
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package universe
+
+import (
+	"fmt"
+
+	"golife/pkg/core"
+)
+
+// Chunk geometry for the sparse universe.
+// Chunks are 16 cells wide on every axis, so chunk coordinates can be
+// derived with arithmetic shifts (which floor correctly for negative values).
+const (
+	chunkShift = 4
+	chunkSize  = 1 << chunkShift
+	chunkMask  = chunkSize - 1
+)
+
+// chunkKey identifies a chunk by its chunk-space coordinate (Z is 0 in 2D)
+type chunkKey struct {
+	X, Y, Z int
+}
+
+// chunk stores a fixed-size block of cells
+type chunk struct {
+	cells      []core.CellState // Flat array: [lz*chunkSize*chunkSize + ly*chunkSize + lx]
+	population int              // Number of living cells, used to free empty chunks
+}
+
+// SparseUniverse is an unbounded universe stored as a map of fixed-size chunks.
+// Chunks are allocated on demand when a cell becomes alive and freed as soon as
+// they become empty, so memory follows the population instead of the bounding box.
+// It supports 2D and 3D universes with any neighborhood up to the chunk size in radius.
+//
+// Rules that give birth with 0 neighbors (B0) are rejected, since they
+// would fill the infinite plane in a single generation.
+type SparseUniverse struct {
+	dim          core.Dimension
+	chunks       map[chunkKey]*chunk
+	rule         core.Rule
+	transition   transition // Applies the rule, including Generations decay states
+	neighborhood core.Neighborhood
+	halo         int // Neighborhood radius, copied around each chunk before stepping
+
+	// Scratch state reused between generations
+	padded        []core.CellState // Chunk plus a halo on every side
+	paddedOffsets []int            // Pre-computed neighbor offsets inside the padded buffer
+	weights       []float64        // NeighborWeight per offset; nil when all weights are 1
+	pool          []*chunk         // Recycled chunks
+}
+
+// NewSparse2D creates a new unbounded 2D universe with the given rule.
+// It returns an error for B0 rules.
+func NewSparse2D(rule core.Rule) (*SparseUniverse, error) {
+	return newSparse(core.Dim2D, rule)
+}
+
+// NewSparse3D creates a new unbounded 3D universe with the given rule.
+// It returns an error for B0 rules.
+func NewSparse3D(rule core.Rule) (*SparseUniverse, error) {
+	return newSparse(core.Dim3D, rule)
+}
+
+// newSparse creates a sparse universe of the given dimension
+func newSparse(dim core.Dimension, rule core.Rule) (*SparseUniverse, error) {
+	if rule.ShouldBirth(0) {
+		return nil, fmt.Errorf("sparse universe does not support B0 rules (%s), which fill the infinite plane", rule.Name())
+	}
+	u := &SparseUniverse{
+		dim:        dim,
+		chunks:     make(map[chunkKey]*chunk),
+		rule:       rule,
+		transition: newTransition(rule),
+	}
+	u.SetNeighborhood(defaultNeighborhood(rule))
+	return u, nil
+}
+
+// SetNeighborhood sets the neighborhood used to count neighbors.
+// Neighborhoods with a radius larger than the chunk size are ignored.
+func (u *SparseUniverse) SetNeighborhood(n core.Neighborhood) {
+	radius := n.Radius(u.dim)
+	if radius > chunkSize {
+		return
+	}
+	u.neighborhood = n
+	u.halo = radius
+	u.precomputePaddedOffsets()
+	u.padded = make([]core.CellState, u.paddedVolume())
+}
+
+// Neighborhood returns the neighborhood used to count neighbors
+func (u *SparseUniverse) Neighborhood() core.Neighborhood {
+	return u.neighborhood
+}
+
+// paddedSize returns the edge length of the padded scratch buffer
+func (u *SparseUniverse) paddedSize() int {
+	return chunkSize + 2*u.halo
+}
+
+// is3D reports whether the universe uses the Z axis
+func (u *SparseUniverse) is3D() bool {
+	return u.dim == core.Dim3D
+}
+
+// chunkVolume returns the number of cells in a chunk
+func (u *SparseUniverse) chunkVolume() int {
+	if u.is3D() {
+		return chunkSize * chunkSize * chunkSize
+	}
+	return chunkSize * chunkSize
+}
+
+// paddedVolume returns the number of cells in the padded scratch buffer
+func (u *SparseUniverse) paddedVolume() int {
+	p := u.paddedSize()
+	if u.is3D() {
+		return p * p * p
+	}
+	return p * p
+}
+
+// precomputePaddedOffsets pre-computes neighbor offsets within the padded buffer
+func (u *SparseUniverse) precomputePaddedOffsets() {
+	p := u.paddedSize()
+	u.paddedOffsets = u.paddedOffsets[:0]
+
+	offsets := u.neighborhood.OffsetsFor(u.dim)
+	for _, c := range offsets {
+		u.paddedOffsets = append(u.paddedOffsets, c.Z*p*p+c.Y*p+c.X)
+	}
+	u.weights = u.transition.neighborWeights(offsets)
+}
+
+// countPadded counts the live neighbors of a cell in the padded buffer,
+// returning the rounded weighted sum for weighted rules
+func (u *SparseUniverse) countPadded(pidx int) int {
+	if u.weights != nil {
+		sum := 0.0
+		for i, offset := range u.paddedOffsets {
+			if u.transition.isLive(u.padded[pidx+offset]) {
+				sum += u.weights[i]
+			}
+		}
+		return roundWeighted(sum)
+	}
+
+	count := 0
+	for _, offset := range u.paddedOffsets {
+		if u.transition.isLive(u.padded[pidx+offset]) {
+			count++
+		}
+	}
+	return count
+}
+
+// keyFor returns the chunk key and local index for a coordinate
+func (u *SparseUniverse) keyFor(x, y, z int) (chunkKey, int) {
+	if !u.is3D() {
+		z = 0
+	}
+	key := chunkKey{X: x >> chunkShift, Y: y >> chunkShift, Z: z >> chunkShift}
+	local := ((z&chunkMask)*chunkSize+(y&chunkMask))*chunkSize + (x & chunkMask)
+	return key, local
+}
+
+// Dimension returns the dimensionality (2D or 3D)
+func (u *SparseUniverse) Dimension() core.Dimension {
+	return u.dim
+}
+
+// Get returns the state of a cell at the given coordinate
+func (u *SparseUniverse) Get(coord core.Coord) core.CellState {
+	key, local := u.keyFor(coord.X, coord.Y, coord.Z)
+	c, ok := u.chunks[key]
+	if !ok {
+		return core.Dead
+	}
+	return c.cells[local]
+}
+
+// Set sets the state of a cell at the given coordinate.
+// Chunks are allocated when a cell comes alive and freed when they become empty.
+func (u *SparseUniverse) Set(coord core.Coord, state core.CellState) {
+	key, local := u.keyFor(coord.X, coord.Y, coord.Z)
+	c, ok := u.chunks[key]
+	if !ok {
+		if state == core.Dead {
+			return
+		}
+		c = u.newChunk()
+		u.chunks[key] = c
+	}
+
+	wasAlive := c.cells[local] != core.Dead
+	c.cells[local] = state
+	isAlive := state != core.Dead
+
+	switch {
+	case !wasAlive && isAlive:
+		c.population++
+	case wasAlive && !isAlive:
+		c.population--
+		if c.population == 0 {
+			delete(u.chunks, key)
+			u.releaseChunk(c)
+		}
+	}
+}
+
+// newChunk returns an empty chunk, reusing a released one when possible
+func (u *SparseUniverse) newChunk() *chunk {
+	if n := len(u.pool); n > 0 {
+		c := u.pool[n-1]
+		u.pool = u.pool[:n-1]
+		return c
+	}
+	return &chunk{cells: make([]core.CellState, u.chunkVolume())}
+}
+
+// releaseChunk clears a chunk and returns it to the pool
+func (u *SparseUniverse) releaseChunk(c *chunk) {
+	for i := range c.cells {
+		c.cells[i] = core.Dead
+	}
+	c.population = 0
+	u.pool = append(u.pool, c)
+}
+
+// Size returns the extent of the bounding box of living cells.
+// An empty universe has size zero.
+func (u *SparseUniverse) Size() core.Coord {
+	minC, maxC, ok := u.Bounds()
+	if !ok {
+		return core.Coord{}
+	}
+	if u.is3D() {
+		return core.NewCoord3D(maxC.X-minC.X+1, maxC.Y-minC.Y+1, maxC.Z-minC.Z+1)
+	}
+	return core.NewCoord2D(maxC.X-minC.X+1, maxC.Y-minC.Y+1)
+}
+
+// Bounds returns the inclusive bounding box of living cells.
+// ok is false if the universe is empty.
+func (u *SparseUniverse) Bounds() (minC, maxC core.Coord, ok bool) {
+	u.ForEachLiving(func(coord core.Coord, state core.CellState) {
+		if !ok {
+			minC, maxC, ok = coord, coord, true
+			return
+		}
+		minC.X, maxC.X = min(minC.X, coord.X), max(maxC.X, coord.X)
+		minC.Y, maxC.Y = min(minC.Y, coord.Y), max(maxC.Y, coord.Y)
+		minC.Z, maxC.Z = min(minC.Z, coord.Z), max(maxC.Z, coord.Z)
+	})
+	return minC, maxC, ok
+}
+
+// ForEachLiving calls fn for every living cell in unspecified order
+func (u *SparseUniverse) ForEachLiving(fn func(coord core.Coord, state core.CellState)) {
+	depth := 1
+	if u.is3D() {
+		depth = chunkSize
+	}
+	for key, c := range u.chunks {
+		for lz := 0; lz < depth; lz++ {
+			for ly := 0; ly < chunkSize; ly++ {
+				for lx := 0; lx < chunkSize; lx++ {
+					state := c.cells[(lz*chunkSize+ly)*chunkSize+lx]
+					if state == core.Dead {
+						continue
+					}
+					coord := core.Coord{
+						X: key.X<<chunkShift + lx,
+						Y: key.Y<<chunkShift + ly,
+						Z: key.Z<<chunkShift + lz,
+					}
+					fn(coord, state)
+				}
+			}
+		}
+	}
+}
+
+// ChunkCount returns the number of allocated chunks
+func (u *SparseUniverse) ChunkCount() int {
+	return len(u.chunks)
+}
+
+// Step executes one generation.
+// Only allocated chunks and their direct neighbors are evaluated.
+func (u *SparseUniverse) Step() {
+	// Collect candidate chunks: every live chunk plus its neighbors
+	zRange := 0
+	if u.is3D() {
+		zRange = 1
+	}
+	candidates := make(map[chunkKey]struct{}, len(u.chunks)*3)
+	for key := range u.chunks {
+		for dz := -zRange; dz <= zRange; dz++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					candidates[chunkKey{X: key.X + dx, Y: key.Y + dy, Z: key.Z + dz}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	next := make(map[chunkKey]*chunk, len(u.chunks))
+	for key := range candidates {
+		if !u.fillPadded(key) {
+			continue // Nothing alive in or around this chunk
+		}
+		if c := u.stepChunk(); c != nil {
+			next[key] = c
+		}
+	}
+
+	// Recycle the previous generation's chunks
+	for _, c := range u.chunks {
+		u.releaseChunk(c)
+	}
+	u.chunks = next
+}
+
+// fillPadded copies a chunk and its halo into the padded buffer.
+// It returns false if every copied cell is dead.
+func (u *SparseUniverse) fillPadded(key chunkKey) bool {
+	p := u.paddedSize()
+	baseX := key.X<<chunkShift - u.halo
+	baseY := key.Y<<chunkShift - u.halo
+	baseZ := key.Z<<chunkShift - u.halo
+
+	depth := p
+	if !u.is3D() {
+		depth = 1
+		baseZ = 0
+	}
+
+	anyAlive := false
+	i := 0
+	for pz := 0; pz < depth; pz++ {
+		for py := 0; py < p; py++ {
+			var (
+				lastKey   chunkKey
+				lastChunk *chunk
+				haveLast  bool
+			)
+			for px := 0; px < p; px++ {
+				key, local := u.keyFor(baseX+px, baseY+py, baseZ+pz)
+				if !haveLast || key != lastKey {
+					lastKey, lastChunk, haveLast = key, u.chunks[key], true
+				}
+				state := core.Dead
+				if lastChunk != nil {
+					state = lastChunk.cells[local]
+				}
+				if state != core.Dead {
+					anyAlive = true
+				}
+				u.padded[i] = state
+				i++
+			}
+		}
+	}
+	return anyAlive
+}
+
+// stepChunk computes the next generation for the chunk in the padded buffer.
+// It returns nil if the resulting chunk is empty.
+func (u *SparseUniverse) stepChunk() *chunk {
+	p := u.paddedSize()
+	h := u.halo
+	depth := 1
+	zPad := 0
+	if u.is3D() {
+		depth = chunkSize
+		zPad = h
+	}
+
+	var result *chunk
+	for lz := 0; lz < depth; lz++ {
+		for ly := 0; ly < chunkSize; ly++ {
+			for lx := 0; lx < chunkSize; lx++ {
+				pidx := ((lz+zPad)*p+ly+h)*p + lx + h
+
+				neighbors := u.countPadded(pidx)
+
+				next := u.transition.next(u.padded[pidx], neighbors)
+				if next != core.Dead {
+					if result == nil {
+						result = u.newChunk()
+					}
+					result.cells[(lz*chunkSize+ly)*chunkSize+lx] = next
+					result.population++
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Clone creates a deep copy of the universe
+func (u *SparseUniverse) Clone() core.Universe {
+	clone, _ := newSparse(u.dim, u.rule) // The rule was accepted when u was created
+	clone.SetNeighborhood(u.neighborhood)
+	for key, c := range u.chunks {
+		cc := &chunk{
+			cells:      make([]core.CellState, len(c.cells)),
+			population: c.population,
+		}
+		copy(cc.cells, c.cells)
+		clone.chunks[key] = cc
+	}
+	return clone
+}
+
+// Clear resets all cells to dead state and frees every chunk
+func (u *SparseUniverse) Clear() {
+	for _, c := range u.chunks {
+		u.releaseChunk(c)
+	}
+	u.chunks = make(map[chunkKey]*chunk)
+}
+
+// CountLiving returns the number of living cells
+func (u *SparseUniverse) CountLiving() int {
+	count := 0
+	for _, c := range u.chunks {
+		count += c.population
+	}
+	return count
+}
@@ -0,0 +1,269 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"math/rand"
+	"testing"
+)
+
+// addGlider places a south-east moving glider with its top-left corner at (x, y)
+func addGlider(u core.Universe, x, y int) {
+	u.Set(core.NewCoord2D(x+1, y), core.Alive)
+	u.Set(core.NewCoord2D(x+2, y+1), core.Alive)
+	u.Set(core.NewCoord2D(x, y+2), core.Alive)
+	u.Set(core.NewCoord2D(x+1, y+2), core.Alive)
+	u.Set(core.NewCoord2D(x+2, y+2), core.Alive)
+}
+
+func TestNewSparse(t *testing.T) {
+	u2, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	if u2.Dimension() != core.Dim2D {
+		t.Errorf("Expected Dim2D, got %v", u2.Dimension())
+	}
+
+	u3, err := NewSparse3D(rules.Life3D_B6S567{})
+	if err != nil {
+		t.Fatalf("NewSparse3D returned error: %v", err)
+	}
+	if u3.Dimension() != core.Dim3D {
+		t.Errorf("Expected Dim3D, got %v", u3.Dimension())
+	}
+
+	if u2.CountLiving() != 0 || u2.ChunkCount() != 0 {
+		t.Error("New sparse universe should be empty")
+	}
+	if size := u2.Size(); size != (core.Coord{}) {
+		t.Errorf("Empty universe should have zero size, got %v", size)
+	}
+
+	b0, err := rules.Parse("B03/S23")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if _, err := NewSparse2D(b0); err == nil {
+		t.Error("NewSparse2D should reject a B0 rule")
+	}
+	if _, err := NewSparse3D(b0); err == nil {
+		t.Error("NewSparse3D should reject a B0 rule")
+	}
+}
+
+func TestSparseUniverse_GetSet(t *testing.T) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+
+	coords := []core.Coord{
+		core.NewCoord2D(0, 0),
+		core.NewCoord2D(-1, -1),
+		core.NewCoord2D(-17, 33),
+		core.NewCoord2D(1000000, -1000000),
+	}
+	for _, c := range coords {
+		u.Set(c, core.Alive)
+		if u.Get(c) != core.Alive {
+			t.Errorf("Cell at %v should be alive", c)
+		}
+	}
+
+	if u.CountLiving() != len(coords) {
+		t.Errorf("Expected %d living cells, got %d", len(coords), u.CountLiving())
+	}
+	if u.ChunkCount() != 4 {
+		t.Errorf("Expected 4 chunks, got %d", u.ChunkCount())
+	}
+
+	// Setting cells dead frees their chunks
+	for _, c := range coords {
+		u.Set(c, core.Dead)
+	}
+	if u.ChunkCount() != 0 {
+		t.Errorf("Empty chunks should be freed, got %d", u.ChunkCount())
+	}
+
+	// Setting a dead cell in an unallocated chunk does not allocate
+	u.Set(core.NewCoord2D(500, 500), core.Dead)
+	if u.ChunkCount() != 0 {
+		t.Error("Setting a dead cell should not allocate a chunk")
+	}
+}
+
+func TestSparseUniverse_Bounds(t *testing.T) {
+	u, err := NewSparse3D(rules.Life3D_B6S567{})
+	if err != nil {
+		t.Fatalf("NewSparse3D returned error: %v", err)
+	}
+	u.Set(core.NewCoord3D(-5, 2, 7), core.Alive)
+	u.Set(core.NewCoord3D(4, -3, 9), core.Alive)
+
+	minC, maxC, ok := u.Bounds()
+	if !ok {
+		t.Fatal("Bounds should be defined for a non-empty universe")
+	}
+	if minC != core.NewCoord3D(-5, -3, 7) || maxC != core.NewCoord3D(4, 2, 9) {
+		t.Errorf("Unexpected bounds %v..%v", minC, maxC)
+	}
+	if size := u.Size(); size != core.NewCoord3D(10, 6, 3) {
+		t.Errorf("Unexpected size %v", size)
+	}
+}
+
+func TestSparseUniverse_GliderTravelsWithoutWalls(t *testing.T) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	addGlider(u, 0, 0)
+
+	const generations = 20000
+	for i := 0; i < generations; i++ {
+		u.Step()
+		if u.ChunkCount() > 4 {
+			t.Fatalf("Generation %d: glider should occupy at most 4 chunks, got %d", i, u.ChunkCount())
+		}
+	}
+
+	if u.CountLiving() != 5 {
+		t.Fatalf("Glider should keep 5 cells, got %d", u.CountLiving())
+	}
+
+	// A glider moves one cell diagonally every 4 generations
+	minC, _, _ := u.Bounds()
+	want := generations / 4
+	if minC.X != want || minC.Y != want {
+		t.Errorf("Expected glider at (%d,%d), got (%d,%d)", want, want, minC.X, minC.Y)
+	}
+}
+
+func TestSparseUniverse_MatchesUniverse2D(t *testing.T) {
+	const size = 96
+	dense := New2D(size, size, rules.ConwayRule{})
+	sparse, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+
+	// Random soup in the middle of the grid, crossing chunk borders
+	r := rand.New(rand.NewSource(1))
+	for y := 40; y < 56; y++ {
+		for x := 40; x < 56; x++ {
+			if r.Intn(2) == 1 {
+				dense.Set(core.NewCoord2D(x, y), core.Alive)
+				sparse.Set(core.NewCoord2D(x-48, y-48), core.Alive)
+			}
+		}
+	}
+
+	// Stay well within the dense grid so its walls do not matter
+	for step := 0; step < 30; step++ {
+		dense.Step()
+		sparse.Step()
+
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if dense.Get(core.NewCoord2D(x, y)) != sparse.Get(core.NewCoord2D(x-48, y-48)) {
+					t.Fatalf("Step %d: cell (%d,%d) differs", step, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestSparseUniverse_MatchesUniverse3D(t *testing.T) {
+	const size = 40
+	rule := rules.Life3D_B6S567{}
+	dense := New3D(size, size, size, rule)
+	sparse, err := NewSparse3D(rule)
+	if err != nil {
+		t.Fatalf("NewSparse3D returned error: %v", err)
+	}
+
+	r := rand.New(rand.NewSource(7))
+	for z := 14; z < 26; z++ {
+		for y := 14; y < 26; y++ {
+			for x := 14; x < 26; x++ {
+				if r.Intn(3) == 0 {
+					dense.Set(core.NewCoord3D(x, y, z), core.Alive)
+					sparse.Set(core.NewCoord3D(x-20, y-20, z-20), core.Alive)
+				}
+			}
+		}
+	}
+
+	for step := 0; step < 6; step++ {
+		dense.Step()
+		sparse.Step()
+
+		if dense.CountLiving() != sparse.CountLiving() {
+			t.Fatalf("Step %d: population differs: dense=%d, sparse=%d",
+				step, dense.CountLiving(), sparse.CountLiving())
+		}
+		for z := 0; z < size; z++ {
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					if dense.Get(core.NewCoord3D(x, y, z)) != sparse.Get(core.NewCoord3D(x-20, y-20, z-20)) {
+						t.Fatalf("Step %d: cell (%d,%d,%d) differs", step, x, y, z)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSparseUniverse_FreesEmptyChunks(t *testing.T) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+
+	// A lone cell dies immediately
+	u.Set(core.NewCoord2D(100, 100), core.Alive)
+	u.Step()
+
+	if u.CountLiving() != 0 {
+		t.Errorf("Expected extinction, got %d living cells", u.CountLiving())
+	}
+	if u.ChunkCount() != 0 {
+		t.Errorf("Expected all chunks to be freed, got %d", u.ChunkCount())
+	}
+}
+
+func TestSparseUniverse_CloneAndClear(t *testing.T) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	addGlider(u, -3, -3)
+
+	clone := u.Clone()
+	u.Clear()
+
+	if u.CountLiving() != 0 || u.ChunkCount() != 0 {
+		t.Error("Clear should remove all cells and chunks")
+	}
+	if clone.CountLiving() != 5 {
+		t.Errorf("Clone should keep 5 cells, got %d", clone.CountLiving())
+	}
+
+	clone.Step()
+	if clone.CountLiving() != 5 {
+		t.Errorf("Cloned glider should keep 5 cells after a step, got %d", clone.CountLiving())
+	}
+}
+
+func BenchmarkSparseUniverse_Step_Glider(b *testing.B) {
+	u, err := NewSparse2D(rules.ConwayRule{})
+	if err != nil {
+		b.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	addGlider(u, 0, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.Step()
+	}
+}
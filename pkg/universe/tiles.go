@@ -0,0 +1,143 @@
+package universe
+
+import "sync/atomic"
+
+// Tile edge lengths used to skip quiescent regions
+const (
+	tileSize2D = 16
+	tileSize3D = 8
+)
+
+// tileMap tracks which tiles of a grid changed in the last generation, so a
+// step only re-evaluates those tiles and the tiles within reach of their
+// neighborhoods. A tile whose surroundings did not change cannot change
+// either, and since the back buffer holds the previous generation it
+// already contains the tile's next state.
+type tileMap struct {
+	size       int // Tile edge length in cells
+	nx, ny, nz int // Tile counts per axis (nz is 1 in 2D)
+	changed    []atomic.Bool
+	active     []bool
+	all        bool // Every tile is active for the next step
+	numActive  int
+}
+
+// newTileMap creates a tile map for a width×height×depth grid with every tile active
+func newTileMap(width, height, depth, size int) *tileMap {
+	nx := (width + size - 1) / size
+	ny := (height + size - 1) / size
+	nz := (depth + size - 1) / size
+	if depth == 1 {
+		nz = 1
+	}
+	return &tileMap{
+		size:    size,
+		nx:      nx,
+		ny:      ny,
+		nz:      nz,
+		changed: make([]atomic.Bool, nx*ny*nz),
+		active:  make([]bool, nx*ny*nz),
+		all:     true,
+	}
+}
+
+// index returns the tile containing cell (x, y, z)
+func (t *tileMap) index(x, y, z int) int {
+	return (z/t.size*t.ny+y/t.size)*t.nx + x/t.size
+}
+
+// markCell records that a cell changed outside of a step
+func (t *tileMap) markCell(x, y, z int) {
+	t.changed[t.index(x, y, z)].Store(true)
+}
+
+// markAll makes every tile active for the next step
+func (t *tileMap) markAll() {
+	t.all = true
+}
+
+// isActive reports whether the tile containing cell (x, y, z) must be evaluated
+func (t *tileMap) isActive(x, y, z int) bool {
+	return t.active[t.index(x, y, z)]
+}
+
+// total returns the number of tiles
+func (t *tileMap) total() int {
+	return len(t.active)
+}
+
+// prepare decides which tiles the next step evaluates: every changed tile and
+// the tiles within radius cells of it. With a boundary other than dead, a
+// change near any edge can be seen across the grid, so it activates the
+// tiles along every edge. The change flags are reset for the step.
+func (t *tileMap) prepare(radius int, boundary Boundary) {
+	if t.all {
+		for i := range t.active {
+			t.active[i] = true
+			t.changed[i].Store(false)
+		}
+		t.all = false
+		t.numActive = len(t.active)
+		return
+	}
+
+	clear(t.active)
+	halo := (radius + t.size - 1) / t.size
+	haloZ := halo
+	if t.nz == 1 {
+		haloZ = 0
+	}
+	edgesActive := false
+
+	for tz := 0; tz < t.nz; tz++ {
+		for ty := 0; ty < t.ny; ty++ {
+			for tx := 0; tx < t.nx; tx++ {
+				i := (tz*t.ny+ty)*t.nx + tx
+				if !t.changed[i].Load() {
+					continue
+				}
+				t.changed[i].Store(false)
+				t.activateBox(tx-halo, ty-halo, tz-haloZ, tx+halo, ty+halo, tz+haloZ)
+				if !boundary.IsDead() && t.nearEdge(tx, ty, tz, halo, haloZ) {
+					edgesActive = true
+				}
+			}
+		}
+	}
+
+	if edgesActive {
+		for tz := 0; tz < t.nz; tz++ {
+			for ty := 0; ty < t.ny; ty++ {
+				for tx := 0; tx < t.nx; tx++ {
+					if t.nearEdge(tx, ty, tz, halo, haloZ) {
+						t.active[(tz*t.ny+ty)*t.nx+tx] = true
+					}
+				}
+			}
+		}
+	}
+
+	t.numActive = 0
+	for _, a := range t.active {
+		if a {
+			t.numActive++
+		}
+	}
+}
+
+// activateBox marks the tiles in an inclusive box active, clipped to the grid
+func (t *tileMap) activateBox(x0, y0, z0, x1, y1, z1 int) {
+	for tz := max(z0, 0); tz <= min(z1, t.nz-1); tz++ {
+		for ty := max(y0, 0); ty <= min(y1, t.ny-1); ty++ {
+			for tx := max(x0, 0); tx <= min(x1, t.nx-1); tx++ {
+				t.active[(tz*t.ny+ty)*t.nx+tx] = true
+			}
+		}
+	}
+}
+
+// nearEdge reports whether a tile lies within halo tiles of a grid face
+func (t *tileMap) nearEdge(tx, ty, tz, halo, haloZ int) bool {
+	return tx < halo || tx >= t.nx-halo || ty < halo || ty >= t.ny-halo ||
+		(t.nz > 1 && (tz < haloZ || tz >= t.nz-haloZ))
+}
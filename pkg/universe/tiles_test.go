@@ -0,0 +1,169 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"testing"
+)
+
+// stepAllTiles steps a universe with every tile evaluated, as a reference
+func stepAllTiles(u *Universe2D) {
+	u.tiles.markAll()
+	u.Step()
+}
+
+func TestUniverse2D_TilesMatchFullStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		boundary Boundary
+	}{
+		{"conway dead", "B3/S23", Boundary{}},
+		{"conway torus", "B3/S23", TorusBoundary()},
+		{"highlife klein", "B36/S23", KleinBottleBoundary()},
+		{"seeds mirror", "B2/S", UniformBoundary(BoundaryMirror)},
+		{"brians brain", "brians-brain", TorusBoundary()},
+		{"bosco torus", "bosco", TorusBoundary()},
+		{"b0 rule", "B0/S8", Boundary{}},
+		{"hensel", "B3/S2-i34q", ProjectivePlaneBoundary()},
+		{"wireworld", "wireworld", Boundary{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := rules.Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.rule, err)
+			}
+			tiled := New2D(70, 45, rule)
+			tiled.SetBoundary(tt.boundary)
+			tiled.RandomizeWith(RandomOptions{
+				Seed:    4,
+				Density: 0.4,
+				Min:     core.NewCoord2D(2, 30),
+				Max:     core.NewCoord2D(20, 45),
+			})
+			full := tiled.Clone().(*Universe2D)
+
+			for gen := 1; gen <= 40; gen++ {
+				if gen == 20 {
+					// Cells set between steps wake their tiles up
+					tiled.Set(core.NewCoord2D(60, 5), core.Alive)
+					full.Set(core.NewCoord2D(60, 5), core.Alive)
+				}
+				tiled.Step()
+				stepAllTiles(full)
+				for i := range full.cells {
+					if tiled.cells[i] != full.cells[i] {
+						t.Fatalf("Generation %d: cell (%d,%d) differs from a full step", gen, i%70, i/70)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUniverse2D_GliderActivatesFewTiles(t *testing.T) {
+	u := New2D(256, 256, rules.ConwayRule{})
+	addGlider(u, 100, 100)
+
+	u.Step() // Every tile is evaluated after the universe is set up
+	if u.ActiveTiles() != u.TileCount() {
+		t.Errorf("First step should evaluate all %d tiles, got %d", u.TileCount(), u.ActiveTiles())
+	}
+
+	u.Step()
+	if u.ActiveTiles() > 9 {
+		t.Errorf("A glider should keep at most 9 of %d tiles active, got %d", u.TileCount(), u.ActiveTiles())
+	}
+
+	// A still life stops changing, so its tiles go quiet
+	still := New2D(64, 64, rules.ConwayRule{})
+	for _, c := range [][2]int{{30, 30}, {31, 30}, {30, 31}, {31, 31}} {
+		still.Set(core.NewCoord2D(c[0], c[1]), core.Alive)
+	}
+	still.Step()
+	still.Step()
+	if still.ActiveTiles() != 0 {
+		t.Errorf("A block should leave no active tiles, got %d", still.ActiveTiles())
+	}
+	if still.GetAge(30, 30) != 2 {
+		t.Errorf("Cells in quiet tiles should keep aging, got age %d", still.GetAge(30, 30))
+	}
+}
+
+func TestUniverse3D_TilesMatchFullStep(t *testing.T) {
+	for _, boundary := range []Boundary{{}, TorusBoundary()} {
+		tiled := New3D(20, 18, 17, rules.Life3D_B6S567{})
+		tiled.SetBoundary(boundary)
+		RandomFill(tiled, RandomOptions{
+			Seed:    8,
+			Density: 0.3,
+			Min:     core.NewCoord3D(0, 0, 0),
+			Max:     core.NewCoord3D(7, 7, 7),
+		})
+		full := tiled.Clone().(*Universe3D)
+
+		for gen := 1; gen <= 20; gen++ {
+			tiled.StepParallel()
+			full.tiles.markAll()
+			full.Step()
+			for i := range full.cells {
+				if tiled.cells[i] != full.cells[i] {
+					t.Fatalf("Boundary %v, generation %d: cell %d differs from a full step", boundary, gen, i)
+				}
+			}
+		}
+	}
+}
+
+func TestUniverse25D_TilesAfterLayerInteraction(t *testing.T) {
+	u := New25D(30, 30, 3, rules.ConwayRule{})
+	u.RandomizeWith(RandomOptions{Seed: 5, Density: 0.35})
+	u.SetLayerInteraction(true)
+	for i := 0; i < 3; i++ {
+		u.Step()
+	}
+
+	// Independent steps must see the changes the interaction steps made
+	fresh := u.Clone().(*Universe25D)
+	u.SetLayerInteraction(false)
+	fresh.SetLayerInteraction(false)
+	for gen := 1; gen <= 5; gen++ {
+		u.Step()
+		fresh.Step()
+		for z := 0; z < 3; z++ {
+			for y := 0; y < 30; y++ {
+				for x := 0; x < 30; x++ {
+					c := core.NewCoord3D(x, y, z)
+					if u.Get(c) != fresh.Get(c) {
+						t.Fatalf("Generation %d: cell (%d,%d,%d) differs from a fresh universe", gen, x, y, z)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestUniverse3D_GliderActivatesFewTiles(t *testing.T) {
+	u := New3D(32, 32, 32, rules.Life3D_B6S567{})
+	for _, c := range [][3]int{{15, 15, 15}, {16, 15, 15}, {15, 16, 15}, {16, 16, 15}} {
+		u.Set(core.NewCoord3D(c[0], c[1], c[2]), core.Alive)
+	}
+
+	u.Step()
+	u.Step()
+	if u.ActiveTiles() >= u.TileCount() {
+		t.Errorf("A small pattern should not keep all %d tiles active, got %d", u.TileCount(), u.ActiveTiles())
+	}
+}
+
+func BenchmarkUniverse2D_Step_GliderIn1024(b *testing.B) {
+	u := New2D(1024, 1024, rules.ConwayRule{})
+	addGlider(u, 500, 500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.Step()
+	}
+}
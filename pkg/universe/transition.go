@@ -0,0 +1,87 @@
+package universe
+
+import "golife/pkg/core"
+
+// transition applies a rule to a single cell, handling the decay states
+// of Generations rules (core.MultiStateRule)
+type transition struct {
+	rule       core.Rule
+	liveMin    core.CellState // States at or above liveMin count as live neighbors
+	firstDying core.CellState // State entered by a live cell that fails to survive
+	multiState bool
+}
+
+// newTransition prepares the transition for a rule
+func newTransition(rule core.Rule) transition {
+	t := transition{rule: rule, liveMin: 1, firstDying: core.Dead}
+	if ms, ok := rule.(core.MultiStateRule); ok && ms.States() > 2 {
+		t.multiState = true
+		t.liveMin = core.Alive
+		t.firstDying = core.CellState(ms.States() - 2)
+	}
+	return t
+}
+
+// isLive reports whether a state counts as a live neighbor
+func (t transition) isLive(state core.CellState) bool {
+	return state >= t.liveMin
+}
+
+// isDying reports whether a state is an intermediate decay state
+func (t transition) isDying(state core.CellState) bool {
+	return t.multiState && state != core.Dead && state != core.Alive
+}
+
+// next returns the next state of a cell with the given number of live neighbors
+func (t transition) next(current core.CellState, neighbors int) core.CellState {
+	if t.isDying(current) {
+		return current - 1
+	}
+	if current == core.Dead {
+		return t.resolve(current, t.rule.ShouldBirth(neighbors))
+	}
+	return t.resolve(current, t.rule.ShouldSurvive(neighbors, current))
+}
+
+// resolve returns the next state of a non-dying cell once the birth/survival
+// decision has been made
+func (t transition) resolve(current core.CellState, alive bool) core.CellState {
+	if alive {
+		return core.Alive
+	}
+	if current == core.Dead {
+		return core.Dead
+	}
+	return t.firstDying
+}
+
+// defaultNeighborhood returns the neighborhood a rule is defined on,
+// or the Moore neighborhood of range 1
+func defaultNeighborhood(rule core.Rule) core.Neighborhood {
+	if nr, ok := rule.(core.NeighborhoodRule); ok {
+		return nr.Neighborhood()
+	}
+	return core.MooreNeighborhood(1)
+}
+
+// neighborWeights returns the rule's NeighborWeight for each offset, or nil
+// when every weight is 1 and neighbors can simply be counted
+func (t transition) neighborWeights(offsets []core.Coord) []float64 {
+	weights := make([]float64, len(offsets))
+	uniform := true
+	for i, o := range offsets {
+		weights[i] = t.rule.NeighborWeight(o.Distance())
+		if weights[i] != 1.0 {
+			uniform = false
+		}
+	}
+	if uniform {
+		return nil
+	}
+	return weights
+}
+
+// roundWeighted converts a weighted neighbor sum into the count passed to the rule
+func roundWeighted(sum float64) int {
+	return int(sum + 0.5) // Round to nearest int
+}
@@ -3,6 +3,7 @@ package universe
 import (
 	"golife/pkg/core"
 	"golife/pkg/rules"
+	"time"
 )
 
 // Universe25D represents a 2.5D universe with multiple 2D layers
@@ -12,7 +13,11 @@ type Universe25D struct {
 	layerInteraction     bool
 	verticalWeight       float64 // Weight for vertical neighbors (0.0-1.0) - deprecated, use interactionRule
 	rule                 core.Rule
+	transition           transition                 // Applies Generations decay between layers
 	interactionRule      rules.LayerInteractionRule // Optional: layer interaction rule
+	boundary             Boundary                   // X/Y apply within layers, Z between layers
+	neighborhood         core.Neighborhood          // 2D neighborhood used within and between layers
+	workers              int                        // Goroutines used by StepParallel; 0 means one per CPU
 }
 
 // New25D creates a new 2.5D universe with the given dimensions and rule
@@ -33,7 +38,9 @@ func New25D(width, height, depth int, rule core.Rule) *Universe25D {
 		layerInteraction: false,
 		verticalWeight:   0.3, // Deprecated: kept for backward compatibility
 		rule:             rule,
+		transition:       newTransition(rule),
 		interactionRule:  defaultInteractionRule,
+		neighborhood:     defaultNeighborhood(rule),
 	}
 }
 
@@ -62,6 +69,11 @@ func (u *Universe25D) Size() core.Coord {
 	return core.NewCoord3D(u.width, u.height, u.depth)
 }
 
+// Rule returns the rule driving this universe
+func (u *Universe25D) Rule() core.Rule {
+	return u.rule
+}
+
 // GetLayer returns a specific layer
 func (u *Universe25D) GetLayer(z int) *Universe2D {
 	if z >= 0 && z < u.depth {
@@ -95,114 +107,201 @@ func (u *Universe25D) GetInteractionRule() rules.LayerInteractionRule {
 	return u.interactionRule
 }
 
+// SetBoundary sets the boundary topology.
+// The X and Y modes apply within each layer, the Z mode to vertical neighbors.
+func (u *Universe25D) SetBoundary(b Boundary) {
+	u.boundary = b
+	for _, layer := range u.layers {
+		layer.SetBoundary(b)
+	}
+}
+
+// Boundary returns the boundary topology
+func (u *Universe25D) Boundary() Boundary {
+	return u.boundary
+}
+
+// SetNeighborhood sets the 2D neighborhood used within each layer.
+// Vertical neighbors are the cells directly above and below plus the cells
+// at the same neighborhood offsets in the adjacent layers.
+func (u *Universe25D) SetNeighborhood(n core.Neighborhood) {
+	u.neighborhood = n
+	for _, layer := range u.layers {
+		layer.SetNeighborhood(n)
+	}
+}
+
+// Neighborhood returns the 2D neighborhood used within each layer
+func (u *Universe25D) Neighborhood() core.Neighborhood {
+	return u.neighborhood
+}
+
+// stateAt returns the state at a possibly out-of-range coordinate
+// according to the boundary topology
+func (u *Universe25D) stateAt(x, y, z int) core.CellState {
+	if x < 0 || x >= u.width || y < 0 || y >= u.height || z < 0 || z >= u.depth {
+		var outside core.CellState
+		var ok bool
+		x, y, z, outside, ok = u.boundary.resolve(x, y, z, u.width, u.height, u.depth)
+		if !ok {
+			return outside
+		}
+	}
+	return u.layers[z].cells[y*u.width+x]
+}
+
 // Step executes one generation
 func (u *Universe25D) Step() {
-	if u.layerInteraction {
-		u.stepWithInteraction()
-	} else {
-		u.stepIndependent()
+	u.prepareLayers()
+	u.stepRows(0, u.depth*u.height)
+	u.swapLayers()
+}
+
+// StepParallel executes one generation using parallel processing.
+// The rows of all layers are divided into bands that are processed
+// concurrently by SetWorkers workers, with or without layer interaction;
+// the result is identical to Step.
+func (u *Universe25D) StepParallel() {
+	rows := u.depth * u.height
+	u.prepareLayers()
+	parallelBands(rows, workerCount(u.workers, rows), u.stepRows)
+	u.swapLayers()
+}
+
+// SetWorkers sets the number of goroutines StepParallel uses.
+// 0, the default, uses one per CPU.
+func (u *Universe25D) SetWorkers(n int) {
+	u.workers = n
+}
+
+// stepRows computes rows [start, end) of the next generation into the layers'
+// back buffers, numbering the rows of all layers as z*height + y
+func (u *Universe25D) stepRows(start, end int) {
+	for z := start / u.height; z < u.depth && z*u.height < end; z++ {
+		yStart := max(start-z*u.height, 0)
+		yEnd := min(end-z*u.height, u.height)
+		if u.layerInteraction {
+			u.stepRowsWithInteraction(z, yStart, yEnd)
+		} else {
+			u.layers[z].stepRows(yStart, yEnd) // Each layer evolves independently
+		}
 	}
 }
 
-// stepIndependent processes each layer independently
-func (u *Universe25D) stepIndependent() {
-	// Each layer evolves independently
+// prepareLayers decides which tiles of each layer the next step evaluates.
+// Independent layers skip their quiescent tiles; with layer interaction a
+// tile also depends on the layers above and below, so every tile is evaluated.
+func (u *Universe25D) prepareLayers() {
 	for _, layer := range u.layers {
-		layer.Step()
+		if u.layerInteraction {
+			layer.tiles.markAll()
+		}
+		layer.tiles.prepare(layer.radius, layer.boundary)
+		layer.counts.reset()
 	}
 }
 
-// stepWithInteraction processes layers with vertical influence
-func (u *Universe25D) stepWithInteraction() {
-	// Create temporary storage for new states
-	newLayers := make([]*Universe2D, u.depth)
-	for z := 0; z < u.depth; z++ {
-		newLayers[z] = New2D(u.width, u.height, u.rule)
+// swapLayers makes the back buffers of every layer current
+func (u *Universe25D) swapLayers() {
+	for _, layer := range u.layers {
+		layer.swap()
 	}
+}
 
-	// Process each cell considering vertical neighbors
-	for z := 0; z < u.depth; z++ {
-		for y := 0; y < u.height; y++ {
-			for x := 0; x < u.width; x++ {
-				coord2D := core.NewCoord2D(x, y)
-
-				// Count horizontal neighbors (same layer)
-				horizontalNeighbors := u.layers[z].countNeighbors(x, y)
-
-				// Count vertical neighbors (upper and lower layers)
-				verticalNeighbors := u.countVerticalNeighbors(x, y, z)
-
-				// Get current and adjacent layer states
-				currentState := u.layers[z].Get(coord2D)
-				var upperState, lowerState core.CellState
-				if z > 0 {
-					upperState = u.layers[z-1].Get(coord2D)
-				}
-				if z < u.depth-1 {
-					lowerState = u.layers[z+1].Get(coord2D)
-				}
-
-				// Use interaction rule to calculate effective neighbor count
-				neighborCount := u.interactionRule.CalculateNeighborCount(
-					horizontalNeighbors, verticalNeighbors,
-					currentState, upperState, lowerState,
-				)
-
-				// Apply rule with layer interaction
-				var newState core.CellState
-				if currentState == core.Dead {
-					if u.interactionRule.ShouldBirth(neighborCount, upperState, lowerState) {
-						newState = core.Alive
-					} else {
-						newState = core.Dead
-					}
-				} else {
-					if u.interactionRule.ShouldSurvive(neighborCount, currentState, upperState, lowerState) {
-						newState = core.Alive
-					} else {
-						newState = core.Dead
-					}
-				}
-
-				newLayers[z].Set(coord2D, newState)
-			}
-		}
+// ActiveTiles returns the number of tiles evaluated by the last step across all layers
+func (u *Universe25D) ActiveTiles() int {
+	count := 0
+	for _, layer := range u.layers {
+		count += layer.ActiveTiles()
 	}
+	return count
+}
 
-	// Copy new states to layers
-	u.layers = newLayers
+// Births returns the number of dead cells that came alive in the last step
+// across all layers
+func (u *Universe25D) Births() int {
+	count := 0
+	for _, layer := range u.layers {
+		count += layer.Births()
+	}
+	return count
 }
 
-// countVerticalNeighbors counts alive cells in the 9 positions above and below
-func (u *Universe25D) countVerticalNeighbors(x, y, z int) int {
+// Deaths returns the number of cells that became dead in the last step
+// across all layers
+func (u *Universe25D) Deaths() int {
 	count := 0
+	for _, layer := range u.layers {
+		count += layer.Deaths()
+	}
+	return count
+}
+
+// stepRowsWithInteraction computes rows [yStart, yEnd) of layer z with
+// vertical influence from the layers above and below
+func (u *Universe25D) stepRowsWithInteraction(z, yStart, yEnd int) {
+	layer := u.layers[z]
+	births, deaths := 0, 0
+	for y := yStart; y < yEnd; y++ {
+		for x := 0; x < u.width; x++ {
+			idx := y*u.width + x
+
+			// Count horizontal neighbors (same layer)
+			horizontalNeighbors := layer.countNeighbors(x, y)
+
+			// Count vertical neighbors (upper and lower layers)
+			verticalNeighbors := u.countVerticalNeighbors(x, y, z)
+
+			// Get current and adjacent layer states
+			currentState := layer.cells[idx]
+			upperState := u.stateAt(x, y, z-1)
+			lowerState := u.stateAt(x, y, z+1)
+
+			// Use interaction rule to calculate effective neighbor count
+			neighborCount := u.interactionRule.CalculateNeighborCount(
+				horizontalNeighbors, verticalNeighbors,
+				currentState, upperState, lowerState,
+			)
+
+			// Apply rule with layer interaction; dying Generations states decay on their own
+			var newState core.CellState
+			if u.transition.isDying(currentState) {
+				newState = currentState - 1
+			} else if currentState == core.Dead {
+				newState = u.transition.resolve(currentState,
+					u.interactionRule.ShouldBirth(neighborCount, upperState, lowerState))
+			} else {
+				newState = u.transition.resolve(currentState,
+					u.interactionRule.ShouldSurvive(neighborCount, currentState, upperState, lowerState))
+			}
 
-	// Check layer above (z-1)
-	if z > 0 {
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				nx := x + dx
-				ny := y + dy
-				if nx >= 0 && nx < u.width && ny >= 0 && ny < u.height {
-					if u.layers[z-1].Get(core.NewCoord2D(nx, ny)) > core.Dead {
-						count++
-					}
-				}
+			if newState != currentState {
+				// Lets the layer skip quiescent tiles once interaction is off
+				layer.tiles.markCell(x, y, 0)
+				tally(currentState, newState, &births, &deaths)
 			}
+			layer.setNext(idx, newState)
 		}
 	}
+	layer.counts.add(births, deaths)
+}
+
+// countVerticalNeighbors counts alive cells above and below: the cell directly
+// above/below plus the neighborhood offsets (9 positions per layer for Moore).
+// Vertical neighbors are not weighted by NeighborWeight; the layer interaction
+// rule decides how much they contribute.
+func (u *Universe25D) countVerticalNeighbors(x, y, z int) int {
+	count := 0
+	offsets := u.layers[z].offsets
 
-	// Check layer below (z+1)
-	if z < u.depth-1 {
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				nx := x + dx
-				ny := y + dy
-				if nx >= 0 && nx < u.width && ny >= 0 && ny < u.height {
-					if u.layers[z+1].Get(core.NewCoord2D(nx, ny)) > core.Dead {
-						count++
-					}
-				}
+	// Check layer above (z-1) and layer below (z+1)
+	for _, nz := range [2]int{z - 1, z + 1} {
+		if u.transition.isLive(u.stateAt(x, y, nz)) {
+			count++
+		}
+		for _, offset := range offsets {
+			if u.transition.isLive(u.stateAt(x+offset.X, y+offset.Y, nz)) {
+				count++
 			}
 		}
 	}
@@ -215,6 +314,10 @@ func (u *Universe25D) Clone() core.Universe {
 	clone := New25D(u.width, u.height, u.depth, u.rule)
 	clone.layerInteraction = u.layerInteraction
 	clone.verticalWeight = u.verticalWeight
+	clone.interactionRule = u.interactionRule
+	clone.workers = u.workers
+	clone.SetBoundary(u.boundary)
+	clone.SetNeighborhood(u.neighborhood)
 
 	for z := 0; z < u.depth; z++ {
 		for y := 0; y < u.height; y++ {
@@ -245,11 +348,21 @@ func (u *Universe25D) CountLiving() int {
 	return count
 }
 
-// Randomize fills all layers with random cells
+// Randomize fills all layers with random cells at DefaultDensity, seeded
+// from the clock. Use RandomizeWith for reproducible fills.
 func (u *Universe25D) Randomize() {
+	u.RandomizeWith(RandomOptions{Seed: time.Now().UnixNano()})
+}
+
+// RandomizeWith clears all layers and fills the volume with RandomFill.
+// Live cells start with age 1. It returns the number of live cells placed.
+func (u *Universe25D) RandomizeWith(opts RandomOptions) int {
+	u.Clear()
+	placed := RandomFill(u, opts)
 	for _, layer := range u.layers {
-		layer.Randomize()
+		layer.startAges()
 	}
+	return placed
 }
 
 // CountLivingInLayer returns the number of living cells in a specific layer
@@ -259,3 +372,12 @@ func (u *Universe25D) CountLivingInLayer(z int) int {
 	}
 	return 0
 }
+
+// GetAge returns how many consecutive generations the cell at (x, y) of
+// layer z has been alive, or 0 if it is not alive
+func (u *Universe25D) GetAge(x, y, z int) int {
+	if z < 0 || z >= u.depth {
+		return 0
+	}
+	return u.layers[z].GetAge(x, y)
+}
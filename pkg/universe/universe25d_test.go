@@ -386,3 +386,61 @@ func BenchmarkUniverse25D_StepWithInteraction(b *testing.B) {
 		u.Step()
 	}
 }
+
+func TestUniverse25D_StepParallelMatchesStep(t *testing.T) {
+	for _, interaction := range []bool{false, true} {
+		serial := New25D(23, 13, 5, rules.ConwayRule{})
+		serial.SetLayerInteraction(interaction)
+		serial.SetBoundary(TorusBoundary())
+		serial.RandomizeWith(RandomOptions{Seed: 6, Density: 0.3})
+
+		// Seven bands over 5 layers of 13 rows start and end mid-layer
+		parallel := serial.Clone().(*Universe25D)
+		parallel.SetWorkers(7)
+
+		for gen := 1; gen <= 10; gen++ {
+			serial.Step()
+			parallel.StepParallel()
+			for z := 0; z < 5; z++ {
+				a, b := serial.GetLayer(z), parallel.GetLayer(z)
+				for i := range a.cells {
+					if a.cells[i] != b.cells[i] {
+						t.Fatalf("Interaction %v, generation %d: layer %d cell %d differs between Step and StepParallel", interaction, gen, z, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestUniverse25D_InteractionKeepsLayersAndAges(t *testing.T) {
+	u := New25D(10, 10, 3, rules.ConwayRule{})
+	u.SetLayerInteraction(true)
+	u.SetInteractionRule(rules.NewWeightedNeighborsRule(rules.ConwayRule{}, 0))
+	layer := u.GetLayer(1)
+
+	// A block is a still life when vertical neighbors have no weight
+	for _, c := range [][2]int{{4, 4}, {5, 4}, {4, 5}, {5, 5}} {
+		u.Set(core.NewCoord3D(c[0], c[1], 1), core.Alive)
+	}
+	u.Step()
+	u.Step()
+
+	if u.GetLayer(1) != layer {
+		t.Error("Stepping should reuse the layers")
+	}
+	if age := layer.GetAge(4, 4); age != 2 {
+		t.Errorf("Block cell should have survived 2 generations, got age %d", age)
+	}
+}
+
+func BenchmarkUniverse25D_StepParallelWithInteraction(b *testing.B) {
+	u := New25D(50, 50, 10, rules.ConwayRule{})
+	u.Randomize()
+	u.SetLayerInteraction(true)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.StepParallel()
+	}
+}
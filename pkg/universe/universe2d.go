@@ -2,7 +2,6 @@ package universe
 
 import (
 	"golife/pkg/core"
-	"math/rand"
 	"time"
 )
 
@@ -11,23 +10,88 @@ type Universe2D struct {
 	width, height int
 	cells         []core.CellState // Flat array for cache locality: [y*width + x]
 	nextCells     []core.CellState // Double buffering
-	ageMap        []int            // Age tracking for each cell
+	born          []int            // Generation in which each live cell was born, for ages
+	generation    int              // Generations stepped, the clock ages are measured against
 	rule          core.Rule
-	neighborhood  core.NeighborhoodType
+	transition    transition // Applies the rule, including Generations decay states
+	neighborhood  core.Neighborhood
+	offsets       []core.Coord        // Neighbor offsets generated from the neighborhood
+	weights       []float64           // NeighborWeight per offset; nil when all weights are 1
+	boundary      Boundary            // Topology beyond the grid edges
+	maskLUT       *maskLUT            // Lookup table for core.MaskRule rules; nil for totalistic rules
+	table         core.TransitionRule // Rule table driving Step; nil for birth/survival rules
+	tableOffsets  []core.Coord        // Neighbor offsets in the order the rule table expects
+	workers       int                 // Goroutines used by StepParallel; 0 means one per CPU
+	tiles         *tileMap            // Changed tiles, so Step can skip quiescent regions
+	radius        int                 // Reach of the neighborhood in cells
+	counts        stepCounts          // Births and deaths of the last step
+}
+
+// maskLUT holds the birth/survival decision for each of the 256 Moore neighborhood masks
+type maskLUT struct {
+	birth   [256]bool
+	survive [256]bool
+}
+
+// maskOffsets lists the neighbor offset for each bit of a core.MaskRule mask
+var maskOffsets = [8]core.Coord{
+	{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1},
+	{X: -1, Y: 0}, {X: 1, Y: 0},
+	{X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1},
+}
+
+// newMaskLUT precomputes the lookup table for a mask rule, or returns nil
+// if the rule only depends on neighbor counts
+func newMaskLUT(rule core.Rule) *maskLUT {
+	mr, ok := rule.(core.MaskRule)
+	if !ok {
+		return nil
+	}
+	lut := &maskLUT{}
+	for mask := 0; mask < 256; mask++ {
+		lut.birth[mask] = mr.ShouldBirthMask(uint8(mask))
+		lut.survive[mask] = mr.ShouldSurviveMask(uint8(mask))
+	}
+	return lut
 }
 
 // New2D creates a new 2D universe with the given dimensions and rule
 func New2D(width, height int, rule core.Rule) *Universe2D {
 	size := width * height
-	return &Universe2D{
-		width:        width,
-		height:       height,
-		cells:        make([]core.CellState, size),
-		nextCells:    make([]core.CellState, size),
-		ageMap:       make([]int, size),
-		rule:         rule,
-		neighborhood: core.Moore, // Default to Moore neighborhood (8 neighbors)
+	u := &Universe2D{
+		width:      width,
+		height:     height,
+		cells:      make([]core.CellState, size),
+		nextCells:  make([]core.CellState, size),
+		born:       make([]int, size),
+		tiles:      newTileMap(width, height, 1, tileSize2D),
+		rule:       rule,
+		transition: newTransition(rule),
+		maskLUT:    newMaskLUT(rule),
+	}
+	if table, ok := rule.(core.TransitionRule); ok {
+		u.table = table
+		u.tableOffsets = table.NeighborOffsets()
+	}
+	u.SetNeighborhood(defaultNeighborhood(rule)) // Default to Moore neighborhood (8 neighbors) unless the rule has its own
+	return u
+}
+
+// SetNeighborhood sets the neighborhood used to count neighbors
+func (u *Universe2D) SetNeighborhood(n core.Neighborhood) {
+	u.neighborhood = n
+	u.offsets = n.OffsetsFor(core.Dim2D)
+	u.weights = u.transition.neighborWeights(u.offsets)
+	u.radius = n.Radius(core.Dim2D)
+	if u.table != nil || u.maskLUT != nil {
+		u.radius = 1 // Tables and mask rules look at the 8 cells around
 	}
+	u.tiles.markAll()
+}
+
+// Neighborhood returns the neighborhood used to count neighbors
+func (u *Universe2D) Neighborhood() core.Neighborhood {
+	return u.neighborhood
 }
 
 // Dimension returns the dimensionality (2D)
@@ -48,7 +112,15 @@ func (u *Universe2D) Set(coord core.Coord, state core.CellState) {
 	if coord.X < 0 || coord.X >= u.width || coord.Y < 0 || coord.Y >= u.height {
 		return
 	}
-	u.cells[coord.Y*u.width+coord.X] = state
+	idx := coord.Y*u.width + coord.X
+	if u.cells[idx] == state {
+		return
+	}
+	if state == core.Alive {
+		u.born[idx] = u.generation + 1 // Age 0 until it survives a step
+	}
+	u.cells[idx] = state
+	u.tiles.markCell(coord.X, coord.Y, 0)
 }
 
 // Size returns the dimensions of the universe
@@ -56,76 +128,225 @@ func (u *Universe2D) Size() core.Coord {
 	return core.NewCoord2D(u.width, u.height)
 }
 
-// countNeighbors counts the number of alive neighbors around a cell
+// countNeighbors counts the number of alive neighbors around a cell.
+// With weighted rules it returns the weighted sum rounded to the nearest integer.
 func (u *Universe2D) countNeighbors(x, y int) int {
 	count := 0
+	sum := 0.0
 
-	// Check all 8 directions (Moore neighborhood)
-	for dy := -1; dy <= 1; dy++ {
-		for dx := -1; dx <= 1; dx++ {
-			// Skip the center cell itself
-			if dx == 0 && dy == 0 {
-				continue
-			}
+	for i, offset := range u.offsets {
+		// Calculate neighbor coordinates
+		nx := x + offset.X
+		ny := y + offset.Y
 
-			// Calculate neighbor coordinates
-			nx := x + dx
-			ny := y + dy
+		// Check boundaries
+		var state core.CellState
+		if nx >= 0 && nx < u.width && ny >= 0 && ny < u.height {
+			state = u.cells[ny*u.width+nx]
+		} else {
+			state = u.stateBeyondEdge(nx, ny)
+		}
+		if !u.transition.isLive(state) {
+			continue
+		}
 
-			// Check boundaries
-			if nx >= 0 && nx < u.width && ny >= 0 && ny < u.height {
-				if u.cells[ny*u.width+nx] > core.Dead {
-					count++
-				}
-			}
+		if u.weights == nil {
+			count++
+		} else {
+			sum += u.weights[i]
 		}
 	}
 
+	if u.weights != nil {
+		return roundWeighted(sum)
+	}
 	return count
 }
 
-// Step executes one generation
+// neighborMask returns the live Moore neighbors of a cell as a core.MaskRule mask
+func (u *Universe2D) neighborMask(x, y int) uint8 {
+	var mask uint8
+	for bit, offset := range maskOffsets {
+		nx, ny := x+offset.X, y+offset.Y
+
+		var state core.CellState
+		if nx >= 0 && nx < u.width && ny >= 0 && ny < u.height {
+			state = u.cells[ny*u.width+nx]
+		} else {
+			state = u.stateBeyondEdge(nx, ny)
+		}
+		if u.transition.isLive(state) {
+			mask |= 1 << bit
+		}
+	}
+	return mask
+}
+
+// nextFromMask returns the next state of a cell under a mask rule.
+// Mask rules always use the Moore neighborhood of range 1.
+func (u *Universe2D) nextFromMask(x, y int, current core.CellState) core.CellState {
+	if u.transition.isDying(current) {
+		return current - 1
+	}
+	mask := u.neighborMask(x, y)
+	if current == core.Dead {
+		return u.transition.resolve(current, u.maskLUT.birth[mask])
+	}
+	return u.transition.resolve(current, u.maskLUT.survive[mask])
+}
+
+// nextFromTable returns the next state of a cell under a rule table. The
+// neighbor states are gathered into the caller's buffer in the table's order;
+// the universe's own neighborhood is not used.
+func (u *Universe2D) nextFromTable(x, y int, current core.CellState, neighbors []core.CellState) core.CellState {
+	for i, offset := range u.tableOffsets {
+		nx, ny := x+offset.X, y+offset.Y
+		if nx >= 0 && nx < u.width && ny >= 0 && ny < u.height {
+			neighbors[i] = u.cells[ny*u.width+nx]
+		} else {
+			neighbors[i] = u.stateBeyondEdge(nx, ny)
+		}
+	}
+	return u.table.Next(current, neighbors)
+}
+
+// stateBeyondEdge returns the state seen at an out-of-range coordinate
+// according to the boundary topology
+func (u *Universe2D) stateBeyondEdge(x, y int) core.CellState {
+	rx, ry, _, outside, ok := u.boundary.resolve(x, y, 0, u.width, u.height, 1)
+	if !ok {
+		return outside
+	}
+	return u.cells[ry*u.width+rx]
+}
+
+// SetBoundary sets the boundary topology (the Z axis is ignored)
+func (u *Universe2D) SetBoundary(b Boundary) {
+	u.boundary = b
+	u.tiles.markAll()
+}
+
+// Boundary returns the boundary topology
+func (u *Universe2D) Boundary() Boundary {
+	return u.boundary
+}
+
+// Step executes one generation. Only tiles near a change in the last
+// generation are evaluated; see ActiveTiles.
 func (u *Universe2D) Step() {
-	newAgeMap := make([]int, len(u.ageMap))
+	u.tiles.prepare(u.radius, u.boundary)
+	u.counts.reset()
+	u.stepRows(0, u.height)
+	u.swap()
+}
 
-	for y := 0; y < u.height; y++ {
-		for x := 0; x < u.width; x++ {
-			idx := y*u.width + x
-			neighbors := u.countNeighbors(x, y)
-			currentState := u.cells[idx]
-
-			// Apply Conway's Game of Life rules
-			if currentState == core.Dead {
-				// Dead cell: check birth condition
-				if u.rule.ShouldBirth(neighbors) {
-					u.nextCells[idx] = core.Alive
-					newAgeMap[idx] = 1 // Born with age 1
-				} else {
-					u.nextCells[idx] = core.Dead
-					newAgeMap[idx] = 0
+// StepParallel executes one generation using parallel processing.
+// The grid is divided into bands of rows that are processed concurrently
+// by SetWorkers workers; the result is identical to Step.
+func (u *Universe2D) StepParallel() {
+	u.tiles.prepare(u.radius, u.boundary)
+	u.counts.reset()
+	parallelBands(u.height, workerCount(u.workers, u.height), u.stepRows)
+	u.swap()
+}
+
+// SetWorkers sets the number of goroutines StepParallel uses.
+// 0, the default, uses one per CPU.
+func (u *Universe2D) SetWorkers(n int) {
+	u.workers = n
+}
+
+// ActiveTiles returns the number of 16x16 tiles evaluated by the last step
+func (u *Universe2D) ActiveTiles() int {
+	return u.tiles.numActive
+}
+
+// TileCount returns the number of 16x16 tiles covering the grid
+func (u *Universe2D) TileCount() int {
+	return u.tiles.total()
+}
+
+// Births returns the number of dead cells that came alive in the last step
+func (u *Universe2D) Births() int {
+	return int(u.counts.births.Load())
+}
+
+// Deaths returns the number of cells that became dead in the last step
+func (u *Universe2D) Deaths() int {
+	return int(u.counts.deaths.Load())
+}
+
+// stepRows computes rows [yStart, yEnd) of the next generation into the
+// back buffer. Inactive tiles are skipped: the back buffer holds the
+// previous generation, which equals their next state.
+func (u *Universe2D) stepRows(yStart, yEnd int) {
+	var neighbors []core.CellState
+	if u.table != nil {
+		neighbors = make([]core.CellState, len(u.tableOffsets))
+	}
+	births, deaths := 0, 0
+
+	for y := yStart; y < yEnd; y++ {
+		for xStart := 0; xStart < u.width; xStart += tileSize2D {
+			if !u.tiles.isActive(xStart, y, 0) {
+				continue
+			}
+
+			changed := false
+			for x := xStart; x < min(xStart+tileSize2D, u.width); x++ {
+				idx := y*u.width + x
+				currentState := u.cells[idx]
+
+				// Apply the rule; dying Generations states decay on their own
+				var next core.CellState
+				switch {
+				case u.table != nil:
+					next = u.nextFromTable(x, y, currentState, neighbors)
+				case u.maskLUT != nil:
+					next = u.nextFromMask(x, y, currentState)
+				default:
+					next = u.transition.next(currentState, u.countNeighbors(x, y))
 				}
-			} else {
-				// Living cell: check survival condition
-				if u.rule.ShouldSurvive(neighbors, currentState) {
-					u.nextCells[idx] = core.Alive
-					newAgeMap[idx] = u.ageMap[idx] + 1 // Increment age
-				} else {
-					u.nextCells[idx] = core.Dead
-					newAgeMap[idx] = 0
+				if next != currentState {
+					changed = true
+					tally(currentState, next, &births, &deaths)
 				}
+				u.setNext(idx, next)
+			}
+			if changed {
+				u.tiles.markCell(xStart, y, 0)
 			}
 		}
 	}
+	u.counts.add(births, deaths)
+}
+
+// setNext stores the next state of a cell in the back buffer and records
+// its birth for ages
+func (u *Universe2D) setNext(idx int, next core.CellState) {
+	u.nextCells[idx] = next
+
+	// Age counts consecutive generations in the live state
+	if next == core.Alive && u.cells[idx] != core.Alive {
+		u.born[idx] = u.generation + 1
+	}
+}
 
-	// Swap buffers
+// swap makes the back buffer current
+func (u *Universe2D) swap() {
 	u.cells, u.nextCells = u.nextCells, u.cells
-	u.ageMap = newAgeMap
+	u.generation++
 }
 
 // Clone creates a deep copy of the universe
 func (u *Universe2D) Clone() core.Universe {
 	clone := New2D(u.width, u.height, u.rule)
+	clone.boundary = u.boundary
+	clone.workers = u.workers
+	clone.generation = u.generation
+	clone.SetNeighborhood(u.neighborhood)
 	copy(clone.cells, u.cells)
+	copy(clone.born, u.born)
 	return clone
 }
 
@@ -133,8 +354,8 @@ func (u *Universe2D) Clone() core.Universe {
 func (u *Universe2D) Clear() {
 	for i := range u.cells {
 		u.cells[i] = core.Dead
-		u.ageMap[i] = 0
 	}
+	u.tiles.markAll()
 }
 
 // CountLiving returns the number of living cells
@@ -148,16 +369,26 @@ func (u *Universe2D) CountLiving() int {
 	return count
 }
 
-// Randomize fills the universe with random cells
+// Randomize fills the universe with random cells at DefaultDensity, seeded
+// from the clock. Use RandomizeWith for reproducible fills.
 func (u *Universe2D) Randomize() {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := range u.cells {
-		if r.Intn(2) == 1 {
-			u.cells[i] = core.Alive
-			u.ageMap[i] = 1
-		} else {
-			u.cells[i] = core.Dead
-			u.ageMap[i] = 0
+	u.RandomizeWith(RandomOptions{Seed: time.Now().UnixNano()})
+}
+
+// RandomizeWith clears the universe and fills it with RandomFill.
+// Live cells start with age 1. It returns the number of live cells placed.
+func (u *Universe2D) RandomizeWith(opts RandomOptions) int {
+	u.Clear()
+	placed := RandomFill(u, opts)
+	u.startAges()
+	return placed
+}
+
+// startAges gives every live cell age 1, as if it had just been born
+func (u *Universe2D) startAges() {
+	for i, cell := range u.cells {
+		if cell == core.Alive {
+			u.born[i] = u.generation
 		}
 	}
 }
@@ -172,12 +403,70 @@ func (u *Universe2D) Height() int {
 	return u.height
 }
 
+// Rule returns the rule driving this universe
+func (u *Universe2D) Rule() core.Rule {
+	return u.rule
+}
+
 // GetAge returns the age of a cell at the given coordinate
 func (u *Universe2D) GetAge(x, y int) int {
 	if x < 0 || x >= u.width || y < 0 || y >= u.height {
 		return 0
 	}
-	return u.ageMap[y*u.width+x]
+	idx := y*u.width + x
+	if u.cells[idx] != core.Alive {
+		return 0
+	}
+	return u.generation - u.born[idx] + 1
+}
+
+// Bounds returns the inclusive bounding box of the non-dead cells.
+// ok is false if every cell is dead.
+func (u *Universe2D) Bounds() (minC, maxC core.Coord, ok bool) {
+	minX, maxX, minY, maxY := u.width, -1, -1, -1
+	for y := 0; y < u.height; y++ {
+		row := u.cells[y*u.width : (y+1)*u.width]
+		first := 0
+		for first < len(row) && row[first] == core.Dead {
+			first++
+		}
+		if first == len(row) {
+			continue
+		}
+		last := len(row) - 1
+		for row[last] == core.Dead {
+			last--
+		}
+		minX, maxX = min(minX, first), max(maxX, last)
+		if minY < 0 {
+			minY = y
+		}
+		maxY = y
+	}
+	if minY < 0 {
+		return core.Coord{}, core.Coord{}, false
+	}
+	return core.NewCoord2D(minX, minY), core.NewCoord2D(maxX, maxY), true
+}
+
+// AgeStats returns the mean and maximum age of the live cells that have an
+// age, as GetAge reports them
+func (u *Universe2D) AgeStats() (mean float64, maxAge int) {
+	total, aged := 0, 0
+	for i, cell := range u.cells {
+		if cell != core.Alive {
+			continue
+		}
+		if age := u.generation - u.born[i] + 1; age > 0 {
+			total += age
+			aged++
+			maxAge = max(maxAge, age)
+		}
+	}
+	if aged > 0 {
+		mean = float64(total) / float64(aged)
+	}
+	return mean, maxAge
 }
 
 // GetCells returns the internal cell array (for compatibility with legacy code)
@@ -205,9 +494,9 @@ func (u *Universe2D) SetCells(cells [][]int) {
 	for y := 0; y < u.height && y < len(cells); y++ {
 		for x := 0; x < u.width && x < len(cells[y]); x++ {
 			if cells[y][x] == 1 {
-				u.cells[y*u.width+x] = core.Alive
+				u.Set(core.NewCoord2D(x, y), core.Alive)
 			} else {
-				u.cells[y*u.width+x] = core.Dead
+				u.Set(core.NewCoord2D(x, y), core.Dead)
 			}
 		}
 	}
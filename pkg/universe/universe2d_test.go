@@ -205,3 +205,62 @@ func TestUniverse2D_CountLiving(t *testing.T) {
 		t.Errorf("Expected 3 living cells, got %d", u.CountLiving())
 	}
 }
+
+func TestUniverse2D_StepParallelMatchesStep(t *testing.T) {
+	for _, name := range []string{"B3/S23", "B2/S/C4", "B3/S2-i34q", "wireworld"} {
+		t.Run(name, func(t *testing.T) {
+			rule, err := rules.Parse(name)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", name, err)
+			}
+			serial := New2D(61, 37, rule)
+			serial.SetBoundary(TorusBoundary())
+			serial.RandomizeWith(RandomOptions{Seed: 12, Density: 0.4})
+
+			for _, workers := range []int{1, 3, 8} {
+				parallel := serial.Clone().(*Universe2D)
+				parallel.SetWorkers(workers)
+				reference := serial.Clone().(*Universe2D)
+
+				for gen := 1; gen <= 15; gen++ {
+					reference.Step()
+					parallel.StepParallel()
+					for i := range reference.cells {
+						if reference.cells[i] != parallel.cells[i] || reference.GetAge(i%61, i/61) != parallel.GetAge(i%61, i/61) {
+							t.Fatalf("%d workers, generation %d: cell %d differs between Step and StepParallel", workers, gen, i)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUniverse2D_StepDoesNotAllocate(t *testing.T) {
+	u := New2D(50, 50, rules.ConwayRule{})
+	u.RandomizeWith(RandomOptions{Seed: 1})
+
+	if allocs := testing.AllocsPerRun(10, u.Step); allocs != 0 {
+		t.Errorf("Step should reuse its buffers, got %.0f allocations per generation", allocs)
+	}
+}
+
+func BenchmarkUniverse2D_Step_512(b *testing.B) {
+	u := New2D(512, 512, rules.ConwayRule{})
+	u.RandomizeWith(RandomOptions{Seed: 1})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.Step()
+	}
+}
+
+func BenchmarkUniverse2D_StepParallel_512(b *testing.B) {
+	u := New2D(512, 512, rules.ConwayRule{})
+	u.RandomizeWith(RandomOptions{Seed: 1})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.StepParallel()
+	}
+}
@@ -1,6 +1,7 @@
 package universe
 
 import (
+	"fmt"
 	"golife/pkg/core"
 	"runtime"
 	"sync"
@@ -12,45 +13,77 @@ type Universe3D struct {
 	cells                []core.CellState // Flat array: [z*height*width + y*width + x]
 	nextCells            []core.CellState
 	rule                 core.Rule
-	neighborOffsets      []int // Pre-computed neighbor offsets for performance
+	transition           transition // Applies the rule, including Generations decay states
+	neighborhood         core.Neighborhood
+	neighborCoords       []core.Coord // Neighbor offsets generated from the neighborhood
+	neighborOffsets      []int        // Pre-computed neighbor offsets for performance
+	weights              []float64    // NeighborWeight per offset; nil when all weights are 1
+	radius               int          // Width of the boundary shell excluded from the fast path
+	boundary             Boundary     // Topology beyond the grid edges
+	tiles                *tileMap     // Changed tiles, so Step can skip quiescent regions
+	counts               stepCounts   // Births and deaths of the last step
 }
 
 // New3D creates a new 3D universe with the given dimensions and rule
 func New3D(width, height, depth int, rule core.Rule) *Universe3D {
 	size := width * height * depth
 	u := &Universe3D{
-		width:     width,
-		height:    height,
-		depth:     depth,
-		cells:     make([]core.CellState, size),
-		nextCells: make([]core.CellState, size),
-		rule:      rule,
+		width:      width,
+		height:     height,
+		depth:      depth,
+		cells:      make([]core.CellState, size),
+		nextCells:  make([]core.CellState, size),
+		rule:       rule,
+		transition: newTransition(rule),
+		tiles:      newTileMap(width, height, depth, tileSize3D),
+		// Default to Moore neighborhood (26 neighbors) unless the rule has its own
+		neighborhood: defaultNeighborhood(rule),
 	}
 
-	// Pre-compute 26-neighbor offsets for performance
+	// Pre-compute neighbor offsets for performance
 	u.precomputeNeighborOffsets()
 
 	return u
 }
 
-// precomputeNeighborOffsets pre-computes the flat array offsets for all 26 neighbors
+// Check3DRule reports why a rule cannot drive a 3D universe, if it cannot:
+// rule tables and non-totalistic rules are defined on 2D neighbors, and every
+// cell would die under them
+func Check3DRule(rule core.Rule) error {
+	if _, ok := rule.(core.TransitionRule); ok {
+		return fmt.Errorf("3D universe does not support rule tables (%s)", rule.Name())
+	}
+	if _, ok := rule.(core.MaskRule); ok {
+		return fmt.Errorf("3D universe does not support non-totalistic rules (%s)", rule.Name())
+	}
+	return nil
+}
+
+// precomputeNeighborOffsets pre-computes the flat array offsets for the chosen neighborhood
 func (u *Universe3D) precomputeNeighborOffsets() {
-	u.neighborOffsets = make([]int, 0, 26)
-
-	// All 26 neighbors (3x3x3 cube minus center)
-	for dz := -1; dz <= 1; dz++ {
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				if dx == 0 && dy == 0 && dz == 0 {
-					continue // Skip center cell
-				}
-				offset := dz*u.height*u.width + dy*u.width + dx
-				u.neighborOffsets = append(u.neighborOffsets, offset)
-			}
-		}
+	u.neighborCoords = u.neighborhood.OffsetsFor(core.Dim3D)
+	u.radius = u.neighborhood.Radius(core.Dim3D)
+	u.weights = u.transition.neighborWeights(u.neighborCoords)
+	u.neighborOffsets = make([]int, 0, len(u.neighborCoords))
+	u.tiles.markAll()
+
+	for _, c := range u.neighborCoords {
+		offset := c.Z*u.height*u.width + c.Y*u.width + c.X
+		u.neighborOffsets = append(u.neighborOffsets, offset)
 	}
 }
 
+// SetNeighborhood sets the neighborhood used to count neighbors
+func (u *Universe3D) SetNeighborhood(n core.Neighborhood) {
+	u.neighborhood = n
+	u.precomputeNeighborOffsets()
+}
+
+// Neighborhood returns the neighborhood used to count neighbors
+func (u *Universe3D) Neighborhood() core.Neighborhood {
+	return u.neighborhood
+}
+
 // Dimension returns the dimensionality (3D)
 func (u *Universe3D) Dimension() core.Dimension {
 	return core.Dim3D
@@ -80,6 +113,7 @@ func (u *Universe3D) Get(coord core.Coord) core.CellState {
 func (u *Universe3D) Set(coord core.Coord, state core.CellState) {
 	if u.isValid(coord.X, coord.Y, coord.Z) {
 		u.cells[u.coordToIndex(coord)] = state
+		u.tiles.markCell(coord.X, coord.Y, coord.Z)
 	}
 }
 
@@ -88,123 +122,91 @@ func (u *Universe3D) Size() core.Coord {
 	return core.NewCoord3D(u.width, u.height, u.depth)
 }
 
-// countNeighbors counts living neighbors for a cell (all 26 neighbors in 3D)
+// Rule returns the rule driving this universe
+func (u *Universe3D) Rule() core.Rule {
+	return u.rule
+}
+
+// countNeighbors counts living neighbors for a cell
 // This is the boundary-safe version with explicit coordinate checks
 func (u *Universe3D) countNeighbors(x, y, z int) int {
 	count := 0
-
-	// Check all 26 neighbors (3x3x3 cube minus center)
-	for dz := -1; dz <= 1; dz++ {
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				// Skip center cell
-				if dx == 0 && dy == 0 && dz == 0 {
-					continue
-				}
-
-				nx := x + dx
-				ny := y + dy
-				nz := z + dz
-
-				// Check bounds
-				if nx < 0 || nx >= u.width || ny < 0 || ny >= u.height || nz < 0 || nz >= u.depth {
-					continue
-				}
-
-				// Count if neighbor is alive
-				idx := nz*u.height*u.width + ny*u.width + nx
-				if u.cells[idx] != core.Dead {
-					count++
-				}
+	sum := 0.0
+
+	for i, offset := range u.neighborCoords {
+		nx := x + offset.X
+		ny := y + offset.Y
+		nz := z + offset.Z
+
+		// Resolve out-of-range neighbors through the boundary topology
+		var state core.CellState
+		if nx < 0 || nx >= u.width || ny < 0 || ny >= u.height || nz < 0 || nz >= u.depth {
+			var ok bool
+			nx, ny, nz, state, ok = u.boundary.resolve(nx, ny, nz, u.width, u.height, u.depth)
+			if ok {
+				state = u.cells[nz*u.height*u.width+ny*u.width+nx]
 			}
+		} else {
+			state = u.cells[nz*u.height*u.width+ny*u.width+nx]
+		}
+
+		// Count if neighbor is alive
+		if !u.transition.isLive(state) {
+			continue
+		}
+		if u.weights == nil {
+			count++
+		} else {
+			sum += u.weights[i]
 		}
 	}
 
+	if u.weights != nil {
+		return roundWeighted(sum)
+	}
 	return count
 }
 
+// SetBoundary sets the boundary topology.
+// Interior cells keep using the pre-computed fast path; only cells on the
+// faces of the grid consult the boundary.
+func (u *Universe3D) SetBoundary(b Boundary) {
+	u.boundary = b
+	u.tiles.markAll()
+}
+
+// Boundary returns the boundary topology
+func (u *Universe3D) Boundary() Boundary {
+	return u.boundary
+}
+
 // countNeighborsInterior counts neighbors for interior cells (no boundary check needed)
 func (u *Universe3D) countNeighborsInterior(idx int) int {
+	if u.weights != nil {
+		sum := 0.0
+		for i, offset := range u.neighborOffsets {
+			if u.transition.isLive(u.cells[idx+offset]) {
+				sum += u.weights[i]
+			}
+		}
+		return roundWeighted(sum)
+	}
+
 	count := 0
 	for _, offset := range u.neighborOffsets {
-		if u.cells[idx+offset] != core.Dead {
+		if u.transition.isLive(u.cells[idx+offset]) {
 			count++
 		}
 	}
 	return count
 }
 
-// Step executes one generation using the rule
+// Step executes one generation using the rule. Only tiles near a change in
+// the last generation are evaluated; see ActiveTiles.
 func (u *Universe3D) Step() {
-	// Optimize by separating interior cells (no boundary check) from boundary cells
-	// Interior region: cells that have all 26 neighbors within bounds
-	interiorStartX, interiorEndX := 1, u.width-1
-	interiorStartY, interiorEndY := 1, u.height-1
-	interiorStartZ, interiorEndZ := 1, u.depth-1
-
-	// Check if we have an interior region
-	hasInterior := u.width > 2 && u.height > 2 && u.depth > 2
-
-	if hasInterior {
-		// Process interior cells (fast path - no boundary checks)
-		for z := interiorStartZ; z < interiorEndZ; z++ {
-			for y := interiorStartY; y < interiorEndY; y++ {
-				for x := interiorStartX; x < interiorEndX; x++ {
-					idx := z*u.height*u.width + y*u.width + x
-					neighbors := u.countNeighborsInterior(idx)
-					currentState := u.cells[idx]
-
-					// Apply rule
-					if currentState == core.Dead {
-						if u.rule.ShouldBirth(neighbors) {
-							u.nextCells[idx] = core.Alive
-						} else {
-							u.nextCells[idx] = core.Dead
-						}
-					} else {
-						if u.rule.ShouldSurvive(neighbors, currentState) {
-							u.nextCells[idx] = core.Alive
-						} else {
-							u.nextCells[idx] = core.Dead
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// Process boundary cells (with bounds checking)
-	for z := 0; z < u.depth; z++ {
-		for y := 0; y < u.height; y++ {
-			for x := 0; x < u.width; x++ {
-				// Skip interior cells (already processed)
-				if hasInterior && x >= interiorStartX && x < interiorEndX &&
-					y >= interiorStartY && y < interiorEndY &&
-					z >= interiorStartZ && z < interiorEndZ {
-					continue
-				}
-
-				idx := z*u.height*u.width + y*u.width + x
-				neighbors := u.countNeighbors(x, y, z)
-				currentState := u.cells[idx]
-
-				// Apply rule
-				if currentState == core.Dead {
-					if u.rule.ShouldBirth(neighbors) {
-						u.nextCells[idx] = core.Alive
-					} else {
-						u.nextCells[idx] = core.Dead
-					}
-				} else {
-					if u.rule.ShouldSurvive(neighbors, currentState) {
-						u.nextCells[idx] = core.Alive
-					} else {
-						u.nextCells[idx] = core.Dead
-					}
-				}
-			}
-		}
-	}
+	u.tiles.prepare(u.radius, u.boundary)
+	u.counts.reset()
+	u.processZSlice(0, u.depth)
 
 	// Swap buffers
 	u.cells, u.nextCells = u.nextCells, u.cells
@@ -213,6 +215,9 @@ func (u *Universe3D) Step() {
 // StepParallel executes one generation using parallel processing
 // The grid is divided into Z-axis slices and processed concurrently
 func (u *Universe3D) StepParallel() {
+	u.tiles.prepare(u.radius, u.boundary)
+	u.counts.reset()
+
 	numWorkers := runtime.NumCPU()
 	if numWorkers > u.depth {
 		numWorkers = u.depth
@@ -245,69 +250,69 @@ func (u *Universe3D) StepParallel() {
 	u.cells, u.nextCells = u.nextCells, u.cells
 }
 
-// processZSlice processes a range of Z layers [zStart, zEnd)
-func (u *Universe3D) processZSlice(zStart, zEnd int) {
-	// Determine interior region boundaries
-	interiorStartX, interiorEndX := 1, u.width-1
-	interiorStartY, interiorEndY := 1, u.height-1
-	hasInterior := u.width > 2 && u.height > 2
+// ActiveTiles returns the number of 8x8x8 tiles evaluated by the last step
+func (u *Universe3D) ActiveTiles() int {
+	return u.tiles.numActive
+}
 
-	for z := zStart; z < zEnd; z++ {
-		// Process interior cells (fast path)
-		if hasInterior && z > 0 && z < u.depth-1 {
-			for y := interiorStartY; y < interiorEndY; y++ {
-				for x := interiorStartX; x < interiorEndX; x++ {
-					idx := z*u.height*u.width + y*u.width + x
-					neighbors := u.countNeighborsInterior(idx)
-					currentState := u.cells[idx]
+// TileCount returns the number of 8x8x8 tiles covering the grid
+func (u *Universe3D) TileCount() int {
+	return u.tiles.total()
+}
 
-					if currentState == core.Dead {
-						if u.rule.ShouldBirth(neighbors) {
-							u.nextCells[idx] = core.Alive
-						} else {
-							u.nextCells[idx] = core.Dead
-						}
-					} else {
-						if u.rule.ShouldSurvive(neighbors, currentState) {
-							u.nextCells[idx] = core.Alive
-						} else {
-							u.nextCells[idx] = core.Dead
-						}
-					}
-				}
-			}
-		}
+// Births returns the number of dead cells that came alive in the last step
+func (u *Universe3D) Births() int {
+	return int(u.counts.births.Load())
+}
+
+// Deaths returns the number of cells that became dead in the last step
+func (u *Universe3D) Deaths() int {
+	return int(u.counts.deaths.Load())
+}
 
-		// Process boundary cells (safe path)
+// processZSlice processes a range of Z layers [zStart, zEnd). Inactive tiles
+// are skipped: the back buffer holds the previous generation, which equals
+// their next state.
+func (u *Universe3D) processZSlice(zStart, zEnd int) {
+	// Interior cells have all neighbors within bounds (fast path)
+	r := u.radius
+	births, deaths := 0, 0
+
+	for z := zStart; z < zEnd; z++ {
+		interiorZ := z >= r && z < u.depth-r
 		for y := 0; y < u.height; y++ {
-			for x := 0; x < u.width; x++ {
-				// Skip interior cells if already processed
-				if hasInterior && z > 0 && z < u.depth-1 &&
-					x >= interiorStartX && x < interiorEndX &&
-					y >= interiorStartY && y < interiorEndY {
+			interiorRow := interiorZ && y >= r && y < u.height-r
+			for xStart := 0; xStart < u.width; xStart += tileSize3D {
+				if !u.tiles.isActive(xStart, y, z) {
 					continue
 				}
 
-				idx := z*u.height*u.width + y*u.width + x
-				neighbors := u.countNeighbors(x, y, z)
-				currentState := u.cells[idx]
-
-				if currentState == core.Dead {
-					if u.rule.ShouldBirth(neighbors) {
-						u.nextCells[idx] = core.Alive
+				changed := false
+				for x := xStart; x < min(xStart+tileSize3D, u.width); x++ {
+					idx := z*u.height*u.width + y*u.width + x
+					var neighbors int
+					if interiorRow && x >= r && x < u.width-r {
+						neighbors = u.countNeighborsInterior(idx)
 					} else {
-						u.nextCells[idx] = core.Dead
+						neighbors = u.countNeighbors(x, y, z) // Boundary cells (safe path)
 					}
-				} else {
-					if u.rule.ShouldSurvive(neighbors, currentState) {
-						u.nextCells[idx] = core.Alive
-					} else {
-						u.nextCells[idx] = core.Dead
+					currentState := u.cells[idx]
+
+					// Apply rule
+					next := u.transition.next(currentState, neighbors)
+					if next != currentState {
+						changed = true
+						tally(currentState, next, &births, &deaths)
 					}
+					u.nextCells[idx] = next
+				}
+				if changed {
+					u.tiles.markCell(xStart, y, z)
 				}
 			}
 		}
 	}
+	u.counts.add(births, deaths)
 }
 
 // Clear sets all cells to dead
@@ -315,6 +320,7 @@ func (u *Universe3D) Clear() {
 	for i := range u.cells {
 		u.cells[i] = core.Dead
 	}
+	u.tiles.markAll()
 }
 
 // CountLiving returns the number of living cells
@@ -348,10 +354,14 @@ func (u *Universe3D) GetSlice(z int) [][]core.CellState {
 // Clone creates a deep copy of the universe
 func (u *Universe3D) Clone() core.Universe {
 	clone := &Universe3D{
-		width:  u.width,
-		height: u.height,
-		depth:  u.depth,
-		rule:   u.rule,
+		width:        u.width,
+		height:       u.height,
+		depth:        u.depth,
+		rule:         u.rule,
+		transition:   u.transition,
+		neighborhood: u.neighborhood,
+		boundary:     u.boundary,
+		tiles:        newTileMap(u.width, u.height, u.depth, tileSize3D),
 	}
 
 	// Copy cells
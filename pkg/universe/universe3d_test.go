@@ -28,6 +28,27 @@ func TestNew3D(t *testing.T) {
 	}
 }
 
+func TestCheck3DRule(t *testing.T) {
+	for _, name := range []string{"5766", "B6/S567/C4", "clouds"} {
+		rule, err := rules.Parse(name)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", name, err)
+		}
+		if err := Check3DRule(rule); err != nil {
+			t.Errorf("Check3DRule(%s) returned error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"wireworld", "B2-a/S12"} {
+		rule, err := rules.Parse(name)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", name, err)
+		}
+		if err := Check3DRule(rule); err == nil {
+			t.Errorf("Check3DRule(%s) should reject a 2D-only rule", name)
+		}
+	}
+}
+
 func TestUniverse3D_Dimension(t *testing.T) {
 	u := New3D(10, 10, 10, rules.ConwayRule{})
 
@@ -0,0 +1,289 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"runtime"
+	"sync"
+)
+
+// Universe4D represents a 4D universe with full 80-neighbor interaction
+type Universe4D struct {
+	width, height, depth, wSize int
+	cells                       []core.CellState // Flat array: [w*depth*height*width + z*height*width + y*width + x]
+	nextCells                   []core.CellState
+	rule                        core.Rule
+	transition                  transition // Applies the rule, including Generations decay states
+	neighborhood                core.Neighborhood
+	neighborCoords              []core.Coord // Neighbor offsets generated from the neighborhood
+	neighborOffsets             []int        // Pre-computed neighbor offsets for performance
+	weights                     []float64    // NeighborWeight per offset; nil when all weights are 1
+	radius                      int          // Width of the boundary shell excluded from the fast path
+}
+
+// New4D creates a new 4D universe with the given dimensions and rule
+func New4D(width, height, depth, wSize int, rule core.Rule) *Universe4D {
+	size := width * height * depth * wSize
+	u := &Universe4D{
+		width:      width,
+		height:     height,
+		depth:      depth,
+		wSize:      wSize,
+		cells:      make([]core.CellState, size),
+		nextCells:  make([]core.CellState, size),
+		rule:       rule,
+		transition: newTransition(rule),
+		// Default to Moore neighborhood (80 neighbors) unless the rule has its own
+		neighborhood: defaultNeighborhood(rule),
+	}
+
+	// Pre-compute neighbor offsets for performance
+	u.precomputeNeighborOffsets()
+
+	return u
+}
+
+// precomputeNeighborOffsets pre-computes the flat array offsets for the chosen neighborhood
+func (u *Universe4D) precomputeNeighborOffsets() {
+	u.neighborCoords = u.neighborhood.OffsetsFor(core.Dim4D)
+	u.radius = u.neighborhood.Radius(core.Dim4D)
+	u.weights = u.transition.neighborWeights(u.neighborCoords)
+	u.neighborOffsets = make([]int, 0, len(u.neighborCoords))
+
+	for _, c := range u.neighborCoords {
+		offset := c.W*u.depth*u.height*u.width + c.Z*u.height*u.width + c.Y*u.width + c.X
+		u.neighborOffsets = append(u.neighborOffsets, offset)
+	}
+}
+
+// SetNeighborhood sets the neighborhood used to count neighbors
+func (u *Universe4D) SetNeighborhood(n core.Neighborhood) {
+	u.neighborhood = n
+	u.precomputeNeighborOffsets()
+}
+
+// Neighborhood returns the neighborhood used to count neighbors
+func (u *Universe4D) Neighborhood() core.Neighborhood {
+	return u.neighborhood
+}
+
+// Dimension returns the dimensionality (4D)
+func (u *Universe4D) Dimension() core.Dimension {
+	return core.Dim4D
+}
+
+// index converts 4D coordinates to flat array index
+func (u *Universe4D) index(x, y, z, w int) int {
+	return ((w*u.depth+z)*u.height+y)*u.width + x
+}
+
+// isValid checks if coordinates are within bounds
+func (u *Universe4D) isValid(x, y, z, w int) bool {
+	return x >= 0 && x < u.width &&
+		y >= 0 && y < u.height &&
+		z >= 0 && z < u.depth &&
+		w >= 0 && w < u.wSize
+}
+
+// Get returns the state of a cell at the given coordinate
+func (u *Universe4D) Get(coord core.Coord) core.CellState {
+	if !u.isValid(coord.X, coord.Y, coord.Z, coord.W) {
+		return core.Dead
+	}
+	return u.cells[u.index(coord.X, coord.Y, coord.Z, coord.W)]
+}
+
+// Set sets the state of a cell at the given coordinate
+func (u *Universe4D) Set(coord core.Coord, state core.CellState) {
+	if u.isValid(coord.X, coord.Y, coord.Z, coord.W) {
+		u.cells[u.index(coord.X, coord.Y, coord.Z, coord.W)] = state
+	}
+}
+
+// Size returns the dimensions of the universe
+func (u *Universe4D) Size() core.Coord {
+	return core.NewCoord4D(u.width, u.height, u.depth, u.wSize)
+}
+
+// countNeighbors counts living neighbors for a cell
+// This is the boundary-safe version with explicit coordinate checks
+func (u *Universe4D) countNeighbors(x, y, z, w int) int {
+	count := 0
+	sum := 0.0
+
+	for i, offset := range u.neighborCoords {
+		nx, ny, nz, nw := x+offset.X, y+offset.Y, z+offset.Z, w+offset.W
+		if !u.isValid(nx, ny, nz, nw) {
+			continue
+		}
+		if !u.transition.isLive(u.cells[u.index(nx, ny, nz, nw)]) {
+			continue
+		}
+		if u.weights == nil {
+			count++
+		} else {
+			sum += u.weights[i]
+		}
+	}
+
+	if u.weights != nil {
+		return roundWeighted(sum)
+	}
+	return count
+}
+
+// countNeighborsInterior counts neighbors for interior cells (no boundary check needed)
+func (u *Universe4D) countNeighborsInterior(idx int) int {
+	if u.weights != nil {
+		sum := 0.0
+		for i, offset := range u.neighborOffsets {
+			if u.transition.isLive(u.cells[idx+offset]) {
+				sum += u.weights[i]
+			}
+		}
+		return roundWeighted(sum)
+	}
+
+	count := 0
+	for _, offset := range u.neighborOffsets {
+		if u.transition.isLive(u.cells[idx+offset]) {
+			count++
+		}
+	}
+	return count
+}
+
+// isInterior reports whether all neighbors of a cell are within bounds
+func (u *Universe4D) isInterior(x, y, z, w int) bool {
+	r := u.radius
+	return x >= r && x < u.width-r &&
+		y >= r && y < u.height-r &&
+		z >= r && z < u.depth-r &&
+		w >= r && w < u.wSize-r
+}
+
+// Step executes one generation using the rule
+func (u *Universe4D) Step() {
+	u.processWSlice(0, u.wSize)
+
+	// Swap buffers
+	u.cells, u.nextCells = u.nextCells, u.cells
+}
+
+// StepParallel executes one generation using parallel processing
+// The grid is divided into W-axis slices and processed concurrently
+func (u *Universe4D) StepParallel() {
+	numWorkers := runtime.NumCPU()
+	if numWorkers > u.wSize {
+		numWorkers = u.wSize
+	}
+	if numWorkers < 1 {
+		return
+	}
+
+	// Calculate slice size for each worker
+	sliceSize := u.wSize / numWorkers
+	remainder := u.wSize % numWorkers
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+
+	for workerID := 0; workerID < numWorkers; workerID++ {
+		wStart := workerID * sliceSize
+		wEnd := wStart + sliceSize
+		if workerID == numWorkers-1 {
+			wEnd += remainder // Last worker handles remainder
+		}
+
+		go func(wStart, wEnd int) {
+			defer wg.Done()
+			u.processWSlice(wStart, wEnd)
+		}(wStart, wEnd)
+	}
+
+	wg.Wait()
+
+	// Swap buffers (single-threaded)
+	u.cells, u.nextCells = u.nextCells, u.cells
+}
+
+// processWSlice processes a range of W hyperplanes [wStart, wEnd)
+func (u *Universe4D) processWSlice(wStart, wEnd int) {
+	for w := wStart; w < wEnd; w++ {
+		for z := 0; z < u.depth; z++ {
+			for y := 0; y < u.height; y++ {
+				for x := 0; x < u.width; x++ {
+					idx := u.index(x, y, z, w)
+
+					// Interior cells use pre-computed offsets (fast path)
+					var neighbors int
+					if u.isInterior(x, y, z, w) {
+						neighbors = u.countNeighborsInterior(idx)
+					} else {
+						neighbors = u.countNeighbors(x, y, z, w)
+					}
+
+					currentState := u.cells[idx]
+					u.nextCells[idx] = u.transition.next(currentState, neighbors)
+				}
+			}
+		}
+	}
+}
+
+// Clear sets all cells to dead
+func (u *Universe4D) Clear() {
+	for i := range u.cells {
+		u.cells[i] = core.Dead
+	}
+}
+
+// CountLiving returns the number of living cells
+func (u *Universe4D) CountLiving() int {
+	count := 0
+	for _, state := range u.cells {
+		if state != core.Dead {
+			count++
+		}
+	}
+	return count
+}
+
+// GetSlice returns a 2D slice at the given Z and W levels
+func (u *Universe4D) GetSlice(z, w int) [][]core.CellState {
+	if z < 0 || z >= u.depth || w < 0 || w >= u.wSize {
+		return nil
+	}
+
+	slice := make([][]core.CellState, u.height)
+	for y := 0; y < u.height; y++ {
+		slice[y] = make([]core.CellState, u.width)
+		for x := 0; x < u.width; x++ {
+			slice[y][x] = u.cells[u.index(x, y, z, w)]
+		}
+	}
+	return slice
+}
+
+// Clone creates a deep copy of the universe
+func (u *Universe4D) Clone() core.Universe {
+	clone := &Universe4D{
+		width:        u.width,
+		height:       u.height,
+		depth:        u.depth,
+		wSize:        u.wSize,
+		rule:         u.rule,
+		transition:   u.transition,
+		neighborhood: u.neighborhood,
+	}
+
+	// Copy cells
+	clone.cells = make([]core.CellState, len(u.cells))
+	copy(clone.cells, u.cells)
+
+	clone.nextCells = make([]core.CellState, len(u.nextCells))
+
+	// Pre-compute neighbor offsets
+	clone.precomputeNeighborOffsets()
+
+	return clone
+}
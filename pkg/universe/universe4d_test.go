@@ -0,0 +1,211 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"math/rand"
+	"testing"
+)
+
+func TestNew4D(t *testing.T) {
+	u := New4D(6, 7, 8, 9, rules.Life4D_B9S7_10{})
+
+	if u == nil {
+		t.Fatal("New4D should not return nil")
+	}
+
+	size := u.Size()
+	if size.X != 6 || size.Y != 7 || size.Z != 8 || size.W != 9 {
+		t.Errorf("Size() returned incorrect dimensions: %v", size)
+	}
+
+	if u.Dimension() != core.Dim4D {
+		t.Errorf("Expected Dim4D, got %v", u.Dimension())
+	}
+
+	// Should have 80 neighbor offsets
+	if len(u.neighborOffsets) != 80 {
+		t.Errorf("Expected 80 neighbor offsets, got %d", len(u.neighborOffsets))
+	}
+}
+
+func TestUniverse4D_GetSet(t *testing.T) {
+	u := New4D(5, 5, 5, 5, rules.Life4D_B9S7_10{})
+
+	coords := []core.Coord{
+		core.NewCoord4D(0, 0, 0, 0),
+		core.NewCoord4D(4, 4, 4, 4),
+		core.NewCoord4D(1, 2, 3, 4),
+	}
+	for _, c := range coords {
+		u.Set(c, core.Alive)
+		if u.Get(c) != core.Alive {
+			t.Errorf("Cell at %v should be alive", c)
+		}
+	}
+
+	// W axis must be distinguished from the other axes
+	if u.Get(core.NewCoord4D(1, 2, 3, 0)) != core.Dead {
+		t.Error("Cell at W=0 should be dead")
+	}
+
+	// Out of bounds should return Dead and not crash on Set
+	outOfBounds := []core.Coord{
+		core.NewCoord4D(-1, 0, 0, 0),
+		core.NewCoord4D(0, 0, 0, -1),
+		core.NewCoord4D(0, 0, 0, 5),
+		core.NewCoord4D(5, 5, 5, 5),
+	}
+	for _, c := range outOfBounds {
+		u.Set(c, core.Alive)
+		if u.Get(c) != core.Dead {
+			t.Errorf("Out of bounds coord %v should return Dead", c)
+		}
+	}
+
+	if u.CountLiving() != 3 {
+		t.Errorf("Expected 3 living cells, got %d", u.CountLiving())
+	}
+}
+
+func TestUniverse4D_CountNeighbors(t *testing.T) {
+	u := New4D(5, 5, 5, 5, rules.Life4D_B9S7_10{})
+
+	// Fill the whole 3x3x3x3 hypercube around the center
+	for w := 1; w <= 3; w++ {
+		for z := 1; z <= 3; z++ {
+			for y := 1; y <= 3; y++ {
+				for x := 1; x <= 3; x++ {
+					u.Set(core.NewCoord4D(x, y, z, w), core.Alive)
+				}
+			}
+		}
+	}
+
+	if count := u.countNeighbors(2, 2, 2, 2); count != 80 {
+		t.Errorf("Expected 80 neighbors, got %d", count)
+	}
+
+	idx := u.index(2, 2, 2, 2)
+	if count := u.countNeighborsInterior(idx); count != 80 {
+		t.Errorf("Expected 80 interior neighbors, got %d", count)
+	}
+
+	// Corner cell only sees the 15 cells of its 2x2x2x2 corner
+	u.Clear()
+	for w := 0; w <= 1; w++ {
+		for z := 0; z <= 1; z++ {
+			for y := 0; y <= 1; y++ {
+				for x := 0; x <= 1; x++ {
+					u.Set(core.NewCoord4D(x, y, z, w), core.Alive)
+				}
+			}
+		}
+	}
+	if count := u.countNeighbors(0, 0, 0, 0); count != 15 {
+		t.Errorf("Corner cell should have 15 neighbors, got %d", count)
+	}
+}
+
+func TestUniverse4D_Step(t *testing.T) {
+	u := New4D(5, 5, 5, 5, rules.Life4D_B9S7_10{})
+
+	// Give the center exactly 9 neighbors along the W=1 hyperplane
+	count := 0
+	for z := 1; z <= 3 && count < 9; z++ {
+		for y := 1; y <= 3 && count < 9; y++ {
+			for x := 1; x <= 3 && count < 9; x++ {
+				u.Set(core.NewCoord4D(x, y, z, 1), core.Alive)
+				count++
+			}
+		}
+	}
+
+	u.Step()
+
+	if u.Get(core.NewCoord4D(2, 2, 2, 2)) != core.Alive {
+		t.Error("Center cell with 9 neighbors should be born under B9/S7-10")
+	}
+
+	// A lone cell dies of isolation
+	u.Clear()
+	u.Set(core.NewCoord4D(2, 2, 2, 2), core.Alive)
+	u.Step()
+	if u.CountLiving() != 0 {
+		t.Errorf("Isolated cell should die, got %d living cells", u.CountLiving())
+	}
+}
+
+func TestUniverse4D_StepParallel_Correctness(t *testing.T) {
+	rule := rules.Life4D_B9S7_10{}
+	u1 := New4D(8, 8, 8, 8, rule)
+	u2 := New4D(8, 8, 8, 8, rule)
+
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 800; i++ {
+		c := core.NewCoord4D(r.Intn(8), r.Intn(8), r.Intn(8), r.Intn(8))
+		u1.Set(c, core.Alive)
+		u2.Set(c, core.Alive)
+	}
+
+	for step := 0; step < 5; step++ {
+		u1.Step()
+		u2.StepParallel()
+
+		for i := range u1.cells {
+			if u1.cells[i] != u2.cells[i] {
+				t.Fatalf("Step %d: cell %d differs between Step and StepParallel", step, i)
+			}
+		}
+	}
+}
+
+func TestUniverse4D_GetSlice(t *testing.T) {
+	u := New4D(4, 3, 2, 2, rules.Life4D_B9S7_10{})
+	u.Set(core.NewCoord4D(3, 2, 1, 1), core.Alive)
+
+	slice := u.GetSlice(1, 1)
+	if len(slice) != 3 || len(slice[0]) != 4 {
+		t.Fatalf("Expected 3x4 slice, got %dx%d", len(slice), len(slice[0]))
+	}
+	if slice[2][3] != core.Alive {
+		t.Error("Slice should contain the living cell")
+	}
+
+	if u.GetSlice(2, 0) != nil || u.GetSlice(0, -1) != nil {
+		t.Error("Out of range slices should be nil")
+	}
+}
+
+func TestUniverse4D_Clone(t *testing.T) {
+	u := New4D(4, 4, 4, 4, rules.Life4D_B9S7_10{})
+	u.Set(core.NewCoord4D(1, 1, 1, 1), core.Alive)
+
+	clone := u.Clone()
+	if clone.Get(core.NewCoord4D(1, 1, 1, 1)) != core.Alive {
+		t.Error("Clone should copy cell states")
+	}
+
+	clone.Set(core.NewCoord4D(2, 2, 2, 2), core.Alive)
+	if u.Get(core.NewCoord4D(2, 2, 2, 2)) != core.Dead {
+		t.Error("Modifying the clone should not affect the original")
+	}
+}
+
+func BenchmarkUniverse4D_Step_16x16x16x16(b *testing.B) {
+	u := New4D(16, 16, 16, 16, rules.Life4D_B9S7_10{})
+	u.Set(core.NewCoord4D(8, 8, 8, 8), core.Alive)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.Step()
+	}
+}
+
+func BenchmarkUniverse4D_StepParallel_16x16x16x16(b *testing.B) {
+	u := New4D(16, 16, 16, 16, rules.Life4D_B9S7_10{})
+	u.Set(core.NewCoord4D(8, 8, 8, 8), core.Alive)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u.StepParallel()
+	}
+}
@@ -0,0 +1,176 @@
+package universe
+
+import (
+	"golife/pkg/core"
+	"golife/pkg/rules"
+	"math/rand"
+	"testing"
+)
+
+func TestLargerThanLife_AdoptsRuleNeighborhood(t *testing.T) {
+	rule := rules.Bosco()
+
+	u2 := New2D(20, 20, rule)
+	if len(u2.offsets) != 120 {
+		t.Errorf("Universe2D: expected 120 offsets for Moore R5, got %d", len(u2.offsets))
+	}
+	if u2.weights != nil {
+		t.Error("Uniform weights should use the plain counting path")
+	}
+
+	u3 := New3D(12, 12, 12, rules.LargerThanLifeRule{Range: 2, Shape: core.Circular, BirthMin: 1, BirthMax: 1})
+	if len(u3.neighborCoords) != 32 {
+		t.Errorf("Universe3D: expected 32 offsets for circular R2, got %d", len(u3.neighborCoords))
+	}
+
+	sparse, err := NewSparse2D(rule)
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+	if sparse.halo != 5 {
+		t.Errorf("SparseUniverse: expected halo 5, got %d", sparse.halo)
+	}
+}
+
+func TestLargerThanLife_MajorityFillsHoles(t *testing.T) {
+	u := New2D(30, 30, rules.Majority())
+
+	// A solid 15x15 block with a single hole in the middle
+	for y := 5; y < 20; y++ {
+		for x := 5; x < 20; x++ {
+			u.Set(core.NewCoord2D(x, y), core.Alive)
+		}
+	}
+	u.Set(core.NewCoord2D(12, 12), core.Dead)
+
+	if got := u.countNeighbors(12, 12); got != 80 {
+		t.Fatalf("Hole should see 80 live neighbors in its 9x9 block, got %d", got)
+	}
+
+	u.Step()
+
+	if u.Get(core.NewCoord2D(12, 12)) != core.Alive {
+		t.Error("Majority rule should fill the hole")
+	}
+	if u.Get(core.NewCoord2D(5, 5)) != core.Dead {
+		t.Error("Block corner sees only 25 of 81 live cells and should die")
+	}
+}
+
+func TestDistanceDecay_WeightedCount(t *testing.T) {
+	// Weight 1/d: face neighbors 1, edge diagonals 1/sqrt(2), corners 1/sqrt(3)
+	rule := rules.NewDistanceDecayRule(rules.ConwayRule{}, core.MooreNeighborhood(1), 1)
+
+	u2 := New2D(5, 5, rule)
+	u3 := New3D(5, 5, 5, rule)
+	for z := 1; z <= 3; z++ {
+		for y := 1; y <= 3; y++ {
+			for x := 1; x <= 3; x++ {
+				u2.Set(core.NewCoord2D(x, y), core.Alive)
+				u3.Set(core.NewCoord3D(x, y, z), core.Alive)
+			}
+		}
+	}
+
+	// 4 + 4*0.707 = 6.83
+	if got := u2.countNeighbors(2, 2); got != 7 {
+		t.Errorf("2D weighted count = %d, want 7", got)
+	}
+	// 6 + 12*0.707 + 8*0.577 = 19.10
+	if got := u3.countNeighbors(2, 2, 2); got != 19 {
+		t.Errorf("3D weighted count = %d, want 19", got)
+	}
+	if got := u3.countNeighborsInterior((2*5+2)*5 + 2); got != 19 {
+		t.Errorf("3D interior weighted count = %d, want 19", got)
+	}
+}
+
+func TestDistanceDecay_Step2D(t *testing.T) {
+	// Under plain Conway a blinker oscillates. With 1/d weights the cells that
+	// would be born next to the center see one face neighbor and two diagonals
+	// (1 + 2*0.707 = 2.41 -> 2), so only the center survives.
+	rule := rules.NewDistanceDecayRule(rules.ConwayRule{}, core.MooreNeighborhood(1), 1)
+	u := New2D(7, 7, rule)
+	u.Set(core.NewCoord2D(2, 3), core.Alive)
+	u.Set(core.NewCoord2D(3, 3), core.Alive)
+	u.Set(core.NewCoord2D(4, 3), core.Alive)
+
+	u.Step()
+
+	if u.Get(core.NewCoord2D(3, 3)) != core.Alive {
+		t.Error("Center with two face neighbors should survive")
+	}
+	if u.CountLiving() != 1 {
+		t.Errorf("Expected 1 living cell, got %d", u.CountLiving())
+	}
+}
+
+func TestWeighted_SparseMatchesUniverse2D(t *testing.T) {
+	rule := rules.NewDistanceDecayRule(rules.ConwayRule{}, core.CircularNeighborhood(2), 1.5)
+	dense := New2D(64, 64, rule)
+	sparse, err := NewSparse2D(rule)
+	if err != nil {
+		t.Fatalf("NewSparse2D returned error: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(7))
+	for y := 26; y < 38; y++ {
+		for x := 26; x < 38; x++ {
+			if rng.Intn(3) == 0 {
+				dense.Set(core.NewCoord2D(x, y), core.Alive)
+				sparse.Set(core.NewCoord2D(x, y), core.Alive)
+			}
+		}
+	}
+
+	// Stay well clear of the dense universe's edges
+	for gen := 0; gen < 5; gen++ {
+		dense.Step()
+		sparse.Step()
+	}
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := core.NewCoord2D(x, y)
+			if dense.Get(c) != sparse.Get(c) {
+				t.Fatalf("Mismatch at (%d,%d): dense=%d sparse=%d", x, y, dense.Get(c), sparse.Get(c))
+			}
+		}
+	}
+}
+
+func TestWeighted_3DParallelMatchesSequential(t *testing.T) {
+	rule := rules.NewDistanceDecayRule(rules.Life3D_B6S567{}, core.MooreNeighborhood(2), 1)
+	seq := New3D(14, 14, 14, rule)
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 600; i++ {
+		seq.Set(core.NewCoord3D(rng.Intn(14), rng.Intn(14), rng.Intn(14)), core.Alive)
+	}
+	par := seq.Clone().(*Universe3D)
+
+	for gen := 0; gen < 4; gen++ {
+		seq.Step()
+		par.StepParallel()
+	}
+
+	for i := range seq.cells {
+		if seq.cells[i] != par.cells[i] {
+			t.Fatalf("Mismatch at index %d: sequential=%d parallel=%d", i, seq.cells[i], par.cells[i])
+		}
+	}
+}
+
+func TestWeighted_4DCountsWithWeights(t *testing.T) {
+	rule := rules.NewDistanceDecayRule(rules.Life4D_B9S7_10{}, core.VonNeumannNeighborhood(2), 1)
+	u := New4D(5, 5, 5, 5, rule)
+
+	// Two cells at distance 1 and one at distance 2 along the axes
+	u.Set(core.NewCoord4D(3, 2, 2, 2), core.Alive)
+	u.Set(core.NewCoord4D(2, 2, 2, 3), core.Alive)
+	u.Set(core.NewCoord4D(2, 2, 4, 2), core.Alive)
+
+	// 1 + 1 + 0.5 = 2.5 -> 3
+	if got := u.countNeighbors(2, 2, 2, 2); got != 3 {
+		t.Errorf("4D weighted count = %d, want 3", got)
+	}
+}
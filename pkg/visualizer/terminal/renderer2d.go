@@ -2,6 +2,7 @@ package terminal
 
 import (
 	"fmt"
+	"image/color"
 
 	"golife/pkg/core"
 	"golife/pkg/engine"
@@ -14,6 +15,7 @@ import (
 type Renderer2D struct {
 	showStats bool
 	colorMode string
+	palette   []termbox.Attribute // Color per state for rule tables; nil otherwise
 }
 
 // NewRenderer2D creates a new 2D renderer
@@ -24,9 +26,21 @@ func NewRenderer2D(showStats bool, colorMode string) *Renderer2D {
 	}
 }
 
+// SetPalette sets one color per state, used to draw rule table universes
+// (for example the colors of a rules.RuleTable). Each color is mapped to the
+// nearest of the eight terminal colors.
+func (r *Renderer2D) SetPalette(colors []color.RGBA) {
+	r.palette = make([]termbox.Attribute, len(colors))
+	for i, c := range colors {
+		r.palette[i] = nearestTermColor(c)
+	}
+}
+
 // Render renders the universe to the terminal
 func (r *Renderer2D) Render(u *universe.Universe2D, stats *engine.Statistics, showHelp bool) error {
-	if r.colorMode == "age" {
+	if r.palette != nil {
+		r.renderWithPalette(u)
+	} else if r.colorMode == "age" {
 		if err := r.renderWithColor(u); err != nil {
 			return err
 		}
@@ -49,11 +63,16 @@ func (r *Renderer2D) Render(u *universe.Universe2D, stats *engine.Statistics, sh
 
 // renderPlain renders the universe without colors
 func (r *Renderer2D) renderPlain(u *universe.Universe2D) error {
+	decaying := hasDecayStates(u)
 	for y := 0; y < u.Height(); y++ {
 		for x := 0; x < u.Width(); x++ {
 			var dot = ' '
 			coord := core.NewCoord2D(x, y)
-			if u.Get(coord) != core.Dead {
+			switch state := u.Get(coord); {
+			case state == core.Dead:
+			case decaying && state != core.Alive:
+				dot = '.' // Decaying Generations state
+			default:
 				dot = '*'
 			}
 			termbox.SetCell(x, y, dot, termbox.ColorDefault, termbox.ColorDefault)
@@ -64,13 +83,19 @@ func (r *Renderer2D) renderPlain(u *universe.Universe2D) error {
 
 // renderWithColor renders the universe with age-based colors
 func (r *Renderer2D) renderWithColor(u *universe.Universe2D) error {
+	decaying := hasDecayStates(u)
 	for y := 0; y < u.Height(); y++ {
 		for x := 0; x < u.Width(); x++ {
 			var dot = ' '
 			color := termbox.ColorDefault
 			coord := core.NewCoord2D(x, y)
 
-			if u.Get(coord) != core.Dead {
+			switch state := u.Get(coord); {
+			case state == core.Dead:
+			case decaying && state != core.Alive:
+				dot = '.'
+				color = getColorByDecay(state)
+			default:
 				dot = '*'
 				age := u.GetAge(x, y)
 				color = getColorByAge(age)
@@ -81,6 +106,51 @@ func (r *Renderer2D) renderWithColor(u *universe.Universe2D) error {
 	return nil
 }
 
+// renderWithPalette draws every non-empty cell as a block in its state's color
+func (r *Renderer2D) renderWithPalette(u *universe.Universe2D) {
+	for y := 0; y < u.Height(); y++ {
+		for x := 0; x < u.Width(); x++ {
+			var dot = ' '
+			color := termbox.ColorDefault
+			state := u.Get(core.NewCoord2D(x, y))
+			if state != core.Dead {
+				dot = '█'
+				if int(state) < len(r.palette) {
+					color = r.palette[state]
+				}
+			}
+			termbox.SetCell(x, y, dot, color, termbox.ColorDefault)
+		}
+	}
+}
+
+// termColors lists the eight basic terminal colors and their RGB values
+var termColors = []struct {
+	attr    termbox.Attribute
+	r, g, b int
+}{
+	{termbox.ColorBlack, 0, 0, 0},
+	{termbox.ColorRed, 255, 0, 0},
+	{termbox.ColorGreen, 0, 255, 0},
+	{termbox.ColorYellow, 255, 255, 0},
+	{termbox.ColorBlue, 0, 0, 255},
+	{termbox.ColorMagenta, 255, 0, 255},
+	{termbox.ColorCyan, 0, 255, 255},
+	{termbox.ColorWhite, 255, 255, 255},
+}
+
+// nearestTermColor returns the basic terminal color closest to c
+func nearestTermColor(c color.RGBA) termbox.Attribute {
+	best, bestDist := termbox.ColorDefault, -1
+	for _, tc := range termColors {
+		dr, dg, db := int(c.R)-tc.r, int(c.G)-tc.g, int(c.B)-tc.b
+		if dist := dr*dr + dg*dg + db*db; bestDist < 0 || dist < bestDist {
+			best, bestDist = tc.attr, dist
+		}
+	}
+	return best
+}
+
 // getColorByAge returns the color based on cell age
 func getColorByAge(age int) termbox.Attribute {
 	if age == 0 {
@@ -96,6 +166,25 @@ func getColorByAge(age int) termbox.Attribute {
 	}
 }
 
+// hasDecayStates reports whether the universe runs a Generations rule,
+// whose intermediate states should be drawn as decaying cells
+func hasDecayStates(u *universe.Universe2D) bool {
+	ms, ok := u.Rule().(core.MultiStateRule)
+	return ok && ms.States() > 2
+}
+
+// getColorByDecay returns the color for a decaying Generations state
+// The state value is the number of generations left before the cell dies
+func getColorByDecay(state core.CellState) termbox.Attribute {
+	if state == 1 {
+		return termbox.ColorBlue // About to die
+	} else if state == 2 {
+		return termbox.ColorMagenta
+	} else {
+		return termbox.ColorRed // Recently dying
+	}
+}
+
 // displayStatistics displays statistics on the screen
 func (r *Renderer2D) displayStatistics(stats *engine.Statistics, width, height int) {
 	// Calculate position (top-right corner)
@@ -113,9 +202,21 @@ func (r *Renderer2D) displayStatistics(stats *engine.Statistics, width, height i
 		fmt.Sprintf("║ Living cells: %-15d ║", stats.LivingCells),
 		fmt.Sprintf("║ Births: +%-20d ║", stats.Births),
 		fmt.Sprintf("║ Deaths: -%-20d ║", stats.Deaths),
+		fmt.Sprintf("║ Density: %-20s ║", fmt.Sprintf("%.1f%%", stats.Density*100)),
 		fmt.Sprintf("║ FPS: %-24.1f ║", stats.FPS),
-		"╚═══════════════════════════════╝",
 	}
+	if stats.MaxAge > 0 {
+		ages := fmt.Sprintf("%.1f / %d", stats.MeanAge, stats.MaxAge)
+		lines = append(lines, fmt.Sprintf("║ Age mean/max: %-15s ║", ages))
+	}
+	if stats.TotalTiles > 0 {
+		tiles := fmt.Sprintf("%d/%d", stats.ActiveTiles, stats.TotalTiles)
+		lines = append(lines, fmt.Sprintf("║ Active tiles: %-15s ║", tiles))
+	}
+	if stats.Seed != 0 {
+		lines = append(lines, fmt.Sprintf("║ Seed: %-23d ║", stats.Seed))
+	}
+	lines = append(lines, "╚═══════════════════════════════╝")
 
 	for i, line := range lines {
 		for j, ch := range line {
@@ -127,7 +228,7 @@ func (r *Renderer2D) displayStatistics(stats *engine.Statistics, width, height i
 // displayHelp displays keyboard controls
 func (r *Renderer2D) displayHelp(height int) {
 	startX := 2
-	startY := height - 10
+	startY := height - 11
 
 	if startY < 0 {
 		return
@@ -138,6 +239,7 @@ func (r *Renderer2D) displayHelp(height int) {
 		"║ Controls:                     ║",
 		"║ Space - Pause/Resume          ║",
 		"║ n     - Next step             ║",
+		"║ b     - Step back             ║",
 		"║ +/-   - Speed up/down         ║",
 		"║ r     - Restart (random)      ║",
 		"║ q/Esc - Quit                  ║",
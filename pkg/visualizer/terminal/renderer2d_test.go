@@ -0,0 +1,44 @@
+package terminal
+
+import (
+	"golife/pkg/rules"
+	"image/color"
+	"testing"
+
+	termbox "github.com/nsf/termbox-go"
+)
+
+func TestNearestTermColor(t *testing.T) {
+	tests := []struct {
+		color color.RGBA
+		want  termbox.Attribute
+	}{
+		{color.RGBA{A: 255}, termbox.ColorBlack},
+		{color.RGBA{R: 48, G: 48, B: 48, A: 255}, termbox.ColorBlack},
+		{color.RGBA{R: 230, G: 20, B: 10, A: 255}, termbox.ColorRed},
+		{color.RGBA{R: 255, G: 128, A: 255}, termbox.ColorYellow},
+		{color.RGBA{G: 128, B: 255, A: 255}, termbox.ColorCyan},
+		{color.RGBA{R: 255, G: 255, B: 255, A: 255}, termbox.ColorWhite},
+	}
+
+	for _, tt := range tests {
+		if got := nearestTermColor(tt.color); got != tt.want {
+			t.Errorf("nearestTermColor(%v) = %v, want %v", tt.color, got, tt.want)
+		}
+	}
+}
+
+func TestRenderer2D_SetPalette(t *testing.T) {
+	r := NewRenderer2D(false, "")
+	r.SetPalette(rules.Wireworld().Colors())
+
+	want := []termbox.Attribute{termbox.ColorBlack, termbox.ColorCyan, termbox.ColorWhite, termbox.ColorYellow}
+	if len(r.palette) != len(want) {
+		t.Fatalf("Expected %d palette entries, got %d", len(want), len(r.palette))
+	}
+	for i := range want {
+		if r.palette[i] != want[i] {
+			t.Errorf("Palette entry %d = %v, want %v", i, r.palette[i], want[i])
+		}
+	}
+}
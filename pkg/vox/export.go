@@ -0,0 +1,125 @@
+package vox
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+
+	"golife/pkg/core"
+	"golife/pkg/universe"
+)
+
+// Colorer picks the color index of a non-dead cell, or 0 to leave it out
+type Colorer func(c core.Coord, state core.CellState) uint8
+
+// StateColors colors cells by state: live cells get index 1, the dying
+// states of a Generations rule 2, 3, ... as they decay, and rule table
+// states their own index
+func StateColors(rule core.Rule) Colorer {
+	switch r := rule.(type) {
+	case core.TransitionRule:
+		return func(_ core.Coord, s core.CellState) uint8 { return uint8(max(int(s), 1)) }
+	case core.MultiStateRule:
+		n := r.States()
+		return func(_ core.Coord, s core.CellState) uint8 {
+			if s == core.Alive || int(s) >= n-1 {
+				return 1
+			}
+			return uint8(min(n-int(s), 255))
+		}
+	}
+	return func(core.Coord, core.CellState) uint8 { return 1 }
+}
+
+// AgeColors colors the live cells of a 2.5D universe by how many
+// generations they have been alive, up to index 255. Other non-dead cells,
+// such as decaying Generations states, get index 1.
+func AgeColors(u *universe.Universe25D) Colorer {
+	return func(c core.Coord, _ core.CellState) uint8 {
+		return uint8(min(max(u.GetAge(c.X, c.Y, c.Z), 1), 255))
+	}
+}
+
+// Gradient returns a palette fading from one color at index 1 to another at
+// index n; the indices after n keep the last color. It suits StateColors,
+// with n the number of states less one, and AgeColors.
+func Gradient(from, to color.RGBA, n int) *Palette {
+	n = min(max(n, 1), 255)
+	var p Palette
+	for i := 1; i < len(p); i++ {
+		t := 1.0
+		if n > 1 {
+			t = min(float64(i-1)/float64(n-1), 1)
+		}
+		mix := func(a, b uint8) uint8 { return uint8(float64(a) + t*(float64(b)-float64(a)) + 0.5) }
+		p[i] = color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: mix(from.A, to.A)}
+	}
+	return &p
+}
+
+// Options control how universes are exported
+type Options struct {
+	Colors  Colorer  // Nil colors by state under the universe's rule
+	Palette *Palette // Nil keeps MagicaVoxel's default palette
+}
+
+// FromUniverse builds a model of a 3D or 2.5D universe's non-dead cells,
+// with the universe's z axis pointing up
+func FromUniverse(u core.Universe, colors Colorer) (Model, error) {
+	size := u.Size()
+	if size.X > MaxSize || size.Y > MaxSize || size.Z > MaxSize {
+		return Model{}, fmt.Errorf("universe of %dx%dx%d cells is larger than a %d³ vox model", size.X, size.Y, size.Z, MaxSize)
+	}
+	m := Model{SizeX: size.X, SizeY: size.Y, SizeZ: size.Z}
+	for z := 0; z < size.Z; z++ {
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				c := core.NewCoord3D(x, y, z)
+				state := u.Get(c)
+				if state == core.Dead {
+					continue
+				}
+				if index := colors(c, state); index != 0 {
+					m.Voxels = append(m.Voxels, Voxel{X: uint8(x), Y: uint8(y), Z: uint8(z), Color: index})
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// Export writes the current state of a Universe3D or Universe25D as a
+// single-model .vox file
+func Export(w io.Writer, u core.Universe, opts Options) error {
+	return ExportAnimation(w, u, 1, opts)
+}
+
+// ExportAnimation writes frames generations of a Universe3D or Universe25D
+// as the frames of a .vox animation, starting from the current state. The
+// universe is stepped frames-1 times.
+func ExportAnimation(w io.Writer, u core.Universe, frames int, opts Options) error {
+	if frames < 1 {
+		return fmt.Errorf("animation needs at least one frame, got %d", frames)
+	}
+	colors := opts.Colors
+	if colors == nil {
+		ruled, ok := u.(interface{ Rule() core.Rule })
+		if !ok {
+			return fmt.Errorf("universe has no rule to color by state; set Options.Colors")
+		}
+		colors = StateColors(ruled.Rule())
+	}
+
+	f := &File{Palette: opts.Palette}
+	for i := 0; i < frames; i++ {
+		if i > 0 {
+			u.Step()
+		}
+		m, err := FromUniverse(u, colors)
+		if err != nil {
+			return err
+		}
+		f.Models = append(f.Models, m)
+	}
+	return Write(w, f)
+}
@@ -0,0 +1,260 @@
+// Package vox reads and writes voxel models in the MagicaVoxel .vox format,
+// so 3D and 2.5D universes can be rendered in external voxel tools.
+package vox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// MaxSize is the largest side of a model; voxel coordinates are single bytes
+const MaxSize = 256
+
+// version is the format version written in the header
+const version = 200
+
+// Voxel is a filled voxel and its color index (1-255) in the palette
+type Voxel struct {
+	X, Y, Z uint8
+	Color   uint8
+}
+
+// Model is a box of SizeX×SizeY×SizeZ voxels, of which Voxels are filled.
+// MagicaVoxel's z axis points up.
+type Model struct {
+	SizeX, SizeY, SizeZ int
+	Voxels              []Voxel
+}
+
+// Palette holds the colors of the color indices 1-255; entry 0 is unused
+type Palette [256]color.RGBA
+
+// File is the contents of a .vox file. Several models are written as the
+// frames of an animation. A nil Palette leaves MagicaVoxel's default
+// palette in place.
+type File struct {
+	Models  []Model
+	Palette *Palette
+}
+
+// Write writes f in the .vox format: a MAIN chunk holding a SIZE and XYZI
+// chunk per model, an RGBA chunk for the palette and, for several models,
+// the scene graph that plays them as animation frames.
+func Write(w io.Writer, f *File) error {
+	if len(f.Models) == 0 {
+		return fmt.Errorf("vox file needs at least one model")
+	}
+
+	var children bytes.Buffer
+	for i, m := range f.Models {
+		if m.SizeX < 1 || m.SizeY < 1 || m.SizeZ < 1 || m.SizeX > MaxSize || m.SizeY > MaxSize || m.SizeZ > MaxSize {
+			return fmt.Errorf("model %d: size %dx%dx%d is outside 1-%d", i, m.SizeX, m.SizeY, m.SizeZ, MaxSize)
+		}
+		writeChunk(&children, "SIZE", int32s(m.SizeX, m.SizeY, m.SizeZ))
+
+		xyzi := int32s(len(m.Voxels))
+		for _, v := range m.Voxels {
+			if int(v.X) >= m.SizeX || int(v.Y) >= m.SizeY || int(v.Z) >= m.SizeZ {
+				return fmt.Errorf("model %d: voxel (%d,%d,%d) is outside the model", i, v.X, v.Y, v.Z)
+			}
+			if v.Color == 0 {
+				return fmt.Errorf("model %d: voxel (%d,%d,%d) has color index 0", i, v.X, v.Y, v.Z)
+			}
+			xyzi = append(xyzi, v.X, v.Y, v.Z, v.Color)
+		}
+		writeChunk(&children, "XYZI", xyzi)
+	}
+	if len(f.Models) > 1 {
+		writeAnimation(&children, len(f.Models))
+	}
+	if f.Palette != nil {
+		rgba := make([]byte, 0, 256*4)
+		for i := 1; i <= 256; i++ {
+			var c color.RGBA // The 256th entry is unused
+			if i < 256 {
+				c = f.Palette[i]
+			}
+			rgba = append(rgba, c.R, c.G, c.B, c.A)
+		}
+		writeChunk(&children, "RGBA", rgba)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("VOX ")
+	binary.Write(bw, binary.LittleEndian, int32(version))
+	bw.WriteString("MAIN")
+	binary.Write(bw, binary.LittleEndian, [2]int32{0, int32(children.Len())})
+	bw.Write(children.Bytes())
+	return bw.Flush()
+}
+
+// writeAnimation writes the scene graph of an animation: a root transform
+// and group holding one transform over a shape whose models are the frames
+func writeAnimation(buf *bytes.Buffer, frames int) {
+	var node []byte
+	node = appendNode(nil, 0)
+	node = append(node, int32s(1, -1, -1, 1)...) // Child, reserved, layer, one frame
+	node = appendDict(node)
+	writeChunk(buf, "nTRN", node)
+
+	node = appendNode(nil, 1)
+	node = append(node, int32s(1, 2)...) // One child
+	writeChunk(buf, "nGRP", node)
+
+	node = appendNode(nil, 2)
+	node = append(node, int32s(3, -1, 0, 1)...)
+	node = appendDict(node)
+	writeChunk(buf, "nTRN", node)
+
+	node = appendNode(nil, 3)
+	node = append(node, int32s(frames)...)
+	for i := 0; i < frames; i++ {
+		node = append(node, int32s(i)...)
+		node = appendDict(node, "_f", strconv.Itoa(i))
+	}
+	writeChunk(buf, "nSHP", node)
+}
+
+// appendNode appends a scene graph node's id and empty attributes
+func appendNode(b []byte, id int) []byte {
+	return appendDict(append(b, int32s(id)...))
+}
+
+// appendDict appends a dictionary of the given key, value pairs
+func appendDict(b []byte, pairs ...string) []byte {
+	b = append(b, int32s(len(pairs)/2)...)
+	for _, s := range pairs {
+		b = append(b, int32s(len(s))...)
+		b = append(b, s...)
+	}
+	return b
+}
+
+// writeChunk writes a chunk without children
+func writeChunk(buf *bytes.Buffer, id string, content []byte) {
+	buf.WriteString(id)
+	binary.Write(buf, binary.LittleEndian, [2]int32{int32(len(content)), 0})
+	buf.Write(content)
+}
+
+// int32s encodes values as little-endian int32s
+func int32s(values ...int) []byte {
+	b := make([]byte, 0, 4*len(values))
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint32(b, uint32(int32(v)))
+	}
+	return b
+}
+
+// Read reads a .vox file: its models in file order and its palette, or a
+// nil palette when the file has none. Scene graph, material and other
+// chunks are skipped.
+func Read(r io.Reader) (*File, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("failed to read vox header: %w", err)
+	}
+	if string(header[:4]) != "VOX " {
+		return nil, fmt.Errorf("not a vox file: missing \"VOX \" header")
+	}
+
+	id, _, children, err := readChunk(r)
+	if err != nil {
+		return nil, err
+	}
+	if id != "MAIN" {
+		return nil, fmt.Errorf("invalid vox: first chunk is %q, want MAIN", id)
+	}
+
+	f := &File{}
+	sized := false // A SIZE chunk is waiting for its XYZI chunk
+	rest := bytes.NewReader(children)
+	for rest.Len() > 0 {
+		id, content, _, err := readChunk(rest)
+		if err != nil {
+			return nil, err
+		}
+		switch id {
+		case "SIZE":
+			if len(content) < 12 {
+				return nil, fmt.Errorf("invalid vox: SIZE chunk of %d bytes", len(content))
+			}
+			m := Model{SizeX: intAt(content, 0), SizeY: intAt(content, 4), SizeZ: intAt(content, 8)}
+			if m.SizeX < 1 || m.SizeY < 1 || m.SizeZ < 1 || m.SizeX > MaxSize || m.SizeY > MaxSize || m.SizeZ > MaxSize {
+				return nil, fmt.Errorf("invalid vox: model size %dx%dx%d", m.SizeX, m.SizeY, m.SizeZ)
+			}
+			f.Models = append(f.Models, m)
+			sized = true
+		case "XYZI":
+			if !sized {
+				return nil, fmt.Errorf("invalid vox: XYZI chunk without a SIZE chunk")
+			}
+			if len(content) < 4 {
+				return nil, fmt.Errorf("invalid vox: XYZI chunk of %d bytes", len(content))
+			}
+			n := intAt(content, 0)
+			if n < 0 || n > (len(content)-4)/4 {
+				return nil, fmt.Errorf("invalid vox: XYZI chunk of %d bytes cannot hold %d voxels", len(content), n)
+			}
+			m := &f.Models[len(f.Models)-1]
+			m.Voxels = make([]Voxel, n)
+			for i := range m.Voxels {
+				b := content[4+4*i:]
+				m.Voxels[i] = Voxel{X: b[0], Y: b[1], Z: b[2], Color: b[3]}
+			}
+			sized = false
+		case "RGBA":
+			if len(content) < 256*4 {
+				return nil, fmt.Errorf("invalid vox: RGBA chunk of %d bytes", len(content))
+			}
+			f.Palette = &Palette{}
+			for i := 1; i < 256; i++ {
+				b := content[4*(i-1):]
+				f.Palette[i] = color.RGBA{R: b[0], G: b[1], B: b[2], A: b[3]}
+			}
+		}
+	}
+	if len(f.Models) == 0 {
+		return nil, fmt.Errorf("invalid vox: no models")
+	}
+	return f, nil
+}
+
+// readChunk reads a chunk's id, content and children
+func readChunk(r io.Reader) (string, []byte, []byte, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return "", nil, nil, fmt.Errorf("invalid vox: truncated chunk header: %w", err)
+	}
+	id := string(header[:4])
+	contentSize, childrenSize := intAt(header[:], 4), intAt(header[:], 8)
+	if contentSize < 0 || childrenSize < 0 {
+		return "", nil, nil, fmt.Errorf("invalid vox: %s chunk has a negative size", id)
+	}
+	// Read in steps rather than trusting the sizes with one allocation
+	content, err := io.ReadAll(io.LimitReader(r, int64(contentSize)))
+	if err == nil && len(content) < contentSize {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid vox: truncated %s chunk: %w", id, err)
+	}
+	children, err := io.ReadAll(io.LimitReader(r, int64(childrenSize)))
+	if err == nil && len(children) < childrenSize {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid vox: truncated %s chunk: %w", id, err)
+	}
+	return id, content, children, nil
+}
+
+// intAt decodes the little-endian int32 at offset i
+func intAt(b []byte, i int) int {
+	return int(int32(binary.LittleEndian.Uint32(b[i:])))
+}
@@ -0,0 +1,269 @@
+package vox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"golife/pkg/core"
+	"golife/pkg/patterns"
+	"golife/pkg/rules"
+	"golife/pkg/universe"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// sameVoxels reports whether a model holds exactly the non-dead cells of a
+// universe, colored by colors
+func sameVoxels(m Model, u core.Universe, colors Colorer) bool {
+	count := 0
+	for _, v := range m.Voxels {
+		c := core.NewCoord3D(int(v.X), int(v.Y), int(v.Z))
+		state := u.Get(c)
+		if state == core.Dead || colors(c, state) != v.Color {
+			return false
+		}
+		count++
+	}
+	size := u.Size()
+	living := 0
+	for z := 0; z < size.Z; z++ {
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				if u.Get(core.NewCoord3D(x, y, z)) != core.Dead {
+					living++
+				}
+			}
+		}
+	}
+	return count == living
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	palette := Gradient(color.RGBA{255, 255, 255, 255}, color.RGBA{40, 0, 0, 255}, 4)
+	f := &File{
+		Models: []Model{
+			{SizeX: 3, SizeY: 2, SizeZ: 1, Voxels: []Voxel{{0, 0, 0, 1}, {2, 1, 0, 4}}},
+			{SizeX: 256, SizeY: 1, SizeZ: 5, Voxels: []Voxel{{255, 0, 4, 255}}},
+			{SizeX: 1, SizeY: 1, SizeZ: 1},
+		},
+		Palette: palette,
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, f); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	data := buf.Bytes()
+	if string(data[:4]) != "VOX " || binary.LittleEndian.Uint32(data[4:]) != version || string(data[8:12]) != "MAIN" {
+		t.Errorf("Unexpected header % x", data[:12])
+	}
+	if !bytes.Contains(data, []byte("nSHP")) {
+		t.Error("Several models should be written with an animation scene graph")
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got.Models[:2], f.Models[:2]) || len(got.Models[2].Voxels) != 0 {
+		t.Errorf("Round trip changed the models:\n%v\nwant\n%v", got.Models, f.Models)
+	}
+	if got.Palette == nil || *got.Palette != *palette {
+		t.Errorf("Round trip changed the palette")
+	}
+}
+
+func TestWrite_DefaultPalette(t *testing.T) {
+	var buf bytes.Buffer
+	f := &File{Models: []Model{{SizeX: 2, SizeY: 2, SizeZ: 2, Voxels: []Voxel{{1, 1, 1, 9}}}}}
+	if err := Write(&buf, f); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("RGBA")) || bytes.Contains(buf.Bytes(), []byte("nSHP")) {
+		t.Error("A single model without a palette needs neither an RGBA chunk nor a scene graph")
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if got.Palette != nil || !reflect.DeepEqual(got.Models, f.Models) {
+		t.Errorf("Read = %+v, want %+v", got, f)
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	for name, f := range map[string]*File{
+		"no models":     {},
+		"too large":     {Models: []Model{{SizeX: 257, SizeY: 1, SizeZ: 1}}},
+		"empty size":    {Models: []Model{{SizeX: 0, SizeY: 1, SizeZ: 1}}},
+		"outside":       {Models: []Model{{SizeX: 2, SizeY: 2, SizeZ: 2, Voxels: []Voxel{{2, 0, 0, 1}}}}},
+		"color index 0": {Models: []Model{{SizeX: 2, SizeY: 2, SizeZ: 2, Voxels: []Voxel{{0, 0, 0, 0}}}}},
+	} {
+		if err := Write(&bytes.Buffer{}, f); err == nil {
+			t.Errorf("Write should reject %s", name)
+		}
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	var valid bytes.Buffer
+	Write(&valid, &File{Models: []Model{{SizeX: 2, SizeY: 2, SizeZ: 2, Voxels: []Voxel{{1, 1, 1, 1}}}}})
+	data := valid.Bytes()
+
+	badCount := bytes.Clone(data)
+	i := bytes.Index(badCount, []byte("XYZI"))
+	binary.LittleEndian.PutUint32(badCount[i+12:], 1000) // More voxels than the chunk holds
+
+	for name, input := range map[string][]byte{
+		"empty":        nil,
+		"wrong magic":  append([]byte("VOZ "), data[4:]...),
+		"truncated":    data[:len(data)-3],
+		"no main":      append(bytes.Clone(data[:8]), []byte("SIZE\x00\x00\x00\x00\x00\x00\x00\x00")...),
+		"voxel count":  badCount,
+		"no models":    []byte("VOX \xc8\x00\x00\x00MAIN\x00\x00\x00\x00\x00\x00\x00\x00"),
+		"huge content": []byte("VOX \xc8\x00\x00\x00MAIN\xff\xff\xff\x7f\x00\x00\x00\x00"),
+	} {
+		if _, err := Read(bytes.NewReader(input)); err == nil {
+			t.Errorf("Read should reject %s input", name)
+		}
+	}
+}
+
+func TestExport_Universe3D(t *testing.T) {
+	u := universe.New3D(16, 12, 10, rules.Life3D_B6S567{})
+	patterns.BaysGlider().LoadIntoUniverse3D(u, 5, 4, 3)
+
+	var buf bytes.Buffer
+	if err := Export(&buf, u, Options{}); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	f, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	m := f.Models[0]
+	if len(f.Models) != 1 || m.SizeX != 16 || m.SizeY != 12 || m.SizeZ != 10 {
+		t.Fatalf("Expected one 16x12x10 model, got %d models of %dx%dx%d", len(f.Models), m.SizeX, m.SizeY, m.SizeZ)
+	}
+	if !sameVoxels(m, u, StateColors(u.Rule())) {
+		t.Errorf("The model's %d voxels differ from the universe's %d cells", len(m.Voxels), u.CountLiving())
+	}
+}
+
+func TestExportAnimation(t *testing.T) {
+	rule, err := rules.Parse("B6/S567/C5")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	u := universe.New3D(12, 12, 12, rule)
+	universe.RandomFill(u, universe.RandomOptions{Seed: 11, Density: 0.3})
+	reference := u.Clone().(*universe.Universe3D)
+	palette := Gradient(color.RGBA{0, 255, 0, 255}, color.RGBA{0, 40, 80, 255}, 4)
+
+	var buf bytes.Buffer
+	if err := ExportAnimation(&buf, u, 6, Options{Palette: palette}); err != nil {
+		t.Fatalf("ExportAnimation returned error: %v", err)
+	}
+	f, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if len(f.Models) != 6 || f.Palette == nil {
+		t.Fatalf("Expected 6 frames and a palette, got %d frames", len(f.Models))
+	}
+
+	colors := StateColors(rule)
+	decaying := false
+	for i, m := range f.Models {
+		if i > 0 {
+			reference.Step()
+		}
+		if !sameVoxels(m, reference, colors) {
+			t.Errorf("Frame %d differs from generation %d", i, i)
+		}
+		for _, v := range m.Voxels {
+			decaying = decaying || v.Color > 1
+		}
+	}
+	if !decaying {
+		t.Error("Expected decaying cells to get their own color indices")
+	}
+
+	if err := ExportAnimation(&bytes.Buffer{}, u, 0, Options{}); err == nil {
+		t.Error("ExportAnimation should reject zero frames")
+	}
+}
+
+func TestExport_Universe25DAges(t *testing.T) {
+	u := universe.New25D(20, 20, 3, rules.ConwayRule{})
+	u.RandomizeWith(universe.RandomOptions{Seed: 7, Density: 0.35})
+	for i := 0; i < 5; i++ {
+		u.Step()
+	}
+
+	colors := AgeColors(u)
+	var buf bytes.Buffer
+	if err := Export(&buf, u, Options{Colors: colors, Palette: Gradient(color.RGBA{255, 255, 0, 255}, color.RGBA{255, 0, 0, 255}, 6)}); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	f, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if !sameVoxels(f.Models[0], u, colors) {
+		t.Errorf("The model's %d voxels differ from the universe's %d cells", len(f.Models[0].Voxels), u.CountLiving())
+	}
+	oldest := uint8(0)
+	for _, v := range f.Models[0].Voxels {
+		oldest = max(oldest, v.Color)
+	}
+	if oldest < 2 {
+		t.Error("Expected cells that survived several generations to get older colors")
+	}
+}
+
+func TestFromUniverse_TooLarge(t *testing.T) {
+	u := universe.New3D(257, 2, 2, rules.Life3D_B6S567{})
+	if _, err := FromUniverse(u, StateColors(u.Rule())); err == nil {
+		t.Error("FromUniverse should reject universes wider than 256 cells")
+	}
+}
+
+func TestStateColors(t *testing.T) {
+	generations, err := rules.Parse("B2/S/C4")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	wireworld, err := rules.Parse("wireworld")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	c := core.NewCoord3D(0, 0, 0)
+	tests := []struct {
+		rule  core.Rule
+		state core.CellState
+		want  uint8
+	}{
+		{rules.Life3D_B6S567{}, core.Alive, 1},
+		{generations, core.Alive, 1},
+		{generations, 2, 2}, // First dying state
+		{generations, 1, 3}, // Last dying state
+		{wireworld, 3, 3},
+	}
+	for _, tt := range tests {
+		if got := StateColors(tt.rule)(c, tt.state); got != tt.want {
+			t.Errorf("StateColors(%s)(%d) = %d, want %d", tt.rule.Name(), tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	from, to := color.RGBA{0, 0, 0, 255}, color.RGBA{200, 100, 50, 255}
+	p := Gradient(from, to, 3)
+	if p[1] != from || p[3] != to || p[255] != to {
+		t.Errorf("Gradient ends = %v, %v, %v", p[1], p[3], p[255])
+	}
+	if p[2] != (color.RGBA{100, 50, 25, 255}) {
+		t.Errorf("Gradient middle = %v", p[2])
+	}
+}
@@ -4,25 +4,35 @@
 package wasm
 
 import (
+	"bytes"
 	"encoding/json"
 	"golife/pkg/core"
+	"golife/pkg/engine"
 	"golife/pkg/patterns"
 	"golife/pkg/rules"
 	"golife/pkg/universe"
 	"syscall/js"
 )
 
-// Global universe instance
+// Global universe instance and the simulation stepping it
 var (
 	currentUniverse *universe.Universe3D
-	generation      int
+	currentSim      *engine.Simulation
+	currentRule     core.Rule = rules.Life3D_B6S567{}
+	baseGeneration  int       // Generations stepped before the last rule change
 )
 
+// generation returns the number of generations stepped since initUniverse
+func generation() int {
+	return baseGeneration + currentSim.Generation()
+}
+
 // CellData represents a single living cell for JSON serialization
 type CellData struct {
-	X int `json:"x"`
-	Y int `json:"y"`
-	Z int `json:"z"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Z     int `json:"z"`
+	State int `json:"state"` // 255 = alive, 1-254 = decaying Generations state
 }
 
 // UniverseState represents the current state of the universe
@@ -33,14 +43,15 @@ type UniverseState struct {
 	Width      int        `json:"width"`
 	Height     int        `json:"height"`
 	Depth      int        `json:"depth"`
+	Rule       string     `json:"rule"`
 }
 
 // InitUniverse creates a new 3D universe with the given dimensions
-// JavaScript call: initUniverse(width, height, depth)
+// JavaScript call: initUniverse(width, height, depth[, rulestring])
 func InitUniverse(this js.Value, args []js.Value) interface{} {
-	if len(args) != 3 {
+	if len(args) != 3 && len(args) != 4 {
 		return map[string]interface{}{
-			"error": "initUniverse requires 3 arguments: width, height, depth",
+			"error": "initUniverse requires 3 or 4 arguments: width, height, depth[, rulestring]",
 		}
 	}
 
@@ -55,21 +66,87 @@ func InitUniverse(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	// Create universe with B6/S567 rule (3D Life)
-	rule := rules.Life3D_B6S567{}
+	// Default to the B6/S567 rule (3D Life)
+	var rule core.Rule = rules.Life3D_B6S567{}
+	if len(args) == 4 {
+		parsed, err := rules.Parse(args[3].String())
+		if err == nil {
+			err = universe.Check3DRule(parsed)
+		}
+		if err != nil {
+			return map[string]interface{}{
+				"error": err.Error(),
+			}
+		}
+		rule = parsed
+	}
+
+	currentRule = rule
 	currentUniverse = universe.New3D(width, height, depth, rule)
-	generation = 0
+	currentSim = engine.NewSimulation(currentUniverse)
+	baseGeneration = 0
 
 	return map[string]interface{}{
 		"success": true,
 		"width":   width,
 		"height":  height,
 		"depth":   depth,
+		"rule":    rule.Name(),
+	}
+}
+
+// SetRule switches the universe to a new rule, keeping the current cells
+// JavaScript call: setRule(rulestring)
+func SetRule(this js.Value, args []js.Value) interface{} {
+	if currentUniverse == nil {
+		return map[string]interface{}{
+			"error": "universe not initialized",
+		}
+	}
+
+	if len(args) != 1 {
+		return map[string]interface{}{
+			"error": "setRule requires 1 argument: rulestring",
+		}
+	}
+
+	rule, err := rules.Parse(args[0].String())
+	if err == nil {
+		err = universe.Check3DRule(rule)
+	}
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	size := currentUniverse.Size()
+	next := universe.New3D(size.X, size.Y, size.Z, rule)
+	next.SetBoundary(currentUniverse.Boundary())
+	next.SetNeighborhood(currentUniverse.Neighborhood())
+	for z := 0; z < size.Z; z++ {
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				coord := core.NewCoord3D(x, y, z)
+				next.Set(coord, currentUniverse.Get(coord))
+			}
+		}
+	}
+
+	currentRule = rule
+	baseGeneration = generation()
+	currentUniverse = next
+	currentSim = engine.NewSimulation(next)
+
+	return map[string]interface{}{
+		"success": true,
+		"rule":    rule.Name(),
 	}
 }
 
-// LoadPattern loads a predefined pattern into the universe
-// JavaScript call: loadPattern(patternName, x, y, z)
+// LoadPattern loads a built-in pattern, or pattern text in the 3D pattern
+// format, into the universe
+// JavaScript call: loadPattern(patternNameOrText, x, y, z)
 func LoadPattern(this js.Value, args []js.Value) interface{} {
 	if currentUniverse == nil {
 		return map[string]interface{}{
@@ -79,7 +156,7 @@ func LoadPattern(this js.Value, args []js.Value) interface{} {
 
 	if len(args) != 4 {
 		return map[string]interface{}{
-			"error": "loadPattern requires 4 arguments: patternName, x, y, z",
+			"error": "loadPattern requires 4 arguments: patternNameOrText, x, y, z",
 		}
 	}
 
@@ -88,26 +165,11 @@ func LoadPattern(this js.Value, args []js.Value) interface{} {
 	y := args[2].Int()
 	z := args[3].Int()
 
-	// Get pattern
-	var pattern *patterns.Pattern3D
-	switch patternName {
-	case "glider":
-		pattern = patterns.BaysGlider()
-	case "block":
-		pattern = patterns.Block3D()
-	case "beehive":
-		pattern = patterns.Beehive3D()
-	case "blinker":
-		pattern = patterns.Blinker3D()
-	case "flashlight":
-		pattern = patterns.Flashlight3D()
-	case "wheel":
-		pattern = patterns.Wheel3D()
-	case "bucket":
-		pattern = patterns.Bucket3D()
-	default:
+	// A built-in name or pattern text
+	pattern, err := patterns.FindPattern3D(patternName, currentUniverse.Size())
+	if err != nil {
 		return map[string]interface{}{
-			"error": "unknown pattern: " + patternName,
+			"error": err.Error(),
 		}
 	}
 
@@ -116,15 +178,15 @@ func LoadPattern(this js.Value, args []js.Value) interface{} {
 
 	return map[string]interface{}{
 		"success": true,
-		"pattern": patternName,
+		"pattern": pattern.Name,
 		"x":       x,
 		"y":       y,
 		"z":       z,
 	}
 }
 
-// Step advances the simulation by one generation
-// JavaScript call: step()
+// Step advances the simulation by one generation, or by count generations
+// JavaScript call: step([count])
 func Step(this js.Value, args []js.Value) interface{} {
 	if currentUniverse == nil {
 		return map[string]interface{}{
@@ -132,13 +194,21 @@ func Step(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	// Use parallel step for better performance
-	currentUniverse.StepParallel()
-	generation++
+	count := 1
+	if len(args) > 0 {
+		count = args[0].Int()
+	}
+	if count < 1 {
+		return map[string]interface{}{
+			"error": "step count must be positive",
+		}
+	}
+
+	currentSim.Step(count)
 
 	return map[string]interface{}{
 		"success":    true,
-		"generation": generation,
+		"generation": generation(),
 	}
 }
 
@@ -178,8 +248,9 @@ func GetUniverseInfo(this js.Value, args []js.Value) interface{} {
 		"width":      size.X,
 		"height":     size.Y,
 		"depth":      size.Z,
-		"generation": generation,
+		"generation": generation(),
 		"population": currentUniverse.CountLiving(),
+		"rule":       currentRule.Name(),
 	}
 }
 
@@ -192,11 +263,40 @@ func ClearUniverse(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	currentUniverse.Clear()
-	generation = 0
+	currentSim.Do(func(u core.Universe) {
+		u.Clear()
+		baseGeneration = 0
+		currentSim.Reset()
+	})
+
+	return map[string]interface{}{
+		"success": true,
+	}
+}
+
+// SavePattern returns the universe as text in the 3D pattern format
+// JavaScript call: savePattern([name])
+func SavePattern(this js.Value, args []js.Value) interface{} {
+	if currentUniverse == nil {
+		return map[string]interface{}{
+			"error": "universe not initialized",
+		}
+	}
+
+	p := patterns.FromUniverse3D(currentUniverse)
+	if len(args) > 0 {
+		p.Name = args[0].String()
+	}
+	var buf bytes.Buffer
+	if err := patterns.WritePattern3D(&buf, p); err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 
 	return map[string]interface{}{
 		"success": true,
+		"pattern": buf.String(),
 	}
 }
 
@@ -242,8 +342,8 @@ func extractUniverseState() UniverseState {
 		for y := 0; y < size.Y; y++ {
 			for x := 0; x < size.X; x++ {
 				coord := core.NewCoord3D(x, y, z)
-				if currentUniverse.Get(coord) != core.Dead {
-					cells = append(cells, CellData{X: x, Y: y, Z: z})
+				if state := currentUniverse.Get(coord); state != core.Dead {
+					cells = append(cells, CellData{X: x, Y: y, Z: z, State: int(state)})
 				}
 			}
 		}
@@ -251,11 +351,12 @@ func extractUniverseState() UniverseState {
 
 	return UniverseState{
 		Cells:      cells,
-		Generation: generation,
+		Generation: generation(),
 		Population: currentUniverse.CountLiving(),
 		Width:      size.X,
 		Height:     size.Y,
 		Depth:      size.Z,
+		Rule:       currentRule.Name(),
 	}
 }
 
@@ -268,4 +369,6 @@ func RegisterCallbacks() {
 	js.Global().Set("goGetUniverseInfo", js.FuncOf(GetUniverseInfo))
 	js.Global().Set("goClearUniverse", js.FuncOf(ClearUniverse))
 	js.Global().Set("goSetCell", js.FuncOf(SetCell))
+	js.Global().Set("goSetRule", js.FuncOf(SetRule))
+	js.Global().Set("goSavePattern", js.FuncOf(SavePattern))
 }
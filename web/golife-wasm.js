@@ -79,18 +79,34 @@ class GoLifeWASM {
      * @param {number} width - Universe width
      * @param {number} height - Universe height
      * @param {number} depth - Universe depth
+     * @param {string} [rule] - Rulestring (e.g. 'B6/S567', '5766', 'B4/S4/C5'); defaults to B6/S567
      * @returns {Object} Result object with success/error
      */
-    initUniverse(width, height, depth) {
+    initUniverse(width, height, depth, rule) {
         if (!this.wasmReady) {
             return { error: 'WASM not ready' };
         }
-        return window.goInitUniverse(width, height, depth);
+        if (rule === undefined) {
+            return window.goInitUniverse(width, height, depth);
+        }
+        return window.goInitUniverse(width, height, depth, rule);
+    }
+
+    /**
+     * Switch to a new rule, keeping the current cells
+     * @param {string} rule - Rulestring (e.g. 'B6/S567', '5766', 'B4/S4/C5')
+     * @returns {Object} Result object with success/error and the rule name
+     */
+    setRule(rule) {
+        if (!this.wasmReady) {
+            return { error: 'WASM not ready' };
+        }
+        return window.goSetRule(rule);
     }
 
     /**
      * Load a pattern into the universe
-     * @param {string} patternName - Pattern name ('glider', 'block', 'tube')
+     * @param {string} patternName - Built-in pattern name ('glider', 'block', ...) or 3D pattern text
      * @param {number} x - X position
      * @param {number} y - Y position
      * @param {number} z - Z position
@@ -104,14 +120,15 @@ class GoLifeWASM {
     }
 
     /**
-     * Advance simulation by one generation
+     * Advance simulation by one or more generations
+     * @param {number} [count=1] - Number of generations to advance
      * @returns {Object} Result object with success/error and generation number
      */
-    step() {
+    step(count = 1) {
         if (!this.wasmReady) {
             return { error: 'WASM not ready' };
         }
-        return window.goStep();
+        return window.goStep(count);
     }
 
     /**
@@ -168,6 +185,18 @@ class GoLifeWASM {
         return window.goSetCell(x, y, z, alive);
     }
 
+    /**
+     * Save the universe as 3D pattern text
+     * @param {string} [name] - Pattern name written in the #N line
+     * @returns {Object} Result object with success/error and the pattern text
+     */
+    savePattern(name) {
+        if (!this.wasmReady) {
+            return { error: 'WASM not ready' };
+        }
+        return name === undefined ? window.goSavePattern() : window.goSavePattern(name);
+    }
+
     /**
      * Run simulation for N generations
      * @param {number} generations - Number of generations to run
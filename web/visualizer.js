@@ -13,8 +13,10 @@ class LifeVisualizer {
         this.fps = 0;
         this.lastTime = performance.now();
         this.frameCount = 0;
+        this.paused = false;
 
         this.init();
+        this.initHistoryControls();
         this.connect();
         this.animate();
     }
@@ -101,9 +103,44 @@ class LifeVisualizer {
         this.scene.add(this.instancedMesh);
     }
 
+    initHistoryControls() {
+        document.getElementById('pause-button').addEventListener('click', () => {
+            this.sendControl({ type: this.paused ? 'resume' : 'pause' });
+            this.setPaused(!this.paused); // No frames arrive while paused
+        });
+        document.getElementById('step-back-button').addEventListener('click', () => {
+            this.sendControl({ type: 'stepBack' });
+        });
+        // Dragging the slider seeks through the generations the server recorded
+        document.getElementById('history-scrub').addEventListener('input', (event) => {
+            this.sendControl({ type: 'seek', generation: Number(event.target.value) });
+        });
+        // A built-in name or pasted pattern text replaces the universe
+        document.getElementById('load-pattern-button').addEventListener('click', () => {
+            const pattern = document.getElementById('pattern-text').value.trim();
+            if (pattern) {
+                this.sendControl({ type: 'loadPattern', pattern });
+            }
+        });
+    }
+
+    setPaused(paused) {
+        this.paused = paused;
+        document.getElementById('pause-button').textContent = paused ? 'Resume' : 'Pause';
+    }
+
+    sendControl(message) {
+        if (this.ws && this.ws.readyState === WebSocket.OPEN) {
+            this.ws.send(JSON.stringify(message));
+        }
+    }
+
     connect() {
         const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-        const wsUrl = `${protocol}//${window.location.host}/ws`;
+        // Forward ?rule=... from the page URL so the server builds the requested rule
+        const rule = new URLSearchParams(window.location.search).get('rule');
+        const query = rule ? `?rule=${encodeURIComponent(rule)}` : '';
+        const wsUrl = `${protocol}//${window.location.host}/ws${query}`;
 
         console.log('Connecting to WebSocket:', wsUrl);
         this.ws = new WebSocket(wsUrl);
@@ -134,9 +171,22 @@ class LifeVisualizer {
         // Update info panel
         document.getElementById('generation').textContent = state.generation;
         document.getElementById('population').textContent = state.population;
+        if (state.tiles) {
+            document.getElementById('active-tiles').textContent =
+                `${state.activeTiles}/${state.tiles}`;
+        }
+        if (state.rule) {
+            document.getElementById('rule').textContent = state.rule;
+        }
         document.getElementById('universe-size').textContent =
             `${state.width}×${state.height}×${state.depth}`;
 
+        this.setPaused(state.paused);
+        const scrub = document.getElementById('history-scrub');
+        scrub.min = state.historyStart;
+        scrub.max = state.historyEnd;
+        scrub.value = state.generation;
+
         // Update universe size if changed
         if (state.width !== this.universeSize.width ||
             state.height !== this.universeSize.height ||
@@ -166,9 +216,15 @@ class LifeVisualizer {
             matrix.setPosition(cell.x + 0.5, cell.y + 0.5, cell.z + 0.5);
             this.instancedMesh.setMatrixAt(index, matrix);
 
-            // Color based on position (optional: could be based on age)
-            const hue = (cell.z / this.universeSize.depth) * 0.3 + 0.3; // Green to cyan gradient
-            color.setHSL(hue, 1.0, 0.5);
+            if (cell.state !== undefined && cell.state < 255) {
+                // Decaying Generations state: fade from orange to dark red
+                const remaining = Math.min(cell.state, 8) / 8;
+                color.setHSL(0.08 * remaining, 1.0, 0.15 + 0.35 * remaining);
+            } else {
+                // Color based on position (optional: could be based on age)
+                const hue = (cell.z / this.universeSize.depth) * 0.3 + 0.3; // Green to cyan gradient
+                color.setHSL(hue, 1.0, 0.5);
+            }
             this.instancedMesh.setColorAt(index, color);
         });
 
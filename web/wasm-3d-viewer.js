@@ -193,10 +193,11 @@ class Life3DViewer {
 
     loadPattern() {
         const patternSelect = document.getElementById('pattern');
-        const pattern = patternSelect.value;
+        const text = document.getElementById('pattern-text').value.trim();
+        const pattern = text || patternSelect.value;
         const center = Math.floor(this.universeSize.width / 2);
 
-        console.log(`Loading pattern: ${pattern} at (${center}, ${center}, ${center})`);
+        console.log(`Loading pattern: ${text ? 'from text' : pattern} at (${center}, ${center}, ${center})`);
         const result = this.wasmAPI.loadPattern(pattern, center - 2, center - 2, center - 2);
 
         if (result.error) {
@@ -207,7 +208,19 @@ class Life3DViewer {
         this.updateInfo();
         this.updateVisualization();
 
-        console.log(`Pattern '${pattern}' loaded successfully`);
+        console.log(`Pattern '${result.pattern || 'from text'}' loaded successfully`);
+    }
+
+    savePattern() {
+        const result = this.wasmAPI.savePattern();
+
+        if (result.error) {
+            console.error('Failed to save pattern:', result.error);
+            return;
+        }
+
+        // Shown as text so it can be copied and shared
+        document.getElementById('pattern-text').value = result.pattern;
     }
 
     step() {